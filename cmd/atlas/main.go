@@ -0,0 +1,136 @@
+// Command atlas packs images into a texture atlas PNG with JSON metadata, for use with dusk.NewTextureAtlasFromFile
+//
+// Usage:
+//
+//	atlas [-o atlas.png] [-max 4096] [-padding 1] images or directories...
+//
+// Regions are named after each image's path, relative to the directory it was found in.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	// Supported input formats, in addition to PNG
+	_ "image/gif"
+	_ "image/jpeg"
+
+	"github.com/WhoBrokeTheBuild/GoDusk/dusk/atlas"
+)
+
+func main() {
+	output := flag.String("o", "atlas.png", "output image, the metadata is written next to it with a .json extension")
+	maxSize := flag.Int("max", 4096, "maximum width and height of the atlas")
+	padding := flag.Int("padding", 1, "pixels between images")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: atlas [-o atlas.png] [-max 4096] [-padding 1] images or directories...")
+		os.Exit(2)
+	}
+
+	err := run(*output, *maxSize, *padding, flag.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(output string, maxSize, padding int, inputs []string) error {
+	images := []atlas.Image{}
+	for _, in := range inputs {
+		found, err := findImages(in)
+		if err != nil {
+			return err
+		}
+		images = append(images, found...)
+	}
+
+	img, meta, err := atlas.Pack(images, maxSize, padding)
+	if err != nil {
+		return err
+	}
+	meta.Meta.Image = filepath.Base(output)
+
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	err = png.Encode(f, img)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	b, err := meta.Marshal()
+	if err != nil {
+		return err
+	}
+	jsonFile := strings.TrimSuffix(output, filepath.Ext(output)) + ".json"
+	err = ioutil.WriteFile(jsonFile, b, 0644)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Packed %d images into %s (%dx%d)\n", len(images), output, meta.Meta.Size.W, meta.Meta.Size.H)
+	return nil
+}
+
+// findImages decodes the file, or every image in the directory and its subdirectories
+func findImages(path string) ([]atlas.Image, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		img, err := decodeImage(path)
+		if err != nil {
+			return nil, err
+		}
+		return []atlas.Image{{Name: filepath.Base(path), Image: img}}, nil
+	}
+
+	images := []atlas.Image{}
+	err = filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".png", ".jpg", ".jpeg", ".gif":
+		default:
+			return nil
+		}
+
+		img, err := decodeImage(file)
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(path, file)
+		if err != nil {
+			return err
+		}
+		images = append(images, atlas.Image{Name: filepath.ToSlash(name), Image: img})
+		return nil
+	})
+	return images, err
+}
+
+func decodeImage(file string) (image.Image, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode [%v]: %v", file, err)
+	}
+	return img, nil
+}
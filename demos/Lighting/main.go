@@ -10,10 +10,10 @@ import (
 
 type lightingShader struct {
 	dusk.DefaultShader
-	PointLight *lightEntity
+	PointLight *dusk.Entity
 }
 
-func newLightingShader(pointLight *lightEntity) *lightingShader {
+func newLightingShader(pointLight *dusk.Entity) *lightingShader {
 	s := &lightingShader{
 		PointLight: pointLight,
 	}
@@ -75,43 +75,17 @@ func (s *flatShader) Bind(ctx *dusk.RenderContext, data interface{}) {
 	gl.Uniform4fv(s.UniformLocation("uColor"), 1, &s.Color[0])
 }
 
-type lightEntity struct {
-	dusk.Entity
-	basePosition mgl32.Vec3
-}
-
-func newLightEntity(basePos mgl32.Vec3, layer dusk.ILayer) *lightEntity {
-	e := &lightEntity{
-		basePosition: basePos,
-	}
-	e.Init(layer)
-	return e
-}
+// addSpin rotates an Entity around one axis forever
+func addSpin(e *dusk.Entity, axis int) {
+	to := e.Transform().Rotation
+	to[axis] += m32.Pi * 2.0
 
-func (e *lightEntity) Update(ctx *dusk.UpdateContext) {
-	e.Entity.Update(ctx)
+	spin := dusk.TweenRotation(e, to, 10.0)
+	spin.Repeat = -1
 
-	e.Transform().Position[1] = e.basePosition[1] + m32.Sin(float32(ctx.ElapsedTime)*1.2)*3
-}
-
-type rotatingEntity struct {
-	dusk.Entity
-	rotInd int
-}
-
-func newRotatingEntity(rotInd int, layer dusk.ILayer) *rotatingEntity {
-	e := &rotatingEntity{
-		rotInd: rotInd,
-	}
-	e.Init(layer)
-	return e
-}
-
-func (e *rotatingEntity) Update(ctx *dusk.UpdateContext) {
-	e.Entity.Update(ctx)
-
-	e.Transform().Rotation[e.rotInd] += ctx.DeltaTime * 0.01
-	e.Transform().Rotation[e.rotInd] = float32(m32.Mod(e.Transform().Rotation[e.rotInd], m32.Pi*2.0))
+	t := dusk.NewTweener(e)
+	t.Play(spin)
+	e.AddComponent(t)
 }
 
 func main() {
@@ -131,6 +105,12 @@ func main() {
 	app.AddLayer(layer)
 	defer layer.Delete()
 
+	// Drag with the left mouse button to orbit, middle to pan, and scroll to zoom
+	controls := dusk.NewEntity(layer)
+	controls.AddComponent(dusk.NewOrbitController(controls, app.Window, c))
+	layer.AddEntity(controls)
+	defer controls.Delete()
+
 	// UI Layer
 	ui, err := dusk.NewUILayer(app)
 	app.AddLayer(ui)
@@ -139,11 +119,22 @@ func main() {
 	fs := newFlatShader(mgl32.Vec4{1, 1, 0.9, 1})
 	defer fs.Delete()
 
-	l1 := newLightEntity(mgl32.Vec3{0, 3, 0}, layer)
+	l1 := dusk.NewEntity(layer)
+	l1.Transform().Position = mgl32.Vec3{0, 0, 0}
 	l1.Transform().Scale = mgl32.Vec3{0.2, 0.2, 0.2}
 	defer l1.Delete()
 	layer.AddEntity(l1)
 
+	// Bob the light up and down
+	bob := dusk.TweenPosition(l1, mgl32.Vec3{0, 6, 0}, 2.6)
+	bob.Easing = dusk.EaseInOutSine
+	bob.Repeat = -1
+	bob.Yoyo = true
+
+	l1t := dusk.NewTweener(l1)
+	l1t.Play(bob)
+	l1.AddComponent(l1t)
+
 	l1m, err := dusk.NewModelFromFile(l1, "data/models/uvsphere.obj")
 	if err != nil {
 		panic(err)
@@ -155,10 +146,11 @@ func main() {
 	s := newLightingShader(l1)
 	defer s.Delete()
 
-	r1 := newRotatingEntity(0, layer)
+	r1 := dusk.NewEntity(layer)
 	r1.Transform().Position = mgl32.Vec3{-3, 1, -1}
 	defer r1.Delete()
 	layer.AddEntity(r1)
+	addSpin(r1, 0)
 
 	r1m, err := dusk.NewModelFromFile(r1, "data/models/torus.obj")
 	if err != nil {
@@ -168,10 +160,11 @@ func main() {
 	r1m.Shader = s
 	r1.AddComponent(r1m)
 
-	r2 := newRotatingEntity(2, layer)
+	r2 := dusk.NewEntity(layer)
 	r2.Transform().Position = mgl32.Vec3{-5, 2, -2}
 	defer r2.Delete()
 	layer.AddEntity(r2)
+	addSpin(r2, 2)
 
 	r2m, err := dusk.NewModelFromFile(r2, "data/models/torus.obj")
 	if err != nil {
@@ -181,10 +174,11 @@ func main() {
 	r2m.Shader = s
 	r2.AddComponent(r2m)
 
-	r3 := newRotatingEntity(0, layer)
+	r3 := dusk.NewEntity(layer)
 	r3.Transform().Position = mgl32.Vec3{-7, 3, -3}
 	defer r3.Delete()
 	layer.AddEntity(r3)
+	addSpin(r3, 0)
 
 	r3m, err := dusk.NewModelFromFile(r3, "data/models/torus.obj")
 	if err != nil {
@@ -194,10 +188,11 @@ func main() {
 	r3m.Shader = s
 	r3.AddComponent(r3m)
 
-	m1 := newRotatingEntity(1, layer)
+	m1 := dusk.NewEntity(layer)
 	m1.Transform().Position = mgl32.Vec3{2, 0, -2}
 	defer m1.Delete()
 	layer.AddEntity(m1)
+	addSpin(m1, 1)
 
 	m1m, err := dusk.NewModelFromFile(m1, "data/models/monkey.obj")
 	if err != nil {
@@ -221,11 +216,12 @@ func main() {
 	m2m.Shader = s
 	m2.AddComponent(m2m)
 
-	m3 := newRotatingEntity(1, layer)
+	m3 := dusk.NewEntity(layer)
 	m3.Transform().Position = mgl32.Vec3{0, 2, 5}
 	m3.Transform().Rotation = mgl32.Vec3{1, 2, 3}
 	defer m3.Delete()
 	layer.AddEntity(m3)
+	addSpin(m3, 1)
 
 	m3m, err := dusk.NewModelFromFile(m3, "data/models/cube.obj")
 	if err != nil {
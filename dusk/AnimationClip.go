@@ -0,0 +1,123 @@
+package dusk
+
+import (
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// VectorKey is a keyframe of a translation or scale
+type VectorKey struct {
+	// Time is in seconds from the start of the AnimationClip
+	Time  float32
+	Value mgl32.Vec3
+}
+
+// QuatKey is a keyframe of a rotation
+type QuatKey struct {
+	// Time is in seconds from the start of the AnimationClip
+	Time  float32
+	Value mgl32.Quat
+}
+
+// AnimationChannel is the keyframes of one Bone, relative to its Parent
+// Any of the keyframe lists can be empty, in which case the rest pose of the Bone is used
+type AnimationChannel struct {
+	Bone string
+
+	Translations []VectorKey
+	Rotations    []QuatKey
+	Scales       []VectorKey
+}
+
+// Sample returns the translation, rotation and scale at the given time, using t, r and s where there are no keyframes
+func (c *AnimationChannel) Sample(time float32, t mgl32.Vec3, r mgl32.Quat, s mgl32.Vec3) (mgl32.Vec3, mgl32.Quat, mgl32.Vec3) {
+	return sampleVectorKeys(c.Translations, time, t),
+		sampleQuatKeys(c.Rotations, time, r),
+		sampleVectorKeys(c.Scales, time, s)
+}
+
+// AnimationClip is a named animation of the Bones of a Skeleton
+type AnimationClip struct {
+	Name string
+
+	// Duration is the length of the clip in seconds
+	Duration float32
+
+	Channels []AnimationChannel
+}
+
+// ComputeDuration sets Duration from the last keyframe of all Channels
+func (a *AnimationClip) ComputeDuration() {
+	a.Duration = 0
+	for i := range a.Channels {
+		c := &a.Channels[i]
+		if n := len(c.Translations); n > 0 && c.Translations[n-1].Time > a.Duration {
+			a.Duration = c.Translations[n-1].Time
+		}
+		if n := len(c.Rotations); n > 0 && c.Rotations[n-1].Time > a.Duration {
+			a.Duration = c.Rotations[n-1].Time
+		}
+		if n := len(c.Scales); n > 0 && c.Scales[n-1].Time > a.Duration {
+			a.Duration = c.Scales[n-1].Time
+		}
+	}
+}
+
+// GetChannel returns the AnimationChannel of the named Bone, or nil
+func (a *AnimationClip) GetChannel(bone string) *AnimationChannel {
+	for i := range a.Channels {
+		if a.Channels[i].Bone == bone {
+			return &a.Channels[i]
+		}
+	}
+	return nil
+}
+
+// findKey returns the index of the first of count keys after time, using at to get the time of each key
+func findKey(count int, time float32, at func(int) float32) int {
+	return sort.Search(count, func(i int) bool {
+		return at(i) > time
+	})
+}
+
+func sampleVectorKeys(keys []VectorKey, time float32, def mgl32.Vec3) mgl32.Vec3 {
+	if len(keys) == 0 {
+		return def
+	}
+
+	i := findKey(len(keys), time, func(i int) float32 { return keys[i].Time })
+	if i == 0 {
+		return keys[0].Value
+	}
+	if i == len(keys) {
+		return keys[i-1].Value
+	}
+
+	a, b := keys[i-1], keys[i]
+	f := (time - a.Time) / (b.Time - a.Time)
+	return a.Value.Add(b.Value.Sub(a.Value).Mul(f))
+}
+
+func sampleQuatKeys(keys []QuatKey, time float32, def mgl32.Quat) mgl32.Quat {
+	if len(keys) == 0 {
+		return def
+	}
+
+	i := findKey(len(keys), time, func(i int) float32 { return keys[i].Time })
+	if i == 0 {
+		return keys[0].Value
+	}
+	if i == len(keys) {
+		return keys[i-1].Value
+	}
+
+	a, b := keys[i-1], keys[i]
+	f := (time - a.Time) / (b.Time - a.Time)
+
+	// Take the shortest path between the two rotations
+	if a.Value.Dot(b.Value) < 0 {
+		b.Value = b.Value.Scale(-1)
+	}
+	return mgl32.QuatSlerp(a.Value, b.Value, f)
+}
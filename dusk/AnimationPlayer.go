@@ -0,0 +1,359 @@
+package dusk
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/WhoBrokeTheBuild/GoDusk/m32"
+)
+
+// ISkinner is a Component that provides the bone matrices used to skin the Models of its Entity
+type ISkinner interface {
+	IComponent
+
+	GetBoneMatrices() []mgl32.Mat4
+}
+
+// getBoneMatrices returns the bone matrices of the first ISkinner attached to the Entity, or nil
+func getBoneMatrices(e IEntity) []mgl32.Mat4 {
+	if e == nil {
+		return nil
+	}
+	for _, c := range e.GetComponents() {
+		if s, ok := c.(ISkinner); ok {
+			return s.GetBoneMatrices()
+		}
+	}
+	return nil
+}
+
+// animationState is an AnimationClip being played by an AnimationPlayer
+type animationState struct {
+	clip *AnimationClip
+	// channels are indexed by Bone, and nil for Bones the clip does not animate
+	channels []*AnimationChannel
+
+	time float32
+	loop bool
+
+	weight float32
+	target float32
+	// fadeRate is the change in weight per second, or 0 to change immediately
+	fadeRate float32
+}
+
+// AnimationPlayer is a Component that plays and blends AnimationClips on a Skeleton, and skins the Models of its Entity on the GPU
+type AnimationPlayer struct {
+	Component
+
+	// Speed multiplies the playback rate of every clip
+	Speed float32
+
+	skeleton *Skeleton
+	clips    map[string]*AnimationClip
+	states   []*animationState
+
+	globals  []mgl32.Mat4
+	matrices []mgl32.Mat4
+}
+
+// NewAnimationPlayer returns a new AnimationPlayer with the Skeleton and AnimationClips of the Model, in its rest pose
+func NewAnimationPlayer(entity IEntity, model *Model) *AnimationPlayer {
+	p := &AnimationPlayer{
+		Speed: 1.0,
+		clips: map[string]*AnimationClip{},
+	}
+	p.Init(entity)
+
+	if model.GetSkeleton() == nil {
+		Warnf("Model has no Skeleton to animate")
+	}
+	p.SetSkeleton(model.GetSkeleton())
+
+	for _, clip := range model.GetAnimations() {
+		p.AddClip(clip)
+	}
+
+	return p
+}
+
+// SetSkeleton changes the Skeleton, stopping all clips and returning to the rest pose
+func (p *AnimationPlayer) SetSkeleton(skeleton *Skeleton) {
+	p.skeleton = skeleton
+	p.states = nil
+	p.globals = nil
+	p.matrices = nil
+
+	if skeleton == nil {
+		return
+	}
+	if len(skeleton.Bones) > MaxBones {
+		Warnf("Skeleton has %d bones, only the first %d are used for skinning", len(skeleton.Bones), MaxBones)
+	}
+	p.globals = make([]mgl32.Mat4, len(skeleton.Bones))
+	p.matrices = make([]mgl32.Mat4, len(skeleton.Bones))
+	p.updatePose()
+}
+
+// GetSkeleton returns the Skeleton being animated
+func (p *AnimationPlayer) GetSkeleton() *Skeleton {
+	return p.skeleton
+}
+
+// AddClip adds an AnimationClip that can be played by its Name, clips from other files can be used if their Bone names match
+func (p *AnimationPlayer) AddClip(clip *AnimationClip) {
+	p.clips[clip.Name] = clip
+}
+
+// GetClip returns the AnimationClip with the given Name, or nil
+func (p *AnimationPlayer) GetClip(name string) *AnimationClip {
+	return p.clips[name]
+}
+
+// GetClipNames returns the names of all AnimationClips, sorted
+func (p *AnimationPlayer) GetClipNames() []string {
+	names := make([]string, 0, len(p.clips))
+	for name := range p.clips {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Play stops all other clips and plays the named clip from the start
+func (p *AnimationPlayer) Play(name string, loop bool) error {
+	return p.CrossFade(name, 0, loop)
+}
+
+// CrossFade fades the named clip in from the start over duration seconds, while fading all other clips out
+func (p *AnimationPlayer) CrossFade(name string, duration float32, loop bool) error {
+	for _, s := range p.states {
+		p.fade(s, 0, duration)
+	}
+
+	s, err := p.getState(name)
+	if err != nil {
+		return err
+	}
+	s.time = 0
+	s.loop = loop
+	if duration <= 0 {
+		s.weight = 1
+	}
+	p.fade(s, 1, duration)
+	return nil
+}
+
+// Blend plays the named clip alongside any others, fading to weight over duration seconds
+// Weights are relative, so two clips with a weight of 1 are mixed equally
+func (p *AnimationPlayer) Blend(name string, weight, duration float32, loop bool) error {
+	s, err := p.getState(name)
+	if err != nil {
+		return err
+	}
+	s.loop = loop
+	p.fade(s, weight, duration)
+	return nil
+}
+
+// Stop fades the named clip out over duration seconds
+func (p *AnimationPlayer) Stop(name string, duration float32) {
+	for _, s := range p.states {
+		if s.clip.Name == name {
+			p.fade(s, 0, duration)
+		}
+	}
+}
+
+// StopAll stops every clip immediately and returns to the rest pose
+func (p *AnimationPlayer) StopAll() {
+	p.states = nil
+	p.updatePose()
+}
+
+// IsPlaying returns whether the named clip is playing, including while it fades out
+func (p *AnimationPlayer) IsPlaying(name string) bool {
+	for _, s := range p.states {
+		if s.clip.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SetTime moves the named clip to a time in seconds
+func (p *AnimationPlayer) SetTime(name string, time float32) {
+	for _, s := range p.states {
+		if s.clip.Name == name {
+			s.time = time
+		}
+	}
+	p.updatePose()
+}
+
+// GetTime returns the time in seconds of the named clip, or 0 if it is not playing
+func (p *AnimationPlayer) GetTime(name string) float32 {
+	for _, s := range p.states {
+		if s.clip.Name == name {
+			return s.time
+		}
+	}
+	return 0
+}
+
+// GetBoneMatrices implements the ISkinner interface
+func (p *AnimationPlayer) GetBoneMatrices() []mgl32.Mat4 {
+	if len(p.matrices) > MaxBones {
+		return p.matrices[:MaxBones]
+	}
+	return p.matrices
+}
+
+// GetBoneMatrix returns the current model space matrix of the named Bone, for attaching other Entities to it
+func (p *AnimationPlayer) GetBoneMatrix(name string) (mgl32.Mat4, bool) {
+	if p.skeleton == nil {
+		return mgl32.Ident4(), false
+	}
+	i := p.skeleton.FindBone(name)
+	if i < 0 {
+		return mgl32.Ident4(), false
+	}
+	return p.globals[i], true
+}
+
+// Update implements the Component interface
+func (p *AnimationPlayer) Update(ctx *UpdateContext) {
+	if p.skeleton == nil || len(p.states) == 0 {
+		return
+	}
+
+	// Fading uses real time, so it is not affected by Speed
+	seconds := ctx.DeltaSeconds()
+	step := seconds * p.Speed
+
+	playing := p.states[:0]
+	for _, s := range p.states {
+		s.time += step
+		if d := s.clip.Duration; d > 0 {
+			if s.loop {
+				s.time = m32.Mod(s.time, d)
+				if s.time < 0 {
+					s.time += d
+				}
+			} else {
+				s.time = m32.Max(0, m32.Min(s.time, d))
+			}
+		}
+
+		if s.fadeRate <= 0 {
+			s.weight = s.target
+		} else if s.weight < s.target {
+			s.weight = m32.Min(s.weight+s.fadeRate*seconds, s.target)
+		} else {
+			s.weight = m32.Max(s.weight-s.fadeRate*seconds, s.target)
+		}
+
+		if s.weight <= 0 && s.target <= 0 {
+			continue
+		}
+		playing = append(playing, s)
+	}
+	p.states = playing
+
+	p.updatePose()
+}
+
+// getState returns the state of the named clip, adding it with no weight if it is not playing
+func (p *AnimationPlayer) getState(name string) (*animationState, error) {
+	for _, s := range p.states {
+		if s.clip.Name == name {
+			return s, nil
+		}
+	}
+
+	clip, found := p.clips[name]
+	if !found {
+		return nil, fmt.Errorf("Animation clip [%v] not found", name)
+	}
+	if p.skeleton == nil {
+		return nil, fmt.Errorf("No Skeleton to play animation clip [%v]", name)
+	}
+
+	s := &animationState{
+		clip:     clip,
+		channels: make([]*AnimationChannel, len(p.skeleton.Bones)),
+	}
+	for i := range clip.Channels {
+		if b := p.skeleton.FindBone(clip.Channels[i].Bone); b >= 0 {
+			s.channels[b] = &clip.Channels[i]
+		}
+	}
+	p.states = append(p.states, s)
+	return s, nil
+}
+
+// fade changes the weight of a state to target over duration seconds
+func (p *AnimationPlayer) fade(s *animationState, target, duration float32) {
+	s.target = target
+	s.fadeRate = 0
+	if duration > 0 {
+		s.fadeRate = m32.Abs(target-s.weight) / duration
+	}
+}
+
+// updatePose samples and blends all clips, then calculates the matrices of every Bone
+func (p *AnimationPlayer) updatePose() {
+	if p.skeleton == nil {
+		return
+	}
+
+	for i := range p.skeleton.Bones {
+		b := &p.skeleton.Bones[i]
+		local := composeMatrix(p.sampleBone(i))
+		if b.Parent >= 0 {
+			p.globals[i] = p.globals[b.Parent].Mul4(local)
+		} else {
+			p.globals[i] = local
+		}
+		p.matrices[i] = p.globals[i].Mul4(b.Offset)
+	}
+}
+
+// sampleBone returns the weighted average of the pose of a Bone in every clip
+func (p *AnimationPlayer) sampleBone(i int) (mgl32.Vec3, mgl32.Quat, mgl32.Vec3) {
+	b := &p.skeleton.Bones[i]
+
+	total := float32(0)
+	t := mgl32.Vec3{}
+	r := mgl32.Quat{W: 0}
+	s := mgl32.Vec3{}
+
+	for _, state := range p.states {
+		w := state.weight
+		if w <= 0 {
+			continue
+		}
+
+		ct, cr, cs := b.Translation, b.Rotation, b.Scale
+		if c := state.channels[i]; c != nil {
+			ct, cr, cs = c.Sample(state.time, ct, cr, cs)
+		}
+
+		// Keep all rotations in the same hemisphere so they do not cancel out
+		if total > 0 && r.Dot(cr) < 0 {
+			cr = cr.Scale(-1)
+		}
+
+		t = t.Add(ct.Mul(w))
+		r = r.Add(cr.Scale(w))
+		s = s.Add(cs.Mul(w))
+		total += w
+	}
+
+	if total == 0 {
+		return b.Translation, b.Rotation, b.Scale
+	}
+	return t.Mul(1 / total), r.Normalize(), s.Mul(1 / total)
+}
@@ -1,6 +1,8 @@
 package dusk
 
 import (
+	"sort"
+
 	gl "github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go-gl/glfw/v3.2/glfw"
 	"github.com/go-gl/mathgl/mgl32"
@@ -9,12 +11,16 @@ import (
 // AppOptions is used to create a new App
 type AppOptions struct {
 	Window *WindowOptions
+
+	// Samples is the number of MSAA samples used when rendering the scene for post-processing
+	Samples int
 }
 
 // DefaultAppOptions returns the default values for AppOptions
 func DefaultAppOptions() *AppOptions {
 	return &AppOptions{
-		Window: DefaultWindowOptions(),
+		Window:  DefaultWindowOptions(),
+		Samples: 4,
 	}
 }
 
@@ -22,10 +28,16 @@ func DefaultAppOptions() *AppOptions {
 type App struct {
 	Window *Window
 
+	// Environment is the Cubemap used for image-based lighting, may be nil
+	Environment *Cubemap
+
 	defaultCamera *Camera
 
 	layers []ILayer
 
+	postProcess *PostProcessor
+	renderStats RenderStats
+
 	updateCtx *UpdateContext
 	renderCtx *RenderContext
 }
@@ -40,24 +52,39 @@ func NewApp(opts *AppOptions) (app *App, err error) {
 		return
 	}
 
-	app.Window.RegisterResizeFunc(func(width, height int) {})
-
 	app.defaultCamera = NewCamera(mgl32.Vec3{3, 3, 3}, mgl32.Vec3{0, 0, 0})
+	app.defaultCamera.SetViewportSize(app.Window.GetFramebufferSize())
 
 	app.layers = []ILayer{}
 
-	aspect := float32(app.Window.Width) / float32(app.Window.Height)
+	app.postProcess = NewPostProcessor(opts.Samples)
+
 	app.updateCtx = &UpdateContext{}
 	app.renderCtx = &RenderContext{
-		Projection: mgl32.Perspective(mgl32.DegToRad(45.0), aspect, 0.1, 10000.0),
+		Projection: app.defaultCamera.Projection,
 		Camera:     app.defaultCamera,
+		Queue:      NewRenderQueue(),
+		Sprites:    NewSpriteBatch(),
 	}
 
+	app.Window.RegisterResizeFunc(func(width, height int) {
+		app.renderCtx.Camera.SetViewportSize(width, height)
+		app.renderCtx.Projection = app.renderCtx.Camera.Projection
+	})
+
 	return
 }
 
 // Delete frees an App's resources
 func (app *App) Delete() {
+	if app.renderCtx != nil && app.renderCtx.Sprites != nil {
+		app.renderCtx.Sprites.Delete()
+		app.renderCtx.Sprites = nil
+	}
+	if app.postProcess != nil {
+		app.postProcess.Delete()
+		app.postProcess = nil
+	}
 	if app.Window != nil {
 		app.Window.Delete()
 		app.Window = nil
@@ -69,6 +96,27 @@ func (app *App) GetRenderContext() *RenderContext {
 	return app.renderCtx
 }
 
+// GetRenderStats returns the RenderStats of the last frame
+func (app *App) GetRenderStats() RenderStats {
+	return app.renderStats
+}
+
+// GetPostProcessor returns the App's PostProcessor
+func (app *App) GetPostProcessor() *PostProcessor {
+	return app.postProcess
+}
+
+// AddPostEffect adds an effect to the end of the post-processing chain
+// Once any effects are added, the scene is rendered into an HDR target and should be tone mapped and gamma corrected by the chain
+func (app *App) AddPostEffect(effect IPostEffect) {
+	app.postProcess.AddEffect(effect)
+}
+
+// RemovePostEffect removes an effect from the post-processing chain, it is not deleted
+func (app *App) RemovePostEffect(effect IPostEffect) {
+	app.postProcess.RemoveEffect(effect)
+}
+
 func (app *App) AddLayer(layer ILayer) {
 	app.layers = append(app.layers, layer)
 }
@@ -81,6 +129,92 @@ func (app *App) RemoveLayer(layer ILayer) {
 	}
 }
 
+// getLights returns all Light components on every Entity in every Layer
+func (app *App) getLights() []*Light {
+	lights := []*Light{}
+	for _, l := range app.layers {
+		for _, e := range l.GetEntities() {
+			for _, c := range e.GetComponents() {
+				if light, ok := c.(*Light); ok {
+					lights = append(lights, light)
+				}
+			}
+		}
+	}
+	return lights
+}
+
+// getCameras returns every enabled Camera component on every Entity in every Layer, sorted by Depth
+func (app *App) getCameras() []*Camera {
+	cameras := []*Camera{}
+	for _, l := range app.layers {
+		for _, e := range l.GetEntities() {
+			for _, c := range e.GetComponents() {
+				if camera, ok := c.(*Camera); ok && camera.Enabled {
+					cameras = append(cameras, camera)
+				}
+			}
+		}
+	}
+	sort.SliceStable(cameras, func(i, j int) bool {
+		return cameras[i].Depth < cameras[j].Depth
+	})
+	return cameras
+}
+
+// renderCamera draws every Layer in the Camera's LayerMask into its Viewport of the bound framebuffer, which is width x height
+func (app *App) renderCamera(camera *Camera, width, height int) {
+	x, y, w, h := camera.GetViewport(width, height)
+	if w <= 0 || h <= 0 {
+		return
+	}
+
+	ctx := app.renderCtx
+
+	camera.calcView()
+	camera.SetViewportSize(int(w), int(h))
+	ctx.Camera = camera
+	ctx.Projection = camera.Projection
+
+	frustum := NewFrustum(ctx.Projection.Mul4(ctx.Camera.View))
+	ctx.Frustum = &frustum
+
+	// Directional shadow cascades are fit to each Camera's view
+	renderShadows(ctx, app.layers)
+
+	gl.Viewport(x, y, w, h)
+
+	if camera.Clear != ClearNone {
+		var mask uint32
+		if camera.Clear&ClearColorBuffer != 0 {
+			c := camera.ClearColor
+			gl.ClearColor(c[0], c[1], c[2], c[3])
+			mask |= gl.COLOR_BUFFER_BIT
+		}
+		if camera.Clear&ClearDepthBuffer != 0 {
+			mask |= gl.DEPTH_BUFFER_BIT
+		}
+
+		// Clear only affects the Viewport with the scissor test enabled
+		if mask != 0 {
+			gl.Enable(gl.SCISSOR_TEST)
+			gl.Scissor(x, y, w, h)
+			gl.Clear(mask)
+			gl.Disable(gl.SCISSOR_TEST)
+		}
+
+		if camera.Clear&ClearSkybox != 0 && camera.Skybox != nil {
+			camera.Skybox.Draw(ctx)
+		}
+	}
+
+	for _, l := range app.layers {
+		if !isOverlay(l) && getLayerMask(l)&camera.LayerMask != 0 {
+			l.Render(ctx)
+		}
+	}
+}
+
 // Run starts the update/render loop for the App, it will not return until the window closes
 func (app *App) Run() {
 	const (
@@ -122,13 +256,66 @@ func (app *App) Run() {
 			frameCount++
 			frameElap = 0.0
 
-			gl.ClearColor(0.0, 0.4, 0.8, 1.0)
-			gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+			width, height := app.Window.GetFramebufferSize()
+
+			// The Camera may have been replaced or changed since the last resize
+			app.renderCtx.Camera.SetViewportSize(width, height)
+			app.renderCtx.Projection = app.renderCtx.Camera.Projection
+
+			app.renderCtx.Queue.ResetStats()
+			app.renderCtx.Lights = app.getLights()
+			app.renderCtx.Environment = app.Environment
+
+			mainCamera := app.renderCtx.Camera
+			cameras := app.getCameras()
+
+			// Cameras with a Target are drawn first, so their textures can be used by the others
+			app.renderCtx.HDR = false
+			screen := []*Camera{}
+			for _, c := range cameras {
+				if c.Target == nil {
+					screen = append(screen, c)
+					continue
+				}
+				c.Target.Bind()
+				app.renderCamera(c, c.Target.Width, c.Target.Height)
+				c.Target.UnBind()
+			}
+			if len(screen) == 0 {
+				screen = append(screen, mainCamera)
+			}
 
+			post := (len(app.postProcess.GetEffects()) > 0)
+			if post {
+				err := app.postProcess.Begin(width, height)
+				if err != nil {
+					Errorf("%v", err)
+					post = false
+				}
+			}
+			app.renderCtx.HDR = post
+
+			for _, c := range screen {
+				app.renderCamera(c, width, height)
+			}
+			gl.Viewport(0, 0, int32(width), int32(height))
+
+			if post {
+				app.postProcess.End()
+			}
+
+			// Overlays are drawn once over the whole screen, with the main Camera
+			app.renderCtx.Camera = mainCamera
+			app.renderCtx.Projection = mainCamera.Projection
+			app.renderCtx.Frustum = nil
 			for _, l := range app.layers {
-				l.Render(app.renderCtx)
+				if isOverlay(l) {
+					l.Render(app.renderCtx)
+				}
 			}
 
+			app.renderStats = app.renderCtx.Queue.Stats
+
 			app.Window.SwapBuffers()
 		}
 	}
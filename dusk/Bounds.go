@@ -0,0 +1,170 @@
+package dusk
+
+import (
+	"github.com/WhoBrokeTheBuild/GoDusk/m32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Bounds is an axis-aligned bounding box, and the bounding sphere around its center
+type Bounds struct {
+	Min mgl32.Vec3
+	Max mgl32.Vec3
+
+	Center mgl32.Vec3
+	Radius float32
+}
+
+// NewBoundsFromPoints returns the Bounds containing all of the given points
+func NewBoundsFromPoints(points []mgl32.Vec3) Bounds {
+	if len(points) == 0 {
+		return Bounds{}
+	}
+
+	b := Bounds{
+		Min: points[0],
+		Max: points[0],
+	}
+	for _, p := range points[1:] {
+		for i := 0; i < 3; i++ {
+			b.Min[i] = m32.Min(b.Min[i], p[i])
+			b.Max[i] = m32.Max(b.Max[i], p[i])
+		}
+	}
+
+	// The sphere is fit to the points, which is usually tighter than the box's corners
+	b.Center = b.Min.Add(b.Max).Mul(0.5)
+	for _, p := range points {
+		b.Radius = m32.Max(b.Radius, p.Sub(b.Center).Len())
+	}
+	return b
+}
+
+// IsZero returns whether the Bounds are unset
+func (b Bounds) IsZero() bool {
+	return b == Bounds{}
+}
+
+// Size returns the dimensions of the bounding box
+func (b Bounds) Size() mgl32.Vec3 {
+	return b.Max.Sub(b.Min)
+}
+
+// Union returns the Bounds containing both b and o
+func (b Bounds) Union(o Bounds) Bounds {
+	if b.IsZero() {
+		return o
+	}
+	if o.IsZero() {
+		return b
+	}
+
+	u := Bounds{}
+	for i := 0; i < 3; i++ {
+		u.Min[i] = m32.Min(b.Min[i], o.Min[i])
+		u.Max[i] = m32.Max(b.Max[i], o.Max[i])
+	}
+	u.Center = u.Min.Add(u.Max).Mul(0.5)
+	u.Radius = m32.Max(
+		u.Center.Sub(b.Center).Len()+b.Radius,
+		u.Center.Sub(o.Center).Len()+o.Radius)
+	return u
+}
+
+// Transform returns the Bounds containing these Bounds after being transformed by m
+func (b Bounds) Transform(m mgl32.Mat4) Bounds {
+	t := Bounds{}
+
+	// Transform the box by the absolute value of the rotation/scale, from Arvo's "Transforming Axis-Aligned Bounding Boxes"
+	center := b.Min.Add(b.Max).Mul(0.5)
+	extent := b.Max.Sub(b.Min).Mul(0.5)
+	newCenter := mgl32.TransformCoordinate(center, m)
+	newExtent := mgl32.Vec3{}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			newExtent[i] += m32.Abs(m.At(i, j)) * extent[j]
+		}
+	}
+	t.Min = newCenter.Sub(newExtent)
+	t.Max = newCenter.Add(newExtent)
+
+	scale := m32.Max(m.Col(0).Vec3().Len(), m32.Max(m.Col(1).Vec3().Len(), m.Col(2).Vec3().Len()))
+	t.Center = mgl32.TransformCoordinate(b.Center, m)
+	t.Radius = b.Radius * scale
+	return t
+}
+
+// Frustum is a set of six planes, stored as (normal, distance), pointing into the visible volume
+type Frustum struct {
+	Planes [6]mgl32.Vec4
+}
+
+// NewFrustum returns the Frustum of a combined projection and view matrix
+func NewFrustum(viewProj mgl32.Mat4) Frustum {
+	// Gribb and Hartmann's plane extraction
+	r0 := viewProj.Row(0)
+	r1 := viewProj.Row(1)
+	r2 := viewProj.Row(2)
+	r3 := viewProj.Row(3)
+
+	f := Frustum{
+		Planes: [6]mgl32.Vec4{
+			r3.Add(r0), // Left
+			r3.Sub(r0), // Right
+			r3.Add(r1), // Bottom
+			r3.Sub(r1), // Top
+			r3.Add(r2), // Near
+			r3.Sub(r2), // Far
+		},
+	}
+
+	for i := range f.Planes {
+		l := f.Planes[i].Vec3().Len()
+		if l > 0 {
+			f.Planes[i] = f.Planes[i].Mul(1.0 / l)
+		}
+	}
+	return f
+}
+
+// ContainsPoint returns whether the point is inside the Frustum
+func (f *Frustum) ContainsPoint(p mgl32.Vec3) bool {
+	for _, pl := range f.Planes {
+		if pl.Vec3().Dot(p)+pl[3] < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsSphere returns whether any part of the sphere is inside the Frustum
+func (f *Frustum) ContainsSphere(center mgl32.Vec3, radius float32) bool {
+	for _, pl := range f.Planes {
+		if pl.Vec3().Dot(center)+pl[3] < -radius {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsBox returns whether any part of the axis-aligned box is inside the Frustum
+// It is conservative, some boxes near the corners of the Frustum will pass even though they are outside
+func (f *Frustum) ContainsBox(min, max mgl32.Vec3) bool {
+	for _, pl := range f.Planes {
+		// Test the corner furthest along the plane's normal
+		p := min
+		for i := 0; i < 3; i++ {
+			if pl[i] >= 0 {
+				p[i] = max[i]
+			}
+		}
+		if pl.Vec3().Dot(p)+pl[3] < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsBounds returns whether any part of the Bounds are inside the Frustum
+func (f *Frustum) ContainsBounds(b Bounds) bool {
+	return f.ContainsSphere(b.Center, b.Radius) && f.ContainsBox(b.Min, b.Max)
+}
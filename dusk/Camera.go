@@ -2,32 +2,230 @@ package dusk
 
 import "github.com/go-gl/mathgl/mgl32"
 
+// ClearFlags controls which buffers a Camera clears before rendering
+type ClearFlags int
+
+const (
+	// ClearColorBuffer clears the color buffer to the Camera's ClearColor
+	ClearColorBuffer ClearFlags = 1 << iota
+	// ClearDepthBuffer clears the depth buffer
+	ClearDepthBuffer
+	// ClearSkybox draws the Camera's Skybox over the color buffer
+	ClearSkybox
+
+	// ClearNone draws over whatever was already rendered, e.g. for an overlay Camera
+	ClearNone ClearFlags = 0
+	// ClearAll clears both the color and depth buffers
+	ClearAll = ClearColorBuffer | ClearDepthBuffer
+)
+
+// AllLayers is a LayerMask that renders every Layer
+const AllLayers = ^uint32(0)
+
+// Camera represents a view and projection into the scene
+// When added to an Entity as a Component, it is positioned and rotated by the Entity's Transform, looking down -Z
 type Camera struct {
+	Component
+
 	Position mgl32.Vec3
 	LookAt   mgl32.Vec3
 	View     mgl32.Mat4
+
+	Projection mgl32.Mat4
+
+	// FOV is the vertical field of view in radians, used when the Camera is not Orthographic
+	FOV float32
+	// OrthoSize is half of the vertical size of the view, used when the Camera is Orthographic
+	OrthoSize    float32
+	Orthographic bool
+
+	Near   float32
+	Far    float32
+	Aspect float32
+
+	// Viewport is the area of the screen or Target to draw to, as (x, y, width, height) from 0 to 1
+	Viewport mgl32.Vec4
+
+	Clear      ClearFlags
+	ClearColor mgl32.Vec4
+
+	// Skybox is drawn when Clear includes ClearSkybox
+	Skybox *Skybox
+
+	// LayerMask selects which Layers are rendered, by their Mask
+	LayerMask uint32
+
+	// Target is the RenderTarget to draw to, if nil the Camera draws to the screen
+	Target *RenderTarget
+
+	// Depth is the order Cameras are drawn in, lowest first
+	Depth int
+
+	Enabled bool
 }
 
+// NewCamera returns a new perspective Camera at pos looking at lookAt
 func NewCamera(pos, lookAt mgl32.Vec3) *Camera {
 	c := &Camera{
-		Position: pos,
-		LookAt:   lookAt,
+		Position:  pos,
+		LookAt:    lookAt,
+		FOV:       mgl32.DegToRad(45.0),
+		OrthoSize: 5.0,
+		Near:      0.1,
+		Far:       10000.0,
+		Aspect:    1.0,
+
+		Viewport:   mgl32.Vec4{0, 0, 1, 1},
+		Clear:      ClearAll,
+		ClearColor: mgl32.Vec4{0.0, 0.4, 0.8, 1.0},
+		LayerMask:  AllLayers,
+		Enabled:    true,
 	}
 	c.calcView()
+	c.calcProjection()
+
+	return c
+}
+
+// NewCamera2D returns a new orthographic Camera looking down -Z at center, showing height world units vertically
+func NewCamera2D(center mgl32.Vec2, height float32) *Camera {
+	c := NewCamera(mgl32.Vec3{center.X(), center.Y(), 100}, mgl32.Vec3{center.X(), center.Y(), 0})
+	c.SetOrthographic(height*0.5, 0.1, 1000.0)
+	return c
+}
+
+// SetPosition2D moves a 2D Camera to look at center, keeping its distance and direction
+func (c *Camera) SetPosition2D(center mgl32.Vec2) {
+	offset := mgl32.Vec3{center.X(), center.Y(), c.Position.Z()}.Sub(c.Position)
+	c.LookAt = c.LookAt.Add(offset)
+	c.SetPosition(c.Position.Add(offset))
+}
 
+// NewEntityCamera returns a new perspective Camera that is positioned by the given Entity, it still needs to be added to the Entity
+func NewEntityCamera(entity IEntity) *Camera {
+	c := NewCamera(mgl32.Vec3{}, mgl32.Vec3{0, 0, -1})
+	c.Init(entity)
+	c.calcView()
 	return c
 }
 
+// hasTransform returns whether the Camera is positioned by an Entity
+func (c *Camera) hasTransform() bool {
+	return c.GetEntity() != nil && c.GetEntity().Transform() != nil
+}
+
+// SetPosition moves the Camera, or its Entity, to pos in world space
 func (c *Camera) SetPosition(pos mgl32.Vec3) {
+	if c.hasTransform() {
+		c.GetEntity().Transform().SetWorldPosition(pos)
+	}
 	c.Position = pos
 	c.calcView()
 }
 
+// SetLookAt points the Camera at lookAt, a Camera on an Entity rotates the Entity to face it
 func (c *Camera) SetLookAt(lookAt mgl32.Vec3) {
+	if c.hasTransform() {
+		c.GetEntity().Transform().LookAt(lookAt, mgl32.Vec3{0, 1, 0})
+	}
 	c.LookAt = lookAt
 	c.calcView()
 }
 
+// SetPerspective changes the Camera to a perspective projection
+func (c *Camera) SetPerspective(fov, near, far float32) {
+	c.Orthographic = false
+	c.FOV = fov
+	c.Near = near
+	c.Far = far
+	c.calcProjection()
+}
+
+// SetOrthographic changes the Camera to an orthographic projection, size is half of the vertical size of the view
+func (c *Camera) SetOrthographic(size, near, far float32) {
+	c.Orthographic = true
+	c.OrthoSize = size
+	c.Near = near
+	c.Far = far
+	c.calcProjection()
+}
+
+// SetAspect sets the aspect ratio (width / height) of the projection
+func (c *Camera) SetAspect(aspect float32) {
+	if aspect <= 0 || aspect == c.Aspect {
+		return
+	}
+	c.Aspect = aspect
+	c.calcProjection()
+}
+
+// SetViewportSize sets the aspect ratio of the projection from a size in pixels
+func (c *Camera) SetViewportSize(width, height int) {
+	if width <= 0 || height <= 0 {
+		return
+	}
+	c.SetAspect(float32(width) / float32(height))
+}
+
+// UpdateProjection recalculates the projection, call after changing FOV, OrthoSize, Orthographic, Near or Far directly
+func (c *Camera) UpdateProjection() {
+	c.calcProjection()
+}
+
+// GetViewport returns the Camera's Viewport in pixels, for a screen or Target of the given size
+func (c *Camera) GetViewport(width, height int) (x, y, w, h int32) {
+	x = int32(c.Viewport[0] * float32(width))
+	y = int32(c.Viewport[1] * float32(height))
+	w = int32(c.Viewport[2] * float32(width))
+	h = int32(c.Viewport[3] * float32(height))
+	return
+}
+
+// ScreenPointToRay returns the world space Ray through a point on a screen of the given size, such as Window.GetMousePos() in a Window of Width x Height
+// The point's origin is the top left, and the Camera's Viewport is taken into account
+func (c *Camera) ScreenPointToRay(point mgl32.Vec2, width, height int) Ray {
+	x, y, w, h := c.GetViewport(width, height)
+	if w <= 0 || h <= 0 {
+		return NewRay(c.Position, c.LookAt.Sub(c.Position))
+	}
+
+	// Convert to normalized device coordinates, flipping Y to match OpenGL
+	ndcX := (point.X()-float32(x))/float32(w)*2.0 - 1.0
+	ndcY := (float32(height)-point.Y()-float32(y))/float32(h)*2.0 - 1.0
+
+	inv := c.Projection.Mul4(c.View).Inv()
+	near := mgl32.TransformCoordinate(mgl32.Vec3{ndcX, ndcY, -1}, inv)
+	far := mgl32.TransformCoordinate(mgl32.Vec3{ndcX, ndcY, 1}, inv)
+	return NewRay(near, far.Sub(near))
+}
+
 func (c *Camera) calcView() {
-	c.View = mgl32.LookAtV(c.Position, c.LookAt, mgl32.Vec3{0, 1, 0})
+	if !c.hasTransform() {
+		c.View = mgl32.LookAtV(c.Position, c.LookAt, mgl32.Vec3{0, 1, 0})
+		return
+	}
+
+	// The view is the inverse of the Entity's world position and rotation, scale is ignored
+	world := c.GetEntity().Transform().GetWorldMatrix()
+	rotation := mgl32.Ident4()
+	for i := 0; i < 3; i++ {
+		if axis := world.Col(i).Vec3(); axis.Len() > 0 {
+			rotation.SetCol(i, axis.Normalize().Vec4(0))
+		}
+	}
+	pos := world.Col(3).Vec3()
+
+	c.Position = pos
+	c.LookAt = pos.Add(rotation.Mul4x1(mgl32.Vec4{0, 0, -1, 0}).Vec3())
+	c.View = rotation.Transpose().Mul4(mgl32.Translate3D(-pos[0], -pos[1], -pos[2]))
+}
+
+func (c *Camera) calcProjection() {
+	if c.Orthographic {
+		h := c.OrthoSize
+		w := h * c.Aspect
+		c.Projection = mgl32.Ortho(-w, w, -h, h, c.Near, c.Far)
+	} else {
+		c.Projection = mgl32.Perspective(c.FOV, c.Aspect, c.Near, c.Far)
+	}
 }
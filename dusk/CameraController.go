@@ -0,0 +1,346 @@
+package dusk
+
+import (
+	"github.com/WhoBrokeTheBuild/GoDusk/m32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Speeds of the camera controllers are per frame at 60 FPS, and are scaled by UpdateContext.DeltaTime
+
+const maxCameraPitch = 89.0 * m32.Pi / 180.0
+
+// mouseTracker collects mouse movement and scrolling from a Window between updates
+type mouseTracker struct {
+	window    *Window
+	callbacks []CallbackID
+
+	last    mgl32.Vec2
+	hasLast bool
+	delta   mgl32.Vec2
+	scroll  float32
+}
+
+func (t *mouseTracker) init(window *Window) {
+	t.window = window
+	t.callbacks = []CallbackID{
+		window.RegisterMouseMoveFunc(func(pos mgl32.Vec2) {
+			if t.hasLast {
+				t.delta = t.delta.Add(pos.Sub(t.last))
+			}
+			t.last = pos
+			t.hasLast = true
+		}),
+		window.RegisterScrollFunc(func(offset mgl32.Vec2) {
+			t.scroll += offset.Y()
+		}),
+	}
+}
+
+// release unregisters the functions added to the Window, so that it no longer keeps the tracker alive
+func (t *mouseTracker) release() {
+	if t.window == nil {
+		return
+	}
+	for _, id := range t.callbacks {
+		t.window.UnregisterFunc(id)
+	}
+	t.callbacks = nil
+}
+
+// consume returns the mouse movement and scrolling since the last call
+func (t *mouseTracker) consume() (delta mgl32.Vec2, scroll float32) {
+	delta, scroll = t.delta, t.scroll
+	t.delta = mgl32.Vec2{}
+	t.scroll = 0
+	return
+}
+
+// axisSign returns -1 if the axis is inverted, and 1 otherwise
+func axisSign(inverted bool) float32 {
+	if inverted {
+		return -1
+	}
+	return 1
+}
+
+// directionFromAngles returns the direction for a yaw around Y and a pitch above the horizon, a yaw of 0 points down -Z
+func directionFromAngles(yaw, pitch float32) mgl32.Vec3 {
+	return mgl32.Vec3{
+		m32.Cos(pitch) * m32.Sin(yaw),
+		m32.Sin(pitch),
+		-m32.Cos(pitch) * m32.Cos(yaw),
+	}
+}
+
+// anglesFromDirection is the inverse of directionFromAngles
+func anglesFromDirection(dir mgl32.Vec3) (yaw, pitch float32) {
+	if dir.Len() == 0 {
+		return 0, 0
+	}
+	dir = dir.Normalize()
+	return m32.Atan2(dir.X(), -dir.Z()), m32.Asin(dir.Y())
+}
+
+// FlyController is a Component that moves a Camera freely with WASD, and looks around with the mouse
+// W/S move forward and back, A/D move sideways, E/Q move up and down, and Left Shift moves faster
+type FlyController struct {
+	Component
+	mouseTracker
+
+	Camera *Camera
+
+	MoveSpeed  float32
+	BoostSpeed float32
+	// LookSpeed is in radians per pixel of mouse movement
+	LookSpeed float32
+
+	// LookButton must be held to look around with the mouse
+	LookButton MouseButton
+
+	InvertX bool
+	InvertY bool
+
+	Enabled bool
+
+	yaw   float32
+	pitch float32
+}
+
+// NewFlyController returns a new FlyController for the Camera, starting from its current position and direction
+func NewFlyController(entity IEntity, window *Window, camera *Camera) *FlyController {
+	c := &FlyController{
+		Camera:     camera,
+		MoveSpeed:  0.1,
+		BoostSpeed: 0.5,
+		LookSpeed:  0.004,
+		LookButton: MouseButtonRight,
+		Enabled:    true,
+	}
+	c.Init(entity)
+	c.mouseTracker.init(window)
+	c.yaw, c.pitch = anglesFromDirection(camera.LookAt.Sub(camera.Position))
+	return c
+}
+
+// Delete implements the Component interface, and unregisters the mouse functions from the Window
+func (c *FlyController) Delete() {
+	c.release()
+	c.Component.Delete()
+}
+
+// Update implements the Component interface
+func (c *FlyController) Update(ctx *UpdateContext) {
+	delta, _ := c.consume()
+	if !c.Enabled || c.Camera == nil {
+		return
+	}
+
+	if c.window.IsMouseDown(c.LookButton) {
+		c.yaw += delta.X() * c.LookSpeed * axisSign(c.InvertX)
+		c.pitch -= delta.Y() * c.LookSpeed * axisSign(c.InvertY)
+		c.pitch = m32.Max(-maxCameraPitch, m32.Min(maxCameraPitch, c.pitch))
+	}
+
+	up := mgl32.Vec3{0, 1, 0}
+	forward := directionFromAngles(c.yaw, c.pitch)
+	right := forward.Cross(up).Normalize()
+
+	move := mgl32.Vec3{}
+	if c.window.IsKeyDown(KeyW) {
+		move = move.Add(forward)
+	}
+	if c.window.IsKeyDown(KeyS) {
+		move = move.Sub(forward)
+	}
+	if c.window.IsKeyDown(KeyD) {
+		move = move.Add(right)
+	}
+	if c.window.IsKeyDown(KeyA) {
+		move = move.Sub(right)
+	}
+	if c.window.IsKeyDown(KeyE) {
+		move = move.Add(up)
+	}
+	if c.window.IsKeyDown(KeyQ) {
+		move = move.Sub(up)
+	}
+
+	pos := c.Camera.Position
+	if move.Len() > 0 {
+		speed := c.MoveSpeed
+		if c.window.IsKeyDown(KeyLeftShift) {
+			speed = c.BoostSpeed
+		}
+		pos = pos.Add(move.Normalize().Mul(speed * ctx.DeltaTime))
+	}
+
+	c.Camera.SetPosition(pos)
+	c.Camera.SetLookAt(pos.Add(forward))
+}
+
+// OrbitController is a Component that rotates a Camera around a target point
+// Dragging with the RotateButton orbits, dragging with the PanButton moves the target, and scrolling zooms
+type OrbitController struct {
+	Component
+	mouseTracker
+
+	Camera *Camera
+	Target mgl32.Vec3
+
+	Distance    float32
+	MinDistance float32
+	MaxDistance float32
+
+	// RotateSpeed is in radians per pixel of mouse movement
+	RotateSpeed float32
+	// PanSpeed is the fraction of the Distance moved per pixel of mouse movement
+	PanSpeed float32
+	// ZoomSpeed is the fraction of the Distance moved per step of the scroll wheel
+	ZoomSpeed float32
+
+	RotateButton MouseButton
+	PanButton    MouseButton
+
+	InvertX    bool
+	InvertY    bool
+	InvertZoom bool
+
+	Enabled bool
+
+	yaw   float32
+	pitch float32
+}
+
+// NewOrbitController returns a new OrbitController for the Camera, orbiting the point it is currently looking at
+func NewOrbitController(entity IEntity, window *Window, camera *Camera) *OrbitController {
+	c := &OrbitController{
+		Camera:       camera,
+		Target:       camera.LookAt,
+		MinDistance:  0.5,
+		MaxDistance:  1000.0,
+		RotateSpeed:  0.01,
+		PanSpeed:     0.002,
+		ZoomSpeed:    0.1,
+		RotateButton: MouseButtonLeft,
+		PanButton:    MouseButtonMiddle,
+		Enabled:      true,
+	}
+	c.Init(entity)
+	c.mouseTracker.init(window)
+
+	offset := camera.Position.Sub(camera.LookAt)
+	c.Distance = offset.Len()
+	c.yaw, c.pitch = anglesFromDirection(offset)
+	return c
+}
+
+// Delete implements the Component interface, and unregisters the mouse functions from the Window
+func (c *OrbitController) Delete() {
+	c.release()
+	c.Component.Delete()
+}
+
+// Update implements the Component interface
+func (c *OrbitController) Update(ctx *UpdateContext) {
+	delta, scroll := c.consume()
+	if !c.Enabled || c.Camera == nil {
+		return
+	}
+
+	if c.window.IsMouseDown(c.RotateButton) {
+		c.yaw -= delta.X() * c.RotateSpeed * axisSign(c.InvertX)
+		c.pitch += delta.Y() * c.RotateSpeed * axisSign(c.InvertY)
+		c.pitch = m32.Max(-maxCameraPitch, m32.Min(maxCameraPitch, c.pitch))
+	}
+
+	offset := directionFromAngles(c.yaw, c.pitch)
+
+	if c.window.IsMouseDown(c.PanButton) {
+		right := offset.Cross(mgl32.Vec3{0, 1, 0}).Normalize().Mul(-1)
+		up := right.Cross(offset.Mul(-1)).Normalize()
+		pan := right.Mul(-delta.X()).Add(up.Mul(delta.Y()))
+		c.Target = c.Target.Add(pan.Mul(c.PanSpeed * c.Distance))
+	}
+
+	if scroll != 0 {
+		c.Distance *= 1.0 - scroll*c.ZoomSpeed*axisSign(c.InvertZoom)
+	}
+	c.Distance = m32.Max(c.MinDistance, m32.Min(c.MaxDistance, c.Distance))
+
+	c.Camera.SetPosition(c.Target.Add(offset.Mul(c.Distance)))
+	c.Camera.SetLookAt(c.Target)
+}
+
+// FollowController is a Component that smoothly moves a Camera behind a target Entity
+// It does not check for collisions, the Camera may pass through other objects
+type FollowController struct {
+	Component
+
+	Camera *Camera
+	Target IEntity
+
+	// Offset is the position of the Camera relative to the Target
+	Offset mgl32.Vec3
+	// LookOffset is the point the Camera looks at relative to the Target
+	LookOffset mgl32.Vec3
+
+	// RotateWithTarget turns the Offset with the Target's rotation around Y
+	RotateWithTarget bool
+
+	// Damping is the fraction of the remaining distance the Camera moves each frame, 1 snaps to the Target
+	Damping float32
+
+	Enabled bool
+
+	position mgl32.Vec3
+	lookAt   mgl32.Vec3
+	started  bool
+}
+
+// NewFollowController returns a new FollowController that keeps the Camera behind and above the Target
+func NewFollowController(entity IEntity, camera *Camera, target IEntity) *FollowController {
+	c := &FollowController{
+		Camera:           camera,
+		Target:           target,
+		Offset:           mgl32.Vec3{0, 3, 6},
+		LookOffset:       mgl32.Vec3{0, 1, 0},
+		RotateWithTarget: true,
+		Damping:          0.1,
+		Enabled:          true,
+	}
+	c.Init(entity)
+	return c
+}
+
+// Update implements the Component interface
+func (c *FollowController) Update(ctx *UpdateContext) {
+	if !c.Enabled || c.Camera == nil || c.Target == nil || c.Target.Transform() == nil {
+		return
+	}
+
+	t := c.Target.Transform()
+	offset := c.Offset
+	lookOffset := c.LookOffset
+	if c.RotateWithTarget {
+		rotation := mgl32.Rotate3DY(t.Rotation[1])
+		offset = rotation.Mul3x1(offset)
+		lookOffset = rotation.Mul3x1(lookOffset)
+	}
+
+	position := t.Position.Add(offset)
+	lookAt := t.Position.Add(lookOffset)
+
+	if !c.started || c.Damping >= 1 {
+		c.position = position
+		c.lookAt = lookAt
+		c.started = true
+	} else {
+		// Damping is per frame at 60 FPS, so it is compounded over DeltaTime frames
+		alpha := 1.0 - m32.Pow(1.0-m32.Max(c.Damping, 0), ctx.DeltaTime)
+		c.position = c.position.Add(position.Sub(c.position).Mul(alpha))
+		c.lookAt = c.lookAt.Add(lookAt.Sub(c.lookAt).Mul(alpha))
+	}
+
+	c.Camera.SetPosition(c.position)
+	c.Camera.SetLookAt(c.lookAt)
+}
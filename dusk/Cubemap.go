@@ -0,0 +1,223 @@
+package dusk
+
+import (
+	"fmt"
+	"path/filepath"
+	"unsafe"
+
+	"github.com/WhoBrokeTheBuild/GoDusk/m32"
+	"github.com/WhoBrokeTheBuild/GoDusk/stbi"
+
+	gl "github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Cubemap represents an OpenGL Cube Map Texture
+type Cubemap struct {
+	ID   uint32
+	Size int
+
+	// HDR is true when the Cubemap holds linear floating point colors, rather than 8-bit sRGB
+	HDR bool
+}
+
+// NewCubemapFromFiles returns a new Cubemap from six files, in the order +X, -X, +Y, -Y, +Z, -Z
+func NewCubemapFromFiles(files ...string) (*Cubemap, error) {
+	c := &Cubemap{}
+	err := c.LoadFromFiles(files...)
+	if err != nil {
+		c.Delete()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Delete frees the resources owned by the Cubemap
+func (c *Cubemap) Delete() {
+	if c.ID != InvalidID {
+		gl.DeleteTextures(1, &c.ID)
+		c.ID = InvalidID
+	}
+}
+
+// LoadFromFiles loads a Cubemap from six files, in the order +X, -X, +Y, -Y, +Z, -Z
+func (c *Cubemap) LoadFromFiles(files ...string) error {
+	c.Delete()
+
+	if len(files) != 6 {
+		return fmt.Errorf("Cubemap requires 6 files, got %d", len(files))
+	}
+
+	gl.GenTextures(1, &c.ID)
+	gl.BindTexture(gl.TEXTURE_CUBE_MAP, c.ID)
+
+	for i, filename := range files {
+		filename = filepath.Clean(filename)
+
+		Loadf("asset.Cubemap [%v]", filename)
+		b, err := Load(filename)
+		if err != nil {
+			return err
+		}
+
+		// Faces are always decoded to RGBA, so grey and grey-alpha images are uploaded correctly
+		image, w, h, _ := stbi.LoadFromMemory(b, stbi.RGBAlpha)
+		if image == nil {
+			return fmt.Errorf("Failed to decode [%v]", filename)
+		}
+		if w != h {
+			stbi.ImageFree(image)
+			return fmt.Errorf("Cubemap face [%v] is not square", filename)
+		}
+
+		c.Size = w
+		gl.TexImage2D(gl.TEXTURE_CUBE_MAP_POSITIVE_X+uint32(i), 0, gl.RGBA,
+			int32(w),
+			int32(h),
+			0, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(image))
+
+		stbi.ImageFree(image)
+	}
+
+	c.setParameters()
+
+	gl.BindTexture(gl.TEXTURE_CUBE_MAP, 0)
+	return nil
+}
+
+// NewCubemapFromEquirectangular returns a new Cubemap with faces of size x size from an equirectangular (latitude/longitude) image
+func NewCubemapFromEquirectangular(filename string, size int) (*Cubemap, error) {
+	c := &Cubemap{}
+	err := c.LoadFromEquirectangular(filename, size)
+	if err != nil {
+		c.Delete()
+		return nil, err
+	}
+	return c, nil
+}
+
+// LoadFromEquirectangular loads a Cubemap with faces of size x size from an equirectangular (latitude/longitude) image
+// Images such as .hdr are kept in linear HDR, other images are converted from sRGB to linear
+func (c *Cubemap) LoadFromEquirectangular(filename string, size int) error {
+	c.Delete()
+
+	if size <= 0 {
+		return fmt.Errorf("Invalid Cubemap size %d", size)
+	}
+
+	filename = filepath.Clean(filename)
+
+	Loadf("asset.Cubemap [%v]", filename)
+	b, err := Load(filename)
+	if err != nil {
+		return err
+	}
+
+	image, w, h, _ := stbi.LoadfFromMemory(b, stbi.RGB)
+	if image == nil {
+		return fmt.Errorf("Failed to decode [%v]", filename)
+	}
+	defer stbi.ImageFreef(image)
+
+	n := w * h * 3
+	pixels := (*[1 << 30]float32)(unsafe.Pointer(image))[:n:n]
+
+	gl.GenTextures(1, &c.ID)
+	gl.BindTexture(gl.TEXTURE_CUBE_MAP, c.ID)
+
+	c.Size = size
+	c.HDR = true
+
+	face := make([]float32, size*size*3)
+	for i := 0; i < 6; i++ {
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				u := 2.0*(float32(x)+0.5)/float32(size) - 1.0
+				v := 2.0*(float32(y)+0.5)/float32(size) - 1.0
+				col := sampleEquirectangular(pixels, w, h, cubemapFaceDirection(i, u, v))
+				copy(face[(y*size+x)*3:], col[:])
+			}
+		}
+
+		gl.TexImage2D(gl.TEXTURE_CUBE_MAP_POSITIVE_X+uint32(i), 0, gl.RGB16F,
+			int32(size),
+			int32(size),
+			0, gl.RGB, gl.FLOAT, gl.Ptr(face))
+	}
+
+	c.setParameters()
+
+	gl.BindTexture(gl.TEXTURE_CUBE_MAP, 0)
+	return nil
+}
+
+// cubemapFaceDirection returns the direction through a point on a face, in the order +X, -X, +Y, -Y, +Z, -Z, with u and v from -1 to 1
+func cubemapFaceDirection(face int, u, v float32) mgl32.Vec3 {
+	var dir mgl32.Vec3
+	switch face {
+	case 0:
+		dir = mgl32.Vec3{1, -v, -u}
+	case 1:
+		dir = mgl32.Vec3{-1, -v, u}
+	case 2:
+		dir = mgl32.Vec3{u, 1, v}
+	case 3:
+		dir = mgl32.Vec3{u, -1, -v}
+	case 4:
+		dir = mgl32.Vec3{u, -v, 1}
+	case 5:
+		dir = mgl32.Vec3{-u, -v, -1}
+	}
+	return dir.Normalize()
+}
+
+// sampleEquirectangular bilinearly samples an RGB equirectangular image in the given direction
+func sampleEquirectangular(pixels []float32, w, h int, dir mgl32.Vec3) mgl32.Vec3 {
+	u := 0.5 + m32.Atan2(dir.Z(), dir.X())/(2.0*m32.Pi)
+	v := 0.5 - m32.Asin(dir.Y())/m32.Pi
+
+	fx := u*float32(w) - 0.5
+	fy := v*float32(h) - 0.5
+	x0 := int(m32.Floor(fx))
+	y0 := int(m32.Floor(fy))
+	tx := fx - float32(x0)
+	ty := fy - float32(y0)
+
+	texel := func(x, y int) mgl32.Vec3 {
+		// Wrap around horizontally, and clamp at the poles
+		x = ((x % w) + w) % w
+		if y < 0 {
+			y = 0
+		} else if y >= h {
+			y = h - 1
+		}
+		i := (y*w + x) * 3
+		return mgl32.Vec3{pixels[i], pixels[i+1], pixels[i+2]}
+	}
+
+	top := texel(x0, y0).Mul(1 - tx).Add(texel(x0+1, y0).Mul(tx))
+	bottom := texel(x0, y0+1).Mul(1 - tx).Add(texel(x0+1, y0+1).Mul(tx))
+	return top.Mul(1 - ty).Add(bottom.Mul(ty))
+}
+
+func (c *Cubemap) setParameters() {
+	gl.TexParameteri(gl.TEXTURE_CUBE_MAP, gl.TEXTURE_MIN_FILTER, gl.LINEAR_MIPMAP_LINEAR)
+	gl.TexParameteri(gl.TEXTURE_CUBE_MAP, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_CUBE_MAP, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_CUBE_MAP, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_CUBE_MAP, gl.TEXTURE_WRAP_R, gl.CLAMP_TO_EDGE)
+	gl.GenerateMipmap(gl.TEXTURE_CUBE_MAP)
+}
+
+// MipLevels returns the number of mipmap levels in the Cubemap
+func (c *Cubemap) MipLevels() int {
+	if c.Size <= 0 {
+		return 0
+	}
+	return int(m32.Floor(m32.Log2(float32(c.Size)))) + 1
+}
+
+// Bind calls glBindTexture with the Cubemap's ID
+func (c *Cubemap) Bind() {
+	gl.BindTexture(gl.TEXTURE_CUBE_MAP, c.ID)
+}
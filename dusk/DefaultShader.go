@@ -9,42 +9,48 @@ const (
 	defaultShaderVert = `
 #include <mvp.inc.glsl>
 #include <attribute.inc.glsl>
+#include <instance.inc.glsl>
+#include <skin.inc.glsl>
 
 out vec4 p_Position;
 out vec4 p_Normal;
+out vec4 p_Tangent;
 out vec2 p_TexCoord;
-
-out vec4 p_LightDir;
-out vec4 p_ViewDir;
+out float p_ViewDepth;
+out vec4 p_Color;
 
 void main() {
-    p_Position = uModel * vec4(_Position, 1.0);
-    p_Normal   = vec4(mat3(transpose(inverse(uModel))) * _Normal, 1.0);
-	p_TexCoord = vec2(_TexCoord.x, 1.0 - _TexCoord.y);
-	
-	p_LightDir = normalize(-vec4(-0.2, -1.0, -0.3, 0.0));
-	p_ViewDir = -(uView * p_Position);
-
-    gl_Position = uMVP * vec4(_Position, 1);
+    mat4 model = GetModelMatrix() * GetSkinMatrix();
+
+    p_Position = model * vec4(_Position, 1.0);
+    p_Normal   = vec4(mat3(transpose(inverse(model))) * _Normal, 1.0);
+    // Tangents follow the surface, so they are transformed by the model matrix itself, and left zero when missing
+    p_Tangent  = vec4(mat3(model) * _Tangent.xyz, _Tangent.w);
+    p_TexCoord = vec2(_TexCoord.x, 1.0 - _TexCoord.y);
+    p_ViewDepth = -(uView * p_Position).z;
+    p_Color    = GetInstanceColor();
+
+    gl_Position = uProjection * uView * p_Position;
 }
 `
 	defaultShaderFrag = `
 #include <material.inc.glsl>
+#include <lighting.inc.glsl>
 
 in vec4 p_Position;
 in vec4 p_Normal;
+in vec4 p_Tangent;
 in vec2 p_TexCoord;
+in float p_ViewDepth;
+in vec4 p_Color;
 
-in vec4 p_LightDir;
-in vec4 p_ViewDir;
+uniform int uHDROutput;
 
 out vec4 _Color;
 
 void main() {
-    vec4 normal = normalize(p_Normal);
-    if (HasNormalMap()) {
-        normal = texture(uNormalMap, p_TexCoord);
-    }
+    vec2 meshTexCoord = vec2(p_TexCoord.x, 1.0 - p_TexCoord.y);
+    vec3 normal = GetMappedNormal(normalize(p_Normal.xyz), p_Tangent, p_Position.xyz, meshTexCoord, p_TexCoord);
 
     vec4 ambient = uAmbient;
     if (HasAmbientMap()) {
@@ -52,25 +58,56 @@ void main() {
     }
     ambient *= 0.1;
 
-    float diff = max(0.0, dot(normal.xyz, p_LightDir.xyz));
-
-    vec4 diffuse = uDiffuse;
+    vec4 diffuseColor = uDiffuse;
     if (HasDiffuseMap()) {
-        diffuse = texture(uDiffuseMap, p_TexCoord);
+        diffuseColor = texture(uDiffuseMap, p_TexCoord);
     }
-    diffuse = vec4(diff * diffuse.rgb, diffuse.a);
-
-    float spec = pow(max(0.0, dot(normal.xyz, p_LightDir.xyz)), 32.0);
+    if (HasAlphaMap()) {
+        diffuseColor.a *= texture(uAlphaMap, p_TexCoord).r;
+    }
+    diffuseColor *= p_Color;
 
-    vec4 specular = uSpecular;
+    vec4 specularColor = uSpecular;
     if (HasSpecularMap()) {
-        specular = texture(uSpecularMap, p_TexCoord);
+        specularColor = texture(uSpecularMap, p_TexCoord);
+    }
+
+    vec3 V = normalize(uCameraPosition - p_Position.xyz);
+
+    float shininess = (uShininess > 0.0 ? uShininess : 32.0);
+
+    vec3 diffuse = vec3(0.0);
+    vec3 specular = vec3(0.0);
+
+    if (uLightCount == 0) {
+        // Fallback light when none are in the scene
+        vec3 L = normalize(vec3(0.2, 1.0, 0.3));
+        diffuse = max(0.0, dot(normal, L)) * diffuseColor.rgb;
+        specular = pow(max(0.0, dot(normal, normalize(L + V))), shininess) * specularColor.rgb;
+    }
+
+    for (int i = 0; i < uLightCount; ++i) {
+        vec3 L;
+        vec3 radiance = GetLightRadiance(uLights[i], p_Position.xyz, L);
+
+        float NdotL = max(0.0, dot(normal, L));
+        radiance *= GetShadow(uLights[i].ShadowIndex, p_Position.xyz, p_ViewDepth, NdotL);
+
+        float spec = pow(max(0.0, dot(normal, normalize(L + V))), shininess);
+
+        diffuse += NdotL * radiance * diffuseColor.rgb;
+        specular += spec * radiance * specularColor.rgb;
+    }
+
+    vec3 color = ambient.rgb + diffuse + specular;
+    if (uHDROutput != 0) {
+        // Colors are authored in gamma space, convert them for post-processing
+        color = pow(color, vec3(2.2));
     }
-    specular = vec4(spec * vec3(specular.rgb), specular.a);
 
-    _Color = vec4(ambient.rgb + diffuse.rgb + specular.rgb, diffuse.a);
+    _Color = vec4(color, diffuseColor.a);
 }
-	`
+`
 )
 
 // DefaultShader is the default shader used to render meshes
@@ -78,7 +115,10 @@ type DefaultShader struct {
 	Shader
 }
 
-var _defaultShader *DefaultShader
+var (
+	_defaultShader          *DefaultShader
+	_defaultInstancedShader *DefaultShader
+)
 
 // GetDefaultShader returns an instance of the DefaultShader
 func GetDefaultShader() *DefaultShader {
@@ -100,6 +140,26 @@ func GetDefaultShader() *DefaultShader {
 	return _defaultShader
 }
 
+// GetDefaultInstancedShader returns an instance of the DefaultShader that reads the model matrix and color from instance attributes
+func GetDefaultInstancedShader() *DefaultShader {
+	if _defaultInstancedShader != nil {
+		return _defaultInstancedShader
+	}
+	Loadf("Loading Default Instanced Shader")
+	_defaultInstancedShader = &DefaultShader{}
+	_defaultInstancedShader.InitFromData(
+		&ShaderData{
+			Code: withDefines(defaultShaderVert, "USE_INSTANCING"),
+			Type: gl.VERTEX_SHADER,
+		},
+		&ShaderData{
+			Code: withDefines(defaultShaderFrag, "USE_INSTANCING"),
+			Type: gl.FRAGMENT_SHADER,
+		},
+	)
+	return _defaultInstancedShader
+}
+
 // Bind implements the Shader interface
 func (s *DefaultShader) Bind(ctx *RenderContext, data interface{}) {
 	s.Shader.Bind(ctx, data)
@@ -108,12 +168,16 @@ func (s *DefaultShader) Bind(ctx *RenderContext, data interface{}) {
 		model = data.(mgl32.Mat4)
 	}
 
-	mvp := ctx.Projection.
-		Mul4(ctx.Camera.View).
-		Mul4(model)
-
 	gl.UniformMatrix4fv(s.UniformLocation("uProjection"), 1, false, &ctx.Projection[0])
 	gl.UniformMatrix4fv(s.UniformLocation("uView"), 1, false, &ctx.Camera.View[0])
+	s.SetModel(ctx, model)
+
+	BindLights(s, ctx)
+
+	gl.Uniform1i(s.UniformLocation("uHDROutput"), boolToInt32(ctx.HDR))
+}
+
+// SetModel implements the IModelShader interface
+func (s *DefaultShader) SetModel(ctx *RenderContext, model mgl32.Mat4) {
 	gl.UniformMatrix4fv(s.UniformLocation("uModel"), 1, false, &model[0])
-	gl.UniformMatrix4fv(s.UniformLocation("uMVP"), 1, false, &mvp[0])
 }
@@ -0,0 +1,146 @@
+package dusk
+
+import "github.com/WhoBrokeTheBuild/GoDusk/m32"
+
+// EasingFunc maps the progress of a Tween from 0 to 1 onto an eased progress
+// The result starts at 0 and ends at 1, but may go outside of that range in between, such as with EaseOutBack
+type EasingFunc func(t float32) float32
+
+// EaseLinear moves at a constant speed
+func EaseLinear(t float32) float32 {
+	return t
+}
+
+// EaseInQuad starts slowly and accelerates
+func EaseInQuad(t float32) float32 {
+	return t * t
+}
+
+// EaseOutQuad starts quickly and decelerates
+func EaseOutQuad(t float32) float32 {
+	return t * (2 - t)
+}
+
+// EaseInOutQuad accelerates until halfway, then decelerates
+func EaseInOutQuad(t float32) float32 {
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	return -1 + (4-2*t)*t
+}
+
+// EaseInCubic starts slowly and accelerates, more sharply than EaseInQuad
+func EaseInCubic(t float32) float32 {
+	return t * t * t
+}
+
+// EaseOutCubic starts quickly and decelerates, more sharply than EaseOutQuad
+func EaseOutCubic(t float32) float32 {
+	t--
+	return t*t*t + 1
+}
+
+// EaseInOutCubic accelerates until halfway, then decelerates
+func EaseInOutCubic(t float32) float32 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	t = 2*t - 2
+	return 0.5*t*t*t + 1
+}
+
+// EaseInSine starts slowly along a quarter sine wave
+func EaseInSine(t float32) float32 {
+	return 1 - m32.Cos(t*m32.Pi/2)
+}
+
+// EaseOutSine decelerates along a quarter sine wave
+func EaseOutSine(t float32) float32 {
+	return m32.Sin(t * m32.Pi / 2)
+}
+
+// EaseInOutSine follows half a sine wave, which is useful for smooth back and forth movement
+func EaseInOutSine(t float32) float32 {
+	return 0.5 * (1 - m32.Cos(t*m32.Pi))
+}
+
+// EaseInExpo starts very slowly and accelerates exponentially
+func EaseInExpo(t float32) float32 {
+	if t <= 0 {
+		return 0
+	}
+	return m32.Pow(2, 10*(t-1))
+}
+
+// EaseOutExpo starts very quickly and decelerates exponentially
+func EaseOutExpo(t float32) float32 {
+	if t >= 1 {
+		return 1
+	}
+	return 1 - m32.Pow(2, -10*t)
+}
+
+// EaseInOutExpo accelerates exponentially until halfway, then decelerates
+func EaseInOutExpo(t float32) float32 {
+	if t <= 0 || t >= 1 {
+		return t
+	}
+	if t < 0.5 {
+		return 0.5 * m32.Pow(2, 20*t-10)
+	}
+	return 1 - 0.5*m32.Pow(2, -20*t+10)
+}
+
+// backOvershoot controls how far the Back easings go past their start and end
+const backOvershoot = 1.70158
+
+// EaseInBack pulls back slightly before moving forward
+func EaseInBack(t float32) float32 {
+	return t * t * ((backOvershoot+1)*t - backOvershoot)
+}
+
+// EaseOutBack overshoots the end slightly, then settles
+func EaseOutBack(t float32) float32 {
+	t--
+	return t*t*((backOvershoot+1)*t+backOvershoot) + 1
+}
+
+// EaseInOutBack pulls back at the start and overshoots at the end
+func EaseInOutBack(t float32) float32 {
+	const s = backOvershoot * 1.525
+	t *= 2
+	if t < 1 {
+		return 0.5 * (t * t * ((s+1)*t - s))
+	}
+	t -= 2
+	return 0.5 * (t*t*((s+1)*t+s) + 2)
+}
+
+// EaseOutBounce bounces against the end like a dropped ball
+func EaseOutBounce(t float32) float32 {
+	switch {
+	case t < 1/2.75:
+		return 7.5625 * t * t
+	case t < 2/2.75:
+		t -= 1.5 / 2.75
+		return 7.5625*t*t + 0.75
+	case t < 2.5/2.75:
+		t -= 2.25 / 2.75
+		return 7.5625*t*t + 0.9375
+	}
+	t -= 2.625 / 2.75
+	return 7.5625*t*t + 0.984375
+}
+
+// EaseInBounce bounces against the start before moving to the end
+func EaseInBounce(t float32) float32 {
+	return 1 - EaseOutBounce(1-t)
+}
+
+// EaseOutElastic overshoots the end and springs back and forth until it settles
+func EaseOutElastic(t float32) float32 {
+	if t <= 0 || t >= 1 {
+		return t
+	}
+	return m32.Pow(2, -10*t)*m32.Sin((t*10-0.75)*(2*m32.Pi/3)) + 1
+}
@@ -9,6 +9,7 @@ type IEntity interface {
 
 	AddComponent(IComponent)
 	RemoveComponent(IComponent)
+	GetComponents() []IComponent
 
 	Transform() *Transform
 	SetTransform(*Transform)
@@ -62,6 +63,11 @@ func (e *Entity) RemoveComponent(component IComponent) {
 	}
 }
 
+// GetComponents returns all Components attached to the Entity
+func (e *Entity) GetComponents() []IComponent {
+	return e.components
+}
+
 // Transform returns the current transform
 func (e *Entity) Transform() *Transform {
 	return e.transform
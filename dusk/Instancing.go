@@ -0,0 +1,262 @@
+package dusk
+
+import (
+	"C"
+
+	gl "github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// InstanceData is the per-instance attributes of an instanced draw
+type InstanceData struct {
+	Matrix mgl32.Mat4
+	Color  mgl32.Vec4
+}
+
+// InstanceBuffer is an OpenGL Buffer of InstanceData
+type InstanceBuffer struct {
+	vbo      uint32
+	count    int
+	capacity int
+}
+
+// NewInstanceBuffer returns a new, empty InstanceBuffer
+func NewInstanceBuffer() *InstanceBuffer {
+	b := &InstanceBuffer{}
+	gl.GenBuffers(1, &b.vbo)
+	return b
+}
+
+// Delete frees all resources owned by the InstanceBuffer
+func (b *InstanceBuffer) Delete() {
+	if b.vbo != InvalidID {
+		gl.DeleteBuffers(1, &b.vbo)
+		b.vbo = InvalidID
+	}
+	b.count = 0
+	b.capacity = 0
+}
+
+// Count returns the number of instances in the buffer
+func (b *InstanceBuffer) Count() int {
+	return b.count
+}
+
+// SetData replaces the contents of the buffer
+func (b *InstanceBuffer) SetData(data []InstanceData) {
+	const F = C.sizeof_float
+	const size = (16 + 4) * F
+
+	b.count = len(data)
+	if b.count == 0 {
+		return
+	}
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, b.vbo)
+	if b.count > b.capacity {
+		b.capacity = b.count
+		gl.BufferData(gl.ARRAY_BUFFER, b.capacity*size, gl.Ptr(&data[0]), gl.DYNAMIC_DRAW)
+	} else {
+		gl.BufferSubData(gl.ARRAY_BUFFER, 0, b.count*size, gl.Ptr(&data[0]))
+	}
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+}
+
+// bind sets the instance attributes of the currently bound Vertex Array to read from this buffer
+func (b *InstanceBuffer) bind() {
+	const F = C.sizeof_float
+	const stride = (16 + 4) * F
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, b.vbo)
+
+	// A mat4 attribute takes four consecutive IDs, one per column
+	for i := uint32(0); i < 4; i++ {
+		id := InstanceMatrixAttrID + i
+		gl.EnableVertexAttribArray(id)
+		gl.VertexAttribPointer(id, 4, gl.FLOAT, false, stride, gl.PtrOffset(int(i)*4*F))
+		gl.VertexAttribDivisor(id, 1)
+	}
+
+	gl.EnableVertexAttribArray(InstanceColorAttrID)
+	gl.VertexAttribPointer(InstanceColorAttrID, 4, gl.FLOAT, false, stride, gl.PtrOffset(16*F))
+	gl.VertexAttribDivisor(InstanceColorAttrID, 1)
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+}
+
+// unbind disables the instance attributes of the currently bound Vertex Array
+func (b *InstanceBuffer) unbind() {
+	for i := uint32(0); i < 4; i++ {
+		gl.DisableVertexAttribArray(InstanceMatrixAttrID + i)
+	}
+	gl.DisableVertexAttribArray(InstanceColorAttrID)
+}
+
+// DrawInstanced draws the Mesh's vertices once for every instance in the buffer, without binding a Material
+func (m *Mesh) DrawInstanced(b *InstanceBuffer) {
+	if b.Count() == 0 {
+		return
+	}
+
+	gl.BindVertexArray(m.vao)
+	b.bind()
+	gl.DrawArraysInstanced(gl.TRIANGLES, 0, m.count, int32(b.Count()))
+	b.unbind()
+}
+
+// RenderInstanced renders the Mesh once for every instance in the buffer
+func (m *Mesh) RenderInstanced(s IShader, b *InstanceBuffer) {
+	if m.material != nil {
+		m.material.Bind(s)
+	}
+
+	m.DrawInstanced(b)
+
+	if m.material != nil {
+		m.material.UnBind()
+	}
+}
+
+// instance is an Entity drawn by an InstancedModel
+type instance struct {
+	Entity IEntity
+	Color  mgl32.Vec4
+}
+
+// InstancedModel is a Model that is drawn at the Transform of every Entity added to it, with one draw call per Mesh
+// The Transform of the InstancedModel's own Entity is not used
+type InstancedModel struct {
+	Model
+
+	instances []instance
+	data      []InstanceData
+	buffer    *InstanceBuffer
+}
+
+// NewInstancedModelFromFile returns a new InstancedModel from the given file, using the DefaultInstancedShader
+func NewInstancedModelFromFile(entity IEntity, filename string) (*InstancedModel, error) {
+	m := &InstancedModel{
+		Model: Model{
+			Shader:         GetDefaultInstancedShader(),
+			CastShadows:    true,
+			ReceiveShadows: true,
+			meshes:         map[string]*Mesh{},
+		},
+		instances: []instance{},
+		data:      []InstanceData{},
+		buffer:    NewInstanceBuffer(),
+	}
+	m.Init(entity)
+
+	err := m.LoadFromFile(filename)
+	if err != nil {
+		m.Delete()
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Delete frees all resources owned by the InstancedModel
+func (m *InstancedModel) Delete() {
+	if m.buffer != nil {
+		m.buffer.Delete()
+		m.buffer = nil
+	}
+	m.instances = []instance{}
+	m.Model.Delete()
+}
+
+// AddInstance adds an Entity to be drawn with the given color, which is multiplied with the Material's color
+func (m *InstancedModel) AddInstance(entity IEntity, color mgl32.Vec4) {
+	m.instances = append(m.instances, instance{
+		Entity: entity,
+		Color:  color,
+	})
+}
+
+// RemoveInstance removes every instance of an Entity from the InstancedModel
+func (m *InstancedModel) RemoveInstance(entity IEntity) {
+	kept := m.instances[:0]
+	for _, inst := range m.instances {
+		if inst.Entity != entity {
+			kept = append(kept, inst)
+		}
+	}
+	m.instances = kept
+}
+
+// SetInstanceColor changes the color of an Entity's instance
+func (m *InstancedModel) SetInstanceColor(entity IEntity, color mgl32.Vec4) {
+	for i := range m.instances {
+		if m.instances[i].Entity == entity {
+			m.instances[i].Color = color
+		}
+	}
+}
+
+// GetInstances returns the Entities drawn by the InstancedModel
+func (m *InstancedModel) GetInstances() []IEntity {
+	entities := make([]IEntity, 0, len(m.instances))
+	for _, i := range m.instances {
+		entities = append(entities, i.Entity)
+	}
+	return entities
+}
+
+// updateBuffer uploads the visible instances, and returns how many were culled
+func (m *InstancedModel) updateBuffer(ctx *RenderContext) int {
+	culled := 0
+	m.data = m.data[:0]
+	for _, i := range m.instances {
+		if i.Entity.Transform() == nil {
+			continue
+		}
+
+		matrix := i.Entity.Transform().GetWorldMatrix()
+		if !ctx.IsVisible(m.bounds.Transform(matrix)) {
+			culled++
+			continue
+		}
+
+		m.data = append(m.data, InstanceData{
+			Matrix: matrix,
+			Color:  i.Color,
+		})
+	}
+	m.buffer.SetData(m.data)
+	return culled
+}
+
+// Render draws every instance, it does not use the RenderQueue
+func (m *InstancedModel) Render(ctx *RenderContext) {
+	if ctx.Pass == ShadowPass && !m.CastShadows {
+		return
+	}
+
+	culled := m.updateBuffer(ctx)
+	if ctx.Queue != nil {
+		ctx.Queue.Stats.Culled += culled
+	}
+	if m.buffer.Count() == 0 {
+		return
+	}
+
+	if ctx.Pass == ShadowPass {
+		s := GetShadowInstancedShader()
+		s.Bind(ctx, mgl32.Ident4())
+		for _, mesh := range m.meshes {
+			mesh.DrawInstanced(m.buffer)
+		}
+		return
+	}
+
+	m.Shader.Bind(ctx, mgl32.Ident4())
+	gl.Uniform1i(m.Shader.UniformLocation("uReceiveShadows"), boolToInt32(m.ReceiveShadows))
+	for _, mesh := range m.meshes {
+		mesh.RenderInstanced(m.Shader, m.buffer)
+		if ctx.Queue != nil {
+			ctx.Queue.Stats.DrawCalls++
+		}
+	}
+}
@@ -14,9 +14,44 @@ type ILayer interface {
 	GetEntities() []IEntity
 }
 
+// IOverlayLayer is a Layer that is drawn on top of the scene, after post-processing
+type IOverlayLayer interface {
+	ILayer
+
+	IsOverlay() bool
+}
+
+// IMaskedLayer is a Layer that is only rendered by Cameras whose LayerMask includes its Mask
+type IMaskedLayer interface {
+	ILayer
+
+	GetMask() uint32
+}
+
+// DefaultLayerMask is the Mask of a Layer that has not been given one
+const DefaultLayerMask = uint32(1)
+
+// getLayerMask returns the Mask of a Layer
+func getLayerMask(layer ILayer) uint32 {
+	if m, ok := layer.(IMaskedLayer); ok {
+		return m.GetMask()
+	}
+	return DefaultLayerMask
+}
+
+// isOverlay returns whether a Layer should be drawn after post-processing
+func isOverlay(layer ILayer) bool {
+	if o, ok := layer.(IOverlayLayer); ok {
+		return o.IsOverlay()
+	}
+	return false
+}
+
 // Layer is a basic Layer
 type Layer struct {
 	entities []IEntity
+
+	mask uint32
 }
 
 // NewLayer returns a new, initialized Layer
@@ -59,13 +94,32 @@ func (s *Layer) Update(ctx *UpdateContext) {
 	}
 }
 
-// Render calls Render() on all entities
+// Render calls Render() on all entities, and then flushes the RenderQueue and SpriteBatch
 func (s *Layer) Render(ctx *RenderContext) {
 	for _, e := range s.entities {
 		e.Render(ctx)
 	}
+	if ctx.Queue != nil {
+		ctx.Queue.Flush(ctx)
+	}
+	if ctx.Sprites != nil && ctx.Pass == ColorPass {
+		ctx.Sprites.Flush(ctx)
+	}
 }
 
 func (s *Layer) GetEntities() []IEntity {
 	return s.entities
 }
+
+// GetMask implements the IMaskedLayer interface
+func (s *Layer) GetMask() uint32 {
+	if s.mask == 0 {
+		return DefaultLayerMask
+	}
+	return s.mask
+}
+
+// SetMask sets the bits a Camera's LayerMask must contain to render the Layer, 0 uses DefaultLayerMask
+func (s *Layer) SetMask(mask uint32) {
+	s.mask = mask
+}
@@ -0,0 +1,206 @@
+package dusk
+
+import (
+	"fmt"
+
+	"github.com/WhoBrokeTheBuild/GoDusk/m32"
+
+	gl "github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// LightType is the type of a Light
+type LightType int
+
+const (
+	// DirectionalLight is infinitely far away and lights everything from one direction, e.g. the sun
+	DirectionalLight LightType = iota
+	// PointLight shines in all directions from its Entity's position
+	PointLight
+	// SpotLight shines in a cone from its Entity's position
+	SpotLight
+)
+
+const (
+	// MaxLights is the maximum number of lights passed to a shader
+	MaxLights = 8
+	// MaxShadowMaps is the maximum number of shadow casting lights passed to a shader
+	MaxShadowMaps = 4
+	// MaxShadowCascades is the maximum number of cascades for a DirectionalLight
+	MaxShadowCascades = 4
+)
+
+func init() {
+	AddShaderDefines(map[string]interface{}{
+		"MAX_LIGHTS":      MaxLights,
+		"MAX_SHADOW_MAPS": MaxShadowMaps,
+		"MAX_CASCADES":    MaxShadowCascades,
+
+		"LIGHT_DIRECTIONAL": int(DirectionalLight),
+		"LIGHT_POINT":       int(PointLight),
+		"LIGHT_SPOT":        int(SpotLight),
+	})
+
+	for i := range lightUniforms {
+		u := &lightUniforms[i]
+		u.Type = fmt.Sprintf("uLights[%d].Type", i)
+		u.Position = fmt.Sprintf("uLights[%d].Position", i)
+		u.Direction = fmt.Sprintf("uLights[%d].Direction", i)
+		u.Color = fmt.Sprintf("uLights[%d].Color", i)
+		u.Range = fmt.Sprintf("uLights[%d].Range", i)
+		u.InnerCutoff = fmt.Sprintf("uLights[%d].InnerCutoff", i)
+		u.OuterCutoff = fmt.Sprintf("uLights[%d].OuterCutoff", i)
+		u.ShadowIndex = fmt.Sprintf("uLights[%d].ShadowIndex", i)
+	}
+	for i := range shadowUniforms {
+		u := &shadowUniforms[i]
+		u.Map = fmt.Sprintf("uShadowMaps[%d]", i)
+		u.Bias = fmt.Sprintf("uShadowBias[%d]", i)
+		u.Cascades = fmt.Sprintf("uShadowCascades[%d]", i)
+		u.Splits = fmt.Sprintf("uShadowSplits[%d]", i*MaxShadowCascades)
+		for j := range u.Matrices {
+			u.Matrices[j] = fmt.Sprintf("uShadowMatrices[%d]", i*MaxShadowCascades+j)
+		}
+	}
+}
+
+// Light is a Component that illuminates the scene from its Entity's position
+type Light struct {
+	Component
+
+	Type      LightType
+	Color     mgl32.Vec3
+	Intensity float32
+
+	// Direction is the world space direction a Directional or Spot Light shines in
+	Direction mgl32.Vec3
+
+	// Range is the distance at which a Point or Spot Light stops having an effect
+	Range float32
+
+	// InnerAngle and OuterAngle are the angles in radians of the Spot Light's cone, light fades between them
+	InnerAngle float32
+	OuterAngle float32
+
+	CastShadows bool
+
+	// ShadowBias is the depth offset used to prevent shadow acne
+	ShadowBias float32
+	// ShadowResolution is the width and height of each shadow map
+	ShadowResolution int
+	// ShadowCascades is the number of shadow maps a Directional Light splits the view into
+	ShadowCascades int
+	// ShadowDistance is how far from the camera a Directional Light draws shadows
+	ShadowDistance float32
+
+	shadowMap *ShadowMap
+}
+
+// NewLight returns a new Light of the given type with default settings
+func NewLight(entity IEntity, t LightType) *Light {
+	l := &Light{
+		Type:             t,
+		Color:            mgl32.Vec3{1, 1, 1},
+		Intensity:        1.0,
+		Direction:        mgl32.Vec3{-0.2, -1.0, -0.3}.Normalize(),
+		Range:            20.0,
+		InnerAngle:       mgl32.DegToRad(20.0),
+		OuterAngle:       mgl32.DegToRad(30.0),
+		CastShadows:      false,
+		ShadowBias:       0.005,
+		ShadowResolution: 2048,
+		ShadowCascades:   4,
+		ShadowDistance:   100.0,
+	}
+	l.Init(entity)
+	return l
+}
+
+// Delete frees all resources owned by the Light
+func (l *Light) Delete() {
+	if l.shadowMap != nil {
+		l.shadowMap.Delete()
+		l.shadowMap = nil
+	}
+	l.Component.Delete()
+}
+
+// GetPosition returns the world space position of the Light
+func (l *Light) GetPosition() mgl32.Vec3 {
+	if l.GetEntity() == nil {
+		return mgl32.Vec3{}
+	}
+	return l.GetEntity().Transform().GetWorldPosition()
+}
+
+// GetShadowMap returns the Light's ShadowMap, or nil if it has not rendered any shadows
+func (l *Light) GetShadowMap() *ShadowMap {
+	return l.shadowMap
+}
+
+// lightUniforms holds the uniform names for each element of uLights[]
+var lightUniforms [MaxLights]struct {
+	Type        string
+	Position    string
+	Direction   string
+	Color       string
+	Range       string
+	InnerCutoff string
+	OuterCutoff string
+	ShadowIndex string
+}
+
+// shadowUniforms holds the uniform names for each element of the shadow arrays
+var shadowUniforms [MaxShadowMaps]struct {
+	Map      string
+	Bias     string
+	Cascades string
+	// Splits is the first of the MaxShadowCascades elements for the shadow map
+	Splits   string
+	Matrices [MaxShadowCascades]string
+}
+
+// BindLights sets the lighting and shadow uniforms from lighting.inc.glsl and shadow.inc.glsl
+func BindLights(s IShader, ctx *RenderContext) {
+	if ctx.Camera != nil {
+		camPos := ctx.Camera.View.Inv().Col(3).Vec3()
+		gl.Uniform3fv(s.UniformLocation("uCameraPosition"), 1, &camPos[0])
+	}
+
+	count := 0
+	shadows := 0
+	for _, l := range ctx.Lights {
+		if count == MaxLights {
+			break
+		}
+		u := &lightUniforms[count]
+
+		pos := l.GetPosition()
+		dir := l.Direction.Normalize()
+		color := l.Color.Mul(l.Intensity)
+
+		gl.Uniform1i(s.UniformLocation(u.Type), int32(l.Type))
+		gl.Uniform3fv(s.UniformLocation(u.Position), 1, &pos[0])
+		gl.Uniform3fv(s.UniformLocation(u.Direction), 1, &dir[0])
+		gl.Uniform3fv(s.UniformLocation(u.Color), 1, &color[0])
+		gl.Uniform1f(s.UniformLocation(u.Range), l.Range)
+		gl.Uniform1f(s.UniformLocation(u.InnerCutoff), m32.Cos(l.InnerAngle))
+		gl.Uniform1f(s.UniformLocation(u.OuterCutoff), m32.Cos(l.OuterAngle))
+
+		index := int32(-1)
+		if l.CastShadows && l.shadowMap != nil && shadows < MaxShadowMaps {
+			index = int32(shadows)
+			l.shadowMap.bindUniforms(s, shadows)
+			shadows++
+		}
+		gl.Uniform1i(s.UniformLocation(u.ShadowIndex), index)
+
+		count++
+	}
+	gl.Uniform1i(s.UniformLocation("uLightCount"), int32(count))
+
+	// Samplers must always point at a unit with the correct type, even if unused
+	for ; shadows < MaxShadowMaps; shadows++ {
+		gl.Uniform1i(s.UniformLocation(shadowUniforms[shadows].Map), int32(ShadowMapTextureUnit+shadows))
+	}
+}
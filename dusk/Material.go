@@ -1,32 +1,88 @@
 package dusk
 
 import (
+	"github.com/WhoBrokeTheBuild/GoDusk/m32"
+
 	gl "github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
 // Material represents a collection of settings and textures
 type Material struct {
-	Ambient  mgl32.Vec4
-	Diffuse  mgl32.Vec4
-	Specular mgl32.Vec4
+	Ambient   mgl32.Vec4
+	Diffuse   mgl32.Vec4
+	Specular  mgl32.Vec4
+	Shininess float32
 
 	AmbientMap  *Texture
 	DiffuseMap  *Texture
 	SpecularMap *Texture
-	NormalMap   *Texture
+	// NormalMap is a tangent-space normal map, with green pointing towards increasing V as in OpenGL
+	NormalMap *Texture
+	// FlipNormalMapGreen flips the green channel of the NormalMap, for maps made with DirectX's convention
+	FlipNormalMapGreen bool
+
+	// Metallic-Roughness PBR settings, used by the PBRShader
+	BaseColor mgl32.Vec4
+	Metallic  float32
+	Roughness float32
+	// Occlusion is the strength of the OcclusionMap, from 0.0 to 1.0
+	Occlusion float32
+	Emissive  mgl32.Vec3
+
+	BaseColorMap *Texture
+	// MetallicMap is sampled from the blue channel, matching glTF's combined metallic-roughness textures
+	MetallicMap *Texture
+	// RoughnessMap is sampled from the green channel, matching glTF's combined metallic-roughness textures
+	RoughnessMap *Texture
+	OcclusionMap *Texture
+	EmissiveMap  *Texture
+
+	// AlphaMap is sampled from the red channel, and multiplies the alpha of the Diffuse and BaseColor
+	AlphaMap *Texture
+
+	// ShininessMap, DisplacementMap and ReflectionMap are loaded for custom shaders, and are not bound by Bind
+	ShininessMap    *Texture
+	DisplacementMap *Texture
+	ReflectionMap   *Texture
+
+	// Transparent forces the Material to be drawn with the transparent items of a RenderQueue
+	Transparent bool
 }
 
 // MaterialData is an intermediate object used to load a Material
 type MaterialData struct {
-	Ambient  mgl32.Vec4
-	Diffuse  mgl32.Vec4
-	Specular mgl32.Vec4
+	Ambient   mgl32.Vec4
+	Diffuse   mgl32.Vec4
+	Specular  mgl32.Vec4
+	Shininess float32
 
 	AmbientMap  string
 	DiffuseMap  string
 	SpecularMap string
 	NormalMap   string
+
+	FlipNormalMapGreen bool
+
+	BaseColor mgl32.Vec4
+	Metallic  float32
+	Roughness float32
+	Occlusion float32
+	Emissive  mgl32.Vec3
+
+	BaseColorMap string
+	MetallicMap  string
+	RoughnessMap string
+	OcclusionMap string
+	EmissiveMap  string
+
+	AlphaMap string
+
+	ShininessMap    string
+	DisplacementMap string
+	ReflectionMap   string
+
+	Transparent bool
 }
 
 const (
@@ -36,11 +92,42 @@ const (
 	NormalAttrID uint32 = 1
 	// TexCoordAttrID is the attribute ID of _TexCoord in GLSL
 	TexCoordAttrID uint32 = 2
+	// ColorAttrID is the attribute ID of _VertexColor in GLSL
+	ColorAttrID uint32 = 3
+	// BoneIndicesAttrID is the attribute ID of _BoneIndices in GLSL
+	BoneIndicesAttrID uint32 = 4
+	// BoneWeightsAttrID is the attribute ID of _BoneWeights in GLSL
+	BoneWeightsAttrID uint32 = 5
+	// TangentAttrID is the attribute ID of _Tangent in GLSL, with the handedness of the bitangent in w
+	TangentAttrID uint32 = 6
+	// InstanceMatrixAttrID is the attribute ID of _InstanceMatrix in GLSL, it uses four IDs starting from this one
+	InstanceMatrixAttrID uint32 = 8
+	// InstanceColorAttrID is the attribute ID of _InstanceColor in GLSL
+	InstanceColorAttrID uint32 = 12
+
+	ambientMapFlag   uint32 = 1
+	diffuseMapFlag   uint32 = 2
+	specularMapFlag  uint32 = 4
+	normalMapFlag    uint32 = 8
+	baseColorMapFlag uint32 = 16
+	metallicMapFlag  uint32 = 32
+	roughnessMapFlag uint32 = 64
+	occlusionMapFlag uint32 = 128
+	emissiveMapFlag  uint32 = 256
+	alphaMapFlag     uint32 = 512
 
-	ambientMapFlag  uint32 = 1
-	diffuseMapFlag  uint32 = 2
-	specularMapFlag uint32 = 4
-	normalMapFlag   uint32 = 8
+	ambientMapUnit   uint32 = 0
+	diffuseMapUnit   uint32 = 1
+	specularMapUnit  uint32 = 2
+	normalMapUnit    uint32 = 3
+	baseColorMapUnit uint32 = 4
+	metallicMapUnit  uint32 = 5
+	roughnessMapUnit uint32 = 6
+	occlusionMapUnit uint32 = 7
+	emissiveMapUnit  uint32 = 8
+	// Unit 9 is the EnvironmentMapTextureUnit
+	alphaMapUnit     uint32 = 10
+	materialMapUnits uint32 = 11
 )
 
 func init() {
@@ -48,47 +135,102 @@ func init() {
 		"ATTR_POSITION": PositionAttrID,
 		"ATTR_NORMAL":   NormalAttrID,
 		"ATTR_TEXCOORD": TexCoordAttrID,
+		"ATTR_COLOR":    ColorAttrID,
+		"ATTR_TANGENT":  TangentAttrID,
+
+		"ATTR_BONE_INDICES": BoneIndicesAttrID,
+		"ATTR_BONE_WEIGHTS": BoneWeightsAttrID,
 
-		"FLAG_AMBIENT_MAP":  ambientMapFlag,
-		"FLAG_DIFFUSE_MAP":  diffuseMapFlag,
-		"FLAG_SPECULAR_MAP": specularMapFlag,
-		"FLAG_NORMAL_MAP":   normalMapFlag,
+		"ATTR_INSTANCE_MATRIX": InstanceMatrixAttrID,
+		"ATTR_INSTANCE_COLOR":  InstanceColorAttrID,
+
+		"FLAG_AMBIENT_MAP":    ambientMapFlag,
+		"FLAG_DIFFUSE_MAP":    diffuseMapFlag,
+		"FLAG_SPECULAR_MAP":   specularMapFlag,
+		"FLAG_NORMAL_MAP":     normalMapFlag,
+		"FLAG_BASE_COLOR_MAP": baseColorMapFlag,
+		"FLAG_METALLIC_MAP":   metallicMapFlag,
+		"FLAG_ROUGHNESS_MAP":  roughnessMapFlag,
+		"FLAG_OCCLUSION_MAP":  occlusionMapFlag,
+		"FLAG_EMISSIVE_MAP":   emissiveMapFlag,
+		"FLAG_ALPHA_MAP":      alphaMapFlag,
 	})
 }
 
 // NewMaterialFromData creates a new Material from the given MaterialData
+// If no BaseColor or BaseColorMap is set, the PBR settings are approximated from the Diffuse and Shininess
+// If an OcclusionMap is set with no Occlusion strength, it defaults to 1.0
+// A Diffuse or BaseColor left at zero is opaque black, so that the Material is not transparent
 func NewMaterialFromData(data *MaterialData) (*Material, error) {
 	var err error
-	m := &Material{
-		Ambient:  data.Ambient,
-		Diffuse:  data.Diffuse,
-		Specular: data.Specular,
+
+	tmp := *data
+	data = &tmp
+
+	if data.Diffuse == (mgl32.Vec4{}) {
+		data.Diffuse[3] = 1.0
 	}
 
-	if data.AmbientMap != "" {
-		m.AmbientMap, err = NewTextureFromFile(data.AmbientMap)
-		if err != nil {
-			return nil, err
+	if data.BaseColor == (mgl32.Vec4{}) && data.BaseColorMap == "" {
+		data.BaseColor = data.Diffuse
+		data.BaseColorMap = data.DiffuseMap
+		if data.Roughness == 0 {
+			data.Roughness = m32.Sqrt(2.0 / (m32.Max(data.Shininess, 1.0) + 2.0))
 		}
 	}
 
-	if data.DiffuseMap != "" {
-		m.DiffuseMap, err = NewTextureFromFile(data.DiffuseMap)
-		if err != nil {
-			return nil, err
-		}
+	if data.BaseColor == (mgl32.Vec4{}) {
+		data.BaseColor[3] = 1.0
 	}
 
-	if data.SpecularMap != "" {
-		m.SpecularMap, err = NewTextureFromFile(data.SpecularMap)
-		if err != nil {
-			return nil, err
-		}
+	occlusion := data.Occlusion
+	if occlusion == 0 && data.OcclusionMap != "" {
+		occlusion = 1.0
 	}
 
-	if data.NormalMap != "" {
-		m.NormalMap, err = NewTextureFromFile(data.NormalMap)
+	m := &Material{
+		Ambient:   data.Ambient,
+		Diffuse:   data.Diffuse,
+		Specular:  data.Specular,
+		Shininess: data.Shininess,
+
+		FlipNormalMapGreen: data.FlipNormalMapGreen,
+
+		BaseColor: data.BaseColor,
+		Metallic:  data.Metallic,
+		Roughness: data.Roughness,
+		Occlusion: occlusion,
+		Emissive:  data.Emissive,
+
+		Transparent: data.Transparent,
+	}
+
+	maps := []struct {
+		File string
+		Map  **Texture
+	}{
+		{data.AmbientMap, &m.AmbientMap},
+		{data.DiffuseMap, &m.DiffuseMap},
+		{data.SpecularMap, &m.SpecularMap},
+		{data.NormalMap, &m.NormalMap},
+		{data.BaseColorMap, &m.BaseColorMap},
+		{data.MetallicMap, &m.MetallicMap},
+		{data.RoughnessMap, &m.RoughnessMap},
+		{data.OcclusionMap, &m.OcclusionMap},
+		{data.EmissiveMap, &m.EmissiveMap},
+		{data.AlphaMap, &m.AlphaMap},
+		{data.ShininessMap, &m.ShininessMap},
+		{data.DisplacementMap, &m.DisplacementMap},
+		{data.ReflectionMap, &m.ReflectionMap},
+	}
+
+	for _, t := range maps {
+		if t.File == "" {
+			continue
+		}
+		*t.Map, err = NewTextureFromFile(t.File)
 		if err != nil {
+			m.Delete()
 			return nil, err
 		}
 	}
@@ -98,22 +240,45 @@ func NewMaterialFromData(data *MaterialData) (*Material, error) {
 
 // Delete frees all resources owned by the Material
 func (m *Material) Delete() {
-	if m.AmbientMap != nil {
-		m.AmbientMap.Delete()
-		m.AmbientMap = nil
-	}
-	if m.DiffuseMap != nil {
-		m.DiffuseMap.Delete()
-		m.DiffuseMap = nil
+	maps := []**Texture{
+		&m.AmbientMap,
+		&m.DiffuseMap,
+		&m.SpecularMap,
+		&m.NormalMap,
+		&m.BaseColorMap,
+		&m.MetallicMap,
+		&m.RoughnessMap,
+		&m.OcclusionMap,
+		&m.EmissiveMap,
+		&m.AlphaMap,
+		&m.ShininessMap,
+		&m.DisplacementMap,
+		&m.ReflectionMap,
 	}
-	if m.SpecularMap != nil {
-		m.SpecularMap.Delete()
-		m.SpecularMap = nil
+
+	for _, t := range maps {
+		if *t != nil {
+			(*t).Delete()
+			*t = nil
+		}
 	}
-	if m.NormalMap != nil {
-		m.NormalMap.Delete()
-		m.NormalMap = nil
+}
+
+// IsTransparent returns whether the Material needs blending, either because Transparent is set or its color has alpha
+func (m *Material) IsTransparent() bool {
+	return m.Transparent || m.Diffuse[3] < 1.0 || m.BaseColor[3] < 1.0 || m.AlphaMap != nil
+}
+
+// bindMap binds a texture to the given unit and sampler uniform, and returns flag if it is not nil
+func bindMap(s IShader, t *Texture, name string, unit, flag uint32) uint32 {
+	gl.Uniform1i(s.UniformLocation(name), int32(unit))
+	if t == nil {
+		return 0
 	}
+
+	gl.ActiveTexture(gl.TEXTURE0 + unit)
+	t.Bind()
+	return flag
 }
 
 // Bind sets all uniforms and textures used by this Material
@@ -121,51 +286,36 @@ func (m *Material) Bind(s IShader) {
 	flags := uint32(0)
 
 	gl.Uniform4fv(s.UniformLocation("uAmbient"), 1, &m.Ambient[0])
-	gl.Uniform1i(s.UniformLocation("uAmbientMap"), 0)
-	if m.AmbientMap != nil {
-		gl.ActiveTexture(gl.TEXTURE0)
-		m.AmbientMap.Bind()
-
-		flags |= ambientMapFlag
-	}
-
 	gl.Uniform4fv(s.UniformLocation("uDiffuse"), 1, &m.Diffuse[0])
-	gl.Uniform1i(s.UniformLocation("uDiffuseMap"), 1)
-	if m.DiffuseMap != nil {
-		gl.ActiveTexture(gl.TEXTURE1)
-		m.DiffuseMap.Bind()
-
-		flags |= diffuseMapFlag
-	}
-
 	gl.Uniform4fv(s.UniformLocation("uSpecular"), 1, &m.Specular[0])
-	gl.Uniform1i(s.UniformLocation("uSpecularMap"), 2)
-	if m.SpecularMap != nil {
-		gl.ActiveTexture(gl.TEXTURE2)
-		m.SpecularMap.Bind()
-
-		flags |= specularMapFlag
-	}
+	gl.Uniform1f(s.UniformLocation("uShininess"), m.Shininess)
 
-	gl.Uniform1i(s.UniformLocation("uNormalMap"), 3)
-	if m.NormalMap != nil {
-		gl.ActiveTexture(gl.TEXTURE3)
-		m.NormalMap.Bind()
+	gl.Uniform4fv(s.UniformLocation("uBaseColor"), 1, &m.BaseColor[0])
+	gl.Uniform1f(s.UniformLocation("uMetallic"), m.Metallic)
+	gl.Uniform1f(s.UniformLocation("uRoughness"), m.Roughness)
+	gl.Uniform1f(s.UniformLocation("uOcclusion"), m.Occlusion)
+	gl.Uniform3fv(s.UniformLocation("uEmissive"), 1, &m.Emissive[0])
 
-		flags |= normalMapFlag
-	}
+	flags |= bindMap(s, m.AmbientMap, "uAmbientMap", ambientMapUnit, ambientMapFlag)
+	flags |= bindMap(s, m.DiffuseMap, "uDiffuseMap", diffuseMapUnit, diffuseMapFlag)
+	flags |= bindMap(s, m.SpecularMap, "uSpecularMap", specularMapUnit, specularMapFlag)
+	flags |= bindMap(s, m.NormalMap, "uNormalMap", normalMapUnit, normalMapFlag)
+	flags |= bindMap(s, m.BaseColorMap, "uBaseColorMap", baseColorMapUnit, baseColorMapFlag)
+	flags |= bindMap(s, m.MetallicMap, "uMetallicMap", metallicMapUnit, metallicMapFlag)
+	flags |= bindMap(s, m.RoughnessMap, "uRoughnessMap", roughnessMapUnit, roughnessMapFlag)
+	flags |= bindMap(s, m.OcclusionMap, "uOcclusionMap", occlusionMapUnit, occlusionMapFlag)
+	flags |= bindMap(s, m.EmissiveMap, "uEmissiveMap", emissiveMapUnit, emissiveMapFlag)
+	flags |= bindMap(s, m.AlphaMap, "uAlphaMap", alphaMapUnit, alphaMapFlag)
 
 	gl.Uniform1ui(s.UniformLocation("uMapFlags"), flags)
+	gl.Uniform1i(s.UniformLocation("uFlipNormalMapGreen"), boolToInt32(m.FlipNormalMapGreen))
 }
 
 // UnBind resets the bindings used in Bind()
 func (m *Material) UnBind() {
+	for unit := uint32(0); unit < materialMapUnits; unit++ {
+		gl.ActiveTexture(gl.TEXTURE0 + unit)
+		gl.BindTexture(gl.TEXTURE_2D, 0)
+	}
 	gl.ActiveTexture(gl.TEXTURE0)
-	gl.BindTexture(gl.TEXTURE_2D, 0)
-	gl.ActiveTexture(gl.TEXTURE1)
-	gl.BindTexture(gl.TEXTURE_2D, 0)
-	gl.ActiveTexture(gl.TEXTURE2)
-	gl.BindTexture(gl.TEXTURE_2D, 0)
-	gl.ActiveTexture(gl.TEXTURE3)
-	gl.BindTexture(gl.TEXTURE_2D, 0)
 }
@@ -15,6 +15,10 @@ type Mesh struct {
 	vbo      uint32
 	size     int
 	count    int32
+	bounds   Bounds
+
+	// vertices are kept for raycasting
+	vertices []mgl32.Vec3
 }
 
 // MeshData is the intermediate data format for loading Meshes from Memory
@@ -25,6 +29,53 @@ type MeshData struct {
 	Vertices  []mgl32.Vec3
 	Normals   []mgl32.Vec3
 	TexCoords []mgl32.Vec2
+
+	// Colors are optional per-vertex colors, passed to shaders as _VertexColor
+	Colors []mgl32.Vec4
+
+	// Tangents and Bitangents are optional, and point along the U and V directions of the TexCoords
+	Tangents   []mgl32.Vec3
+	Bitangents []mgl32.Vec3
+
+	// BoneIndices and BoneWeights are the four bones of the Skeleton that move each vertex, and how much
+	BoneIndices []mgl32.Vec4
+	BoneWeights []mgl32.Vec4
+
+	// Skeleton is the bone hierarchy used for skinning, it may be shared with other MeshData from the same file
+	Skeleton *Skeleton
+	// Animations are the clips that animate the Skeleton
+	Animations []*AnimationClip
+
+	// Polygons is the index of the original polygon of each triangle, for loaders that triangulate larger polygons
+	Polygons []int
+
+	// Bounds are calculated from the Vertices when the Mesh is loaded, if not already set
+	Bounds Bounds
+}
+
+// ComputeBounds sets Bounds from the Vertices
+func (d *MeshData) ComputeBounds() {
+	d.Bounds = NewBoundsFromPoints(d.Vertices)
+}
+
+// HasTangents returns whether every vertex has a Normal and Tangent, for normal mapping
+func (d *MeshData) HasTangents() bool {
+	return len(d.Tangents) == len(d.Vertices) && len(d.Normals) == len(d.Vertices) && len(d.Vertices) > 0
+}
+
+// tangent returns the Tangent of a vertex, with w set to -1 if the Bitangent points the opposite way to the cross product of the Normal and Tangent
+func (d *MeshData) tangent(i int) mgl32.Vec4 {
+	t := d.Tangents[i]
+	w := float32(1)
+	if len(d.Bitangents) == len(d.Vertices) && d.Normals[i].Cross(t).Dot(d.Bitangents[i]) < 0 {
+		w = -1
+	}
+	return t.Vec4(w)
+}
+
+// HasBones returns whether every vertex has BoneIndices and BoneWeights
+func (d *MeshData) HasBones() bool {
+	return len(d.BoneIndices) == len(d.Vertices) && len(d.BoneWeights) == len(d.Vertices) && len(d.Vertices) > 0
 }
 
 // NewMeshFromData returns a new Mesh from the given MeshData
@@ -61,11 +112,20 @@ func (m *Mesh) LoadFromData(data *MeshData) error {
 
 	m.material = data.Material
 
+	if data.Bounds.IsZero() {
+		data.ComputeBounds()
+	}
+	m.bounds = data.Bounds
+	m.vertices = data.Vertices
+
 	m.count = int32(len(data.Vertices))
 	hasNorms := len(data.Normals) > 0
 	hasTxcds := len(data.TexCoords) > 0
+	hasColors := len(data.Colors) > 0
+	hasBones := data.HasBones()
+	hasTangents := data.HasTangents()
 
-	buf := make([]float32, 0, (len(data.Vertices)*3)+(len(data.Normals)*3)+(len(data.TexCoords)*2))
+	buf := make([]float32, 0, (len(data.Vertices)*3)+(len(data.Normals)*3)+(len(data.TexCoords)*2)+(len(data.Colors)*4)+(len(data.BoneIndices)*8)+(len(data.Tangents)*4))
 	for i := range data.Vertices {
 		buf = append(buf, data.Vertices[i][0], data.Vertices[i][1], data.Vertices[i][2])
 		if hasNorms {
@@ -74,6 +134,17 @@ func (m *Mesh) LoadFromData(data *MeshData) error {
 		if hasTxcds {
 			buf = append(buf, data.TexCoords[i][0], data.TexCoords[i][1])
 		}
+		if hasColors {
+			buf = append(buf, data.Colors[i][:]...)
+		}
+		if hasBones {
+			buf = append(buf, data.BoneIndices[i][:]...)
+			buf = append(buf, data.BoneWeights[i][:]...)
+		}
+		if hasTangents {
+			t := data.tangent(i)
+			buf = append(buf, t[:]...)
+		}
 	}
 
 	m.size = len(buf)
@@ -85,6 +156,15 @@ func (m *Mesh) LoadFromData(data *MeshData) error {
 	if hasTxcds {
 		stride += int32(2 * F)
 	}
+	if hasColors {
+		stride += int32(4 * F)
+	}
+	if hasBones {
+		stride += int32(8 * F)
+	}
+	if hasTangents {
+		stride += int32(4 * F)
+	}
 
 	offset := 0
 
@@ -108,6 +188,29 @@ func (m *Mesh) LoadFromData(data *MeshData) error {
 	if hasTxcds {
 		gl.EnableVertexAttribArray(TexCoordAttrID)
 		gl.VertexAttribPointer(TexCoordAttrID, 2, gl.FLOAT, false, stride, gl.PtrOffset(offset))
+		offset += 2 * F
+	}
+
+	if hasColors {
+		gl.EnableVertexAttribArray(ColorAttrID)
+		gl.VertexAttribPointer(ColorAttrID, 4, gl.FLOAT, false, stride, gl.PtrOffset(offset))
+		offset += 4 * F
+	}
+
+	if hasBones {
+		gl.EnableVertexAttribArray(BoneIndicesAttrID)
+		gl.VertexAttribPointer(BoneIndicesAttrID, 4, gl.FLOAT, false, stride, gl.PtrOffset(offset))
+		offset += 4 * F
+
+		gl.EnableVertexAttribArray(BoneWeightsAttrID)
+		gl.VertexAttribPointer(BoneWeightsAttrID, 4, gl.FLOAT, false, stride, gl.PtrOffset(offset))
+		offset += 4 * F
+	}
+
+	// Without tangents, _Tangent reads as (0, 0, 0, 1) and the shader finds them from the texcoords instead
+	if hasTangents {
+		gl.EnableVertexAttribArray(TangentAttrID)
+		gl.VertexAttribPointer(TangentAttrID, 4, gl.FLOAT, false, stride, gl.PtrOffset(offset))
 	}
 
 	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
@@ -118,6 +221,11 @@ func (m *Mesh) GetMaterial() *Material {
 	return m.material
 }
 
+// GetBounds returns the Bounds of the Mesh's vertices
+func (m *Mesh) GetBounds() Bounds {
+	return m.bounds
+}
+
 // UpdateData sets the data in the existing buffer
 func (m *Mesh) UpdateData(data *MeshData) error {
 	const F = C.sizeof_float
@@ -126,11 +234,20 @@ func (m *Mesh) UpdateData(data *MeshData) error {
 		m.material = data.Material
 	}
 
+	if data.Bounds.IsZero() {
+		data.ComputeBounds()
+	}
+	m.bounds = data.Bounds
+	m.vertices = data.Vertices
+
 	m.count = int32(len(data.Vertices))
 	hasNorms := len(data.Normals) > 0
 	hasTxcds := len(data.TexCoords) > 0
+	hasColors := len(data.Colors) > 0
+	hasBones := data.HasBones()
+	hasTangents := data.HasTangents()
 
-	buf := make([]float32, 0, (len(data.Vertices)*3)+(len(data.Normals)*3)+(len(data.TexCoords)*2))
+	buf := make([]float32, 0, (len(data.Vertices)*3)+(len(data.Normals)*3)+(len(data.TexCoords)*2)+(len(data.Colors)*4)+(len(data.BoneIndices)*8)+(len(data.Tangents)*4))
 	for i := range data.Vertices {
 		buf = append(buf, data.Vertices[i][0], data.Vertices[i][1], data.Vertices[i][2])
 		if hasNorms {
@@ -139,6 +256,17 @@ func (m *Mesh) UpdateData(data *MeshData) error {
 		if hasTxcds {
 			buf = append(buf, data.TexCoords[i][0], data.TexCoords[i][1])
 		}
+		if hasColors {
+			buf = append(buf, data.Colors[i][:]...)
+		}
+		if hasBones {
+			buf = append(buf, data.BoneIndices[i][:]...)
+			buf = append(buf, data.BoneWeights[i][:]...)
+		}
+		if hasTangents {
+			t := data.tangent(i)
+			buf = append(buf, t[:]...)
+		}
 	}
 
 	gl.BindBuffer(gl.ARRAY_BUFFER, m.vbo)
@@ -161,10 +289,15 @@ func (m *Mesh) Render(s IShader) {
 		m.material.Bind(s)
 	}
 
-	gl.BindVertexArray(m.vao)
-	gl.DrawArrays(gl.TRIANGLES, 0, m.count)
+	m.Draw()
 
 	if m.material != nil {
 		m.material.UnBind()
 	}
 }
+
+// Draw draws the Mesh's vertices without binding a Material
+func (m *Mesh) Draw() {
+	gl.BindVertexArray(m.vao)
+	gl.DrawArrays(gl.TRIANGLES, 0, m.count)
+}
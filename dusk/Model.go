@@ -3,6 +3,8 @@ package dusk
 import (
 	"fmt"
 	"path/filepath"
+
+	gl "github.com/go-gl/gl/v4.1-core/gl"
 )
 
 // ModelLoader is a function that loads mesh data
@@ -25,6 +27,16 @@ func (f *modelFormat) hasExt(ext string) bool {
 
 var _modelFormats = map[string]modelFormat{}
 
+// MeshProcessor is a function that modifies the MeshData of a file before its Meshes are created
+type MeshProcessor func(data []*MeshData) error
+
+var _meshProcessors = []MeshProcessor{}
+
+// AddMeshProcessor adds a function that is run on the MeshData of every file loaded by Model.LoadFromFile, in the order they were added
+func AddMeshProcessor(p MeshProcessor) {
+	_meshProcessors = append(_meshProcessors, p)
+}
+
 // RegisterModelFormat adds a new handler for loading mesh files
 func RegisterModelFormat(name string, exts []string, loader ModelLoader) {
 	_modelFormats[name] = modelFormat{
@@ -37,14 +49,26 @@ func RegisterModelFormat(name string, exts []string, loader ModelLoader) {
 type Model struct {
 	Component
 	Shader IShader
+
+	// CastShadows controls whether the Model is drawn into the ShadowMaps of Lights
+	CastShadows bool
+	// ReceiveShadows controls whether shadows are drawn onto the Model
+	ReceiveShadows bool
+
 	meshes map[string]*Mesh
+	bounds Bounds
+
+	skeleton   *Skeleton
+	animations []*AnimationClip
 }
 
 // NewModelFromFile returns a new Mesh from the given file
 func NewModelFromFile(entity IEntity, filename string) (*Model, error) {
 	m := &Model{
-		Shader: GetDefaultShader(),
-		meshes: map[string]*Mesh{},
+		Shader:         GetDefaultShader(),
+		CastShadows:    true,
+		ReceiveShadows: true,
+		meshes:         map[string]*Mesh{},
 	}
 	m.Init(entity)
 
@@ -57,6 +81,25 @@ func NewModelFromFile(entity IEntity, filename string) (*Model, error) {
 	return m, err
 }
 
+// NewModelFromData returns a new Model with a Mesh for each MeshData
+func NewModelFromData(entity IEntity, data []*MeshData) (*Model, error) {
+	m := &Model{
+		Shader:         GetDefaultShader(),
+		CastShadows:    true,
+		ReceiveShadows: true,
+		meshes:         map[string]*Mesh{},
+	}
+	m.Init(entity)
+
+	err := m.LoadFromData(data)
+	if err != nil {
+		m.Delete()
+		return nil, err
+	}
+
+	return m, nil
+}
+
 // LoadFromFile loads the meshes from a given file
 func (m *Model) LoadFromFile(filename string) error {
 	filename = filepath.Clean(filename)
@@ -84,11 +127,34 @@ func (m *Model) LoadFromFile(filename string) error {
 		return fmt.Errorf("No data loaded from [%v]", filename)
 	}
 
+	for _, p := range _meshProcessors {
+		err = p(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return m.LoadFromData(data)
+}
+
+// LoadFromData creates a Mesh for each MeshData, such as the Meshes of a SceneNode
+func (m *Model) LoadFromData(data []*MeshData) error {
 	for _, d := range data {
+		var err error
 		m.meshes[d.Name], err = NewMeshFromData(d)
 		if err != nil {
 			return err
 		}
+		m.bounds = m.bounds.Union(m.meshes[d.Name].GetBounds())
+
+		if d.Skeleton != nil && m.skeleton == nil {
+			m.skeleton = d.Skeleton
+		}
+		for _, a := range d.Animations {
+			if !m.hasAnimation(a) {
+				m.animations = append(m.animations, a)
+			}
+		}
 	}
 
 	return nil
@@ -98,9 +164,84 @@ func (m *Model) GetMeshes() map[string]*Mesh {
 	return m.meshes
 }
 
+// GetSkeleton returns the Skeleton used to skin the Meshes, or nil
+func (m *Model) GetSkeleton() *Skeleton {
+	return m.skeleton
+}
+
+// GetAnimations returns the AnimationClips loaded with the Model
+func (m *Model) GetAnimations() []*AnimationClip {
+	return m.animations
+}
+
+func (m *Model) hasAnimation(a *AnimationClip) bool {
+	for _, other := range m.animations {
+		if other == a {
+			return true
+		}
+	}
+	return false
+}
+
+// GetLocalBounds returns the Bounds of all Meshes, before the Entity's Transform is applied
+func (m *Model) GetLocalBounds() Bounds {
+	return m.bounds
+}
+
+// GetBounds returns the world space Bounds of all Meshes
+func (m *Model) GetBounds() Bounds {
+	return m.bounds.Transform(m.GetEntity().Transform().GetWorldMatrix())
+}
+
 func (m *Model) Render(ctx *RenderContext) {
-	m.Shader.Bind(ctx, m.GetEntity().Transform().GetMatrix())
+	if ctx.Pass == ShadowPass {
+		if !m.CastShadows {
+			return
+		}
+		s := GetShadowShader()
+		s.Bind(ctx, m.GetEntity().Transform().GetWorldMatrix())
+		setBoneUniforms(s, getBoneMatrices(m.GetEntity()))
+		for _, mesh := range m.meshes {
+			mesh.Draw()
+		}
+		return
+	}
+
+	matrix := m.GetEntity().Transform().GetWorldMatrix()
+	bones := getBoneMatrices(m.GetEntity())
+
+	if ctx.Queue != nil {
+		for _, mesh := range m.meshes {
+			bounds := mesh.GetBounds().Transform(matrix)
+			if !ctx.IsVisible(bounds) {
+				ctx.Queue.Stats.Culled++
+				continue
+			}
+
+			depth := float32(0)
+			if ctx.Camera != nil {
+				depth = -ctx.Camera.View.Mul4x1(bounds.Center.Vec4(1)).Z()
+			}
+
+			ctx.Queue.Submit(DrawItem{
+				Mesh:           mesh,
+				Material:       mesh.GetMaterial(),
+				Shader:         m.Shader,
+				Matrix:         matrix,
+				Depth:          depth,
+				ReceiveShadows: m.ReceiveShadows,
+				Bones:          bones,
+			})
+		}
+		return
+	}
+
+	m.Shader.Bind(ctx, matrix)
+	gl.Uniform1i(m.Shader.UniformLocation("uReceiveShadows"), boolToInt32(m.ReceiveShadows))
+	setBoneUniforms(m.Shader, bones)
 	for _, mesh := range m.meshes {
-		mesh.Render(m.Shader)
+		if ctx.IsVisible(mesh.GetBounds().Transform(matrix)) {
+			mesh.Render(m.Shader)
+		}
 	}
 }
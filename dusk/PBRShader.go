@@ -0,0 +1,244 @@
+package dusk
+
+import (
+	gl "github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// EnvironmentMapTextureUnit is the texture unit used for the environment Cubemap
+	EnvironmentMapTextureUnit = 9
+)
+
+const (
+	pbrShaderFrag = `
+#include <material.inc.glsl>
+#include <lighting.inc.glsl>
+
+uniform samplerCube uEnvironmentMap;
+uniform int uHasEnvironment;
+uniform float uEnvironmentMipLevels;
+uniform int uHDROutput;
+
+in vec4 p_Position;
+in vec4 p_Normal;
+in vec4 p_Tangent;
+in vec2 p_TexCoord;
+in float p_ViewDepth;
+in vec4 p_Color;
+
+out vec4 _Color;
+
+const float PI = 3.14159265359;
+
+// Trowbridge-Reitz GGX normal distribution
+float DistributionGGX(float NdotH, float roughness) {
+    float a = roughness * roughness;
+    float a2 = a * a;
+    float d = (NdotH * NdotH * (a2 - 1.0) + 1.0);
+    return a2 / max(PI * d * d, 0.0001);
+}
+
+// Schlick-GGX geometry term, combined for view and light with Smith's method
+float GeometrySmith(float NdotV, float NdotL, float roughness) {
+    float r = roughness + 1.0;
+    float k = (r * r) / 8.0;
+    float ggxV = NdotV / (NdotV * (1.0 - k) + k);
+    float ggxL = NdotL / (NdotL * (1.0 - k) + k);
+    return ggxV * ggxL;
+}
+
+vec3 FresnelSchlick(float cosTheta, vec3 F0) {
+    return F0 + (1.0 - F0) * pow(1.0 - cosTheta, 5.0);
+}
+
+// Analytical approximation of the pre-integrated BRDF lookup, from Karis 2014
+vec3 EnvBRDFApprox(vec3 F0, float roughness, float NdotV) {
+    const vec4 c0 = vec4(-1.0, -0.0275, -0.572, 0.022);
+    const vec4 c1 = vec4(1.0, 0.0425, 1.04, -0.04);
+    vec4 r = roughness * c0 + c1;
+    float a004 = min(r.x * r.x, exp2(-9.28 * NdotV)) * r.x + r.y;
+    vec2 AB = vec2(-1.04, 1.04) * a004 + r.zw;
+    return F0 * AB.x + AB.y;
+}
+
+void main() {
+    vec2 meshTexCoord = vec2(p_TexCoord.x, 1.0 - p_TexCoord.y);
+    vec3 N = GetMappedNormal(normalize(p_Normal.xyz), p_Tangent, p_Position.xyz, meshTexCoord, p_TexCoord);
+
+    vec4 baseColor = uBaseColor;
+    if (HasBaseColorMap()) {
+        baseColor *= texture(uBaseColorMap, p_TexCoord);
+    }
+    if (HasAlphaMap()) {
+        baseColor.a *= texture(uAlphaMap, p_TexCoord).r;
+    }
+    baseColor *= p_Color;
+    vec3 albedo = pow(baseColor.rgb, vec3(2.2));
+
+    float metallic = uMetallic;
+    if (HasMetallicMap()) {
+        metallic *= texture(uMetallicMap, p_TexCoord).b;
+    }
+
+    float roughness = uRoughness;
+    if (HasRoughnessMap()) {
+        roughness *= texture(uRoughnessMap, p_TexCoord).g;
+    }
+    roughness = clamp(roughness, 0.04, 1.0);
+
+    float ao = 1.0;
+    if (HasOcclusionMap()) {
+        ao = mix(1.0, texture(uOcclusionMap, p_TexCoord).r, uOcclusion);
+    }
+
+    vec3 emissive = uEmissive;
+    if (HasEmissiveMap()) {
+        emissive *= pow(texture(uEmissiveMap, p_TexCoord).rgb, vec3(2.2));
+    }
+
+    vec3 V = normalize(uCameraPosition - p_Position.xyz);
+    float NdotV = max(dot(N, V), 0.0001);
+
+    vec3 F0 = mix(vec3(0.04), albedo, metallic);
+
+    vec3 Lo = vec3(0.0);
+    int count = uLightCount;
+    for (int i = 0; i < count + (count == 0 ? 1 : 0); ++i) {
+        vec3 L;
+        vec3 radiance;
+        float NdotL;
+        if (count == 0) {
+            // Fallback light when none are in the scene
+            L = normalize(vec3(0.2, 1.0, 0.3));
+            radiance = vec3(1.0);
+            NdotL = max(dot(N, L), 0.0);
+        } else {
+            radiance = GetLightRadiance(uLights[i], p_Position.xyz, L);
+            NdotL = max(dot(N, L), 0.0);
+            radiance *= GetShadow(uLights[i].ShadowIndex, p_Position.xyz, p_ViewDepth, NdotL);
+        }
+
+        vec3 H = normalize(V + L);
+        float NdotH = max(dot(N, H), 0.0);
+
+        float D = DistributionGGX(NdotH, roughness);
+        float G = GeometrySmith(NdotV, NdotL, roughness);
+        vec3 F = FresnelSchlick(max(dot(H, V), 0.0), F0);
+
+        vec3 specular = (D * G * F) / max(4.0 * NdotV * NdotL, 0.0001);
+        vec3 kD = (vec3(1.0) - F) * (1.0 - metallic);
+
+        Lo += (kD * albedo / PI + specular) * radiance * NdotL;
+    }
+
+    vec3 ambient = vec3(0.03) * albedo;
+    if (uHasEnvironment != 0) {
+        vec3 kS = FresnelSchlick(NdotV, F0);
+        vec3 kD = (1.0 - kS) * (1.0 - metallic);
+
+        // The highest mip levels approximate the irradiance for diffuse lighting
+        vec3 irradiance = textureLod(uEnvironmentMap, N, uEnvironmentMipLevels - 1.0).rgb;
+
+        vec3 R = reflect(-V, N);
+        vec3 prefiltered = textureLod(uEnvironmentMap, R, roughness * (uEnvironmentMipLevels - 1.0)).rgb;
+
+        ambient = kD * irradiance * albedo + prefiltered * EnvBRDFApprox(F0, roughness, NdotV);
+    }
+    ambient *= ao;
+
+    vec3 color = ambient + Lo + emissive;
+
+    // Tone mapping and gamma correction are left to post-processing when rendering to HDR
+    if (uHDROutput == 0) {
+        color = color / (color + vec3(1.0));
+        color = pow(color, vec3(1.0 / 2.2));
+    }
+
+    _Color = vec4(color, baseColor.a);
+}
+`
+)
+
+// PBRShader is a metallic-roughness Cook-Torrance shader with image-based lighting
+type PBRShader struct {
+	Shader
+}
+
+var (
+	_pbrShader          *PBRShader
+	_pbrInstancedShader *PBRShader
+)
+
+// GetPBRShader returns an instance of the PBRShader
+func GetPBRShader() *PBRShader {
+	if _pbrShader != nil {
+		return _pbrShader
+	}
+	Loadf("Loading PBR Shader")
+	_pbrShader = &PBRShader{}
+	_pbrShader.InitFromData(
+		&ShaderData{
+			Code: defaultShaderVert,
+			Type: gl.VERTEX_SHADER,
+		},
+		&ShaderData{
+			Code: pbrShaderFrag,
+			Type: gl.FRAGMENT_SHADER,
+		},
+	)
+	return _pbrShader
+}
+
+// GetPBRInstancedShader returns an instance of the PBRShader that reads the model matrix and color from instance attributes
+func GetPBRInstancedShader() *PBRShader {
+	if _pbrInstancedShader != nil {
+		return _pbrInstancedShader
+	}
+	Loadf("Loading PBR Instanced Shader")
+	_pbrInstancedShader = &PBRShader{}
+	_pbrInstancedShader.InitFromData(
+		&ShaderData{
+			Code: withDefines(defaultShaderVert, "USE_INSTANCING"),
+			Type: gl.VERTEX_SHADER,
+		},
+		&ShaderData{
+			Code: withDefines(pbrShaderFrag, "USE_INSTANCING"),
+			Type: gl.FRAGMENT_SHADER,
+		},
+	)
+	return _pbrInstancedShader
+}
+
+// Bind implements the Shader interface
+func (s *PBRShader) Bind(ctx *RenderContext, data interface{}) {
+	s.Shader.Bind(ctx, data)
+	model := mgl32.Mat4{}
+	if data != nil {
+		model = data.(mgl32.Mat4)
+	}
+
+	gl.UniformMatrix4fv(s.UniformLocation("uProjection"), 1, false, &ctx.Projection[0])
+	gl.UniformMatrix4fv(s.UniformLocation("uView"), 1, false, &ctx.Camera.View[0])
+	s.SetModel(ctx, model)
+
+	BindLights(s, ctx)
+
+	gl.Uniform1i(s.UniformLocation("uHDROutput"), boolToInt32(ctx.HDR))
+	gl.Uniform1i(s.UniformLocation("uEnvironmentMap"), EnvironmentMapTextureUnit)
+	gl.ActiveTexture(gl.TEXTURE0 + EnvironmentMapTextureUnit)
+	if ctx.Environment != nil {
+		ctx.Environment.Bind()
+		gl.Uniform1i(s.UniformLocation("uHasEnvironment"), 1)
+		gl.Uniform1f(s.UniformLocation("uEnvironmentMipLevels"), float32(ctx.Environment.MipLevels()))
+	} else {
+		gl.BindTexture(gl.TEXTURE_CUBE_MAP, 0)
+		gl.Uniform1i(s.UniformLocation("uHasEnvironment"), 0)
+	}
+	gl.ActiveTexture(gl.TEXTURE0)
+}
+
+// SetModel implements the IModelShader interface
+func (s *PBRShader) SetModel(ctx *RenderContext, model mgl32.Mat4) {
+	gl.UniformMatrix4fv(s.UniformLocation("uModel"), 1, false, &model[0])
+}
@@ -0,0 +1,386 @@
+package dusk
+
+import (
+	gl "github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	toneMapShaderFrag = `
+#include <post.inc.glsl>
+
+uniform float uExposure;
+uniform int uOperator;
+
+// Filmic curve fit from Krzysztof Narkowicz
+vec3 ACES(vec3 x) {
+    return clamp((x * (2.51 * x + 0.03)) / (x * (2.43 * x + 0.59) + 0.14), 0.0, 1.0);
+}
+
+void main() {
+    vec3 color = texture(uTexture, p_TexCoord).rgb * uExposure;
+    if (uOperator == 1) {
+        color = ACES(color);
+    } else {
+        color = color / (color + vec3(1.0));
+    }
+    _Color = vec4(color, 1.0);
+}
+`
+
+	gammaShaderFrag = `
+#include <post.inc.glsl>
+
+uniform float uGamma;
+
+void main() {
+    vec3 color = texture(uTexture, p_TexCoord).rgb;
+    _Color = vec4(pow(color, vec3(1.0 / uGamma)), 1.0);
+}
+`
+
+	bloomBrightShaderFrag = `
+#include <post.inc.glsl>
+
+uniform float uThreshold;
+
+void main() {
+    vec3 color = texture(uTexture, p_TexCoord).rgb;
+    float brightness = max(color.r, max(color.g, color.b));
+    float contribution = max(brightness - uThreshold, 0.0) / max(brightness, 0.0001);
+    _Color = vec4(color * contribution, 1.0);
+}
+`
+
+	bloomBlurShaderFrag = `
+#include <post.inc.glsl>
+
+uniform vec2 uDirection;
+
+const float weights[5] = float[](0.227027, 0.1945946, 0.1216216, 0.054054, 0.016216);
+
+void main() {
+    vec2 offset = uDirection * uTexelSize;
+    vec3 color = texture(uTexture, p_TexCoord).rgb * weights[0];
+    for (int i = 1; i < 5; ++i) {
+        color += texture(uTexture, p_TexCoord + offset * float(i)).rgb * weights[i];
+        color += texture(uTexture, p_TexCoord - offset * float(i)).rgb * weights[i];
+    }
+    _Color = vec4(color, 1.0);
+}
+`
+
+	bloomCompositeShaderFrag = `
+#include <post.inc.glsl>
+
+uniform sampler2D uBloom;
+uniform float uIntensity;
+
+void main() {
+    vec3 color = texture(uTexture, p_TexCoord).rgb;
+    color += texture(uBloom, p_TexCoord).rgb * uIntensity;
+    _Color = vec4(color, 1.0);
+}
+`
+
+	fxaaShaderFrag = `
+#include <post.inc.glsl>
+
+const float reduceMin = 1.0 / 128.0;
+const float reduceMul = 1.0 / 8.0;
+const float spanMax = 8.0;
+
+void main() {
+    const vec3 luma = vec3(0.299, 0.587, 0.114);
+
+    vec3 rgbNW = texture(uTexture, p_TexCoord + vec2(-1.0, -1.0) * uTexelSize).rgb;
+    vec3 rgbNE = texture(uTexture, p_TexCoord + vec2( 1.0, -1.0) * uTexelSize).rgb;
+    vec3 rgbSW = texture(uTexture, p_TexCoord + vec2(-1.0,  1.0) * uTexelSize).rgb;
+    vec3 rgbSE = texture(uTexture, p_TexCoord + vec2( 1.0,  1.0) * uTexelSize).rgb;
+    vec3 rgbM  = texture(uTexture, p_TexCoord).rgb;
+
+    float lumaNW = dot(rgbNW, luma);
+    float lumaNE = dot(rgbNE, luma);
+    float lumaSW = dot(rgbSW, luma);
+    float lumaSE = dot(rgbSE, luma);
+    float lumaM  = dot(rgbM,  luma);
+
+    float lumaMin = min(lumaM, min(min(lumaNW, lumaNE), min(lumaSW, lumaSE)));
+    float lumaMax = max(lumaM, max(max(lumaNW, lumaNE), max(lumaSW, lumaSE)));
+
+    vec2 dir = vec2(
+        -((lumaNW + lumaNE) - (lumaSW + lumaSE)),
+         ((lumaNW + lumaSW) - (lumaNE + lumaSE)));
+
+    float dirReduce = max((lumaNW + lumaNE + lumaSW + lumaSE) * (0.25 * reduceMul), reduceMin);
+    float rcpDirMin = 1.0 / (min(abs(dir.x), abs(dir.y)) + dirReduce);
+    dir = clamp(dir * rcpDirMin, vec2(-spanMax), vec2(spanMax)) * uTexelSize;
+
+    vec3 rgbA = 0.5 * (
+        texture(uTexture, p_TexCoord + dir * (1.0 / 3.0 - 0.5)).rgb +
+        texture(uTexture, p_TexCoord + dir * (2.0 / 3.0 - 0.5)).rgb);
+    vec3 rgbB = rgbA * 0.5 + 0.25 * (
+        texture(uTexture, p_TexCoord + dir * -0.5).rgb +
+        texture(uTexture, p_TexCoord + dir * 0.5).rgb);
+
+    float lumaB = dot(rgbB, luma);
+    if (lumaB < lumaMin || lumaB > lumaMax) {
+        _Color = vec4(rgbA, 1.0);
+    } else {
+        _Color = vec4(rgbB, 1.0);
+    }
+}
+`
+
+	vignetteShaderFrag = `
+#include <post.inc.glsl>
+
+uniform float uIntensity;
+uniform float uRadius;
+uniform float uSoftness;
+uniform vec3 uVignetteColor;
+
+void main() {
+    vec3 color = texture(uTexture, p_TexCoord).rgb;
+    float dist = distance(p_TexCoord, vec2(0.5));
+    // Without softness the vignette has a hard edge, as smoothstep needs edge0 < edge1
+    float vignette = 1.0 - step(uRadius, dist);
+    if (uSoftness > 0.0) {
+        vignette = 1.0 - smoothstep(uRadius - uSoftness, uRadius, dist);
+    }
+    _Color = vec4(mix(uVignetteColor, color, mix(1.0, vignette, uIntensity)), 1.0);
+}
+`
+)
+
+// ToneMapOperator is the curve used to map HDR colors into [0, 1]
+type ToneMapOperator int
+
+const (
+	// ToneMapReinhard is the simple Reinhard operator, color / (color + 1)
+	ToneMapReinhard ToneMapOperator = iota
+	// ToneMapACES is a fit of the ACES filmic curve
+	ToneMapACES
+)
+
+// ToneMapEffect maps the HDR scene into displayable colors, it should come before any LDR effects
+type ToneMapEffect struct {
+	Exposure float32
+	Operator ToneMapOperator
+}
+
+// NewToneMapEffect returns a new ToneMapEffect with the given operator
+func NewToneMapEffect(op ToneMapOperator) *ToneMapEffect {
+	return &ToneMapEffect{
+		Exposure: 1.0,
+		Operator: op,
+	}
+}
+
+// Delete implements the IPostEffect interface
+func (e *ToneMapEffect) Delete() {}
+
+// Apply implements the IPostEffect interface
+func (e *ToneMapEffect) Apply(pp *PostProcessor, src *Texture, dst *RenderTarget) {
+	s := getPostShader("Tone Map", toneMapShaderFrag)
+	pp.Draw(s, src, dst, func(s IShader) {
+		gl.Uniform1f(s.UniformLocation("uExposure"), e.Exposure)
+		gl.Uniform1i(s.UniformLocation("uOperator"), int32(e.Operator))
+	})
+}
+
+// GammaEffect converts linear colors to gamma space, usually after a ToneMapEffect
+type GammaEffect struct {
+	Gamma float32
+}
+
+// NewGammaEffect returns a new GammaEffect with a gamma of 2.2
+func NewGammaEffect() *GammaEffect {
+	return &GammaEffect{
+		Gamma: 2.2,
+	}
+}
+
+// Delete implements the IPostEffect interface
+func (e *GammaEffect) Delete() {}
+
+// Apply implements the IPostEffect interface
+func (e *GammaEffect) Apply(pp *PostProcessor, src *Texture, dst *RenderTarget) {
+	s := getPostShader("Gamma", gammaShaderFrag)
+	pp.Draw(s, src, dst, func(s IShader) {
+		gl.Uniform1f(s.UniformLocation("uGamma"), e.Gamma)
+	})
+}
+
+// BloomEffect blurs the brightest parts of the scene and adds them back, it should come before a ToneMapEffect
+type BloomEffect struct {
+	// Threshold is the brightness above which colors bloom
+	Threshold float32
+	// Intensity is the strength of the bloom added to the scene
+	Intensity float32
+	// Iterations is the number of blur passes, more gives a wider bloom
+	Iterations int
+
+	targets [2]*RenderTarget
+}
+
+// NewBloomEffect returns a new BloomEffect with default settings
+func NewBloomEffect() *BloomEffect {
+	return &BloomEffect{
+		Threshold:  1.0,
+		Intensity:  0.5,
+		Iterations: 5,
+	}
+}
+
+// Delete implements the IPostEffect interface
+func (e *BloomEffect) Delete() {
+	for i := range e.targets {
+		if e.targets[i] != nil {
+			e.targets[i].Delete()
+			e.targets[i] = nil
+		}
+	}
+}
+
+// Apply implements the IPostEffect interface
+func (e *BloomEffect) Apply(pp *PostProcessor, src *Texture, dst *RenderTarget) {
+	// Blur at half resolution
+	width := int(src.Size[0]) / 2
+	height := int(src.Size[1]) / 2
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	if e.targets[0] == nil || e.targets[0].Width != width || e.targets[0].Height != height {
+		e.Delete()
+		for i := range e.targets {
+			var err error
+			e.targets[i], err = NewRenderTarget(width, height, &RenderTargetOptions{
+				ColorFormats: []uint32{gl.RGBA16F},
+			})
+			if err != nil {
+				Errorf("%v", err)
+				e.Delete()
+				return
+			}
+		}
+	}
+
+	bright := getPostShader("Bloom Bright", bloomBrightShaderFrag)
+	pp.Draw(bright, src, e.targets[0], func(s IShader) {
+		gl.Uniform1f(s.UniformLocation("uThreshold"), e.Threshold)
+	})
+
+	blur := getPostShader("Bloom Blur", bloomBlurShaderFrag)
+	horizontal := mgl32.Vec2{1, 0}
+	vertical := mgl32.Vec2{0, 1}
+	for i := 0; i < e.Iterations; i++ {
+		pp.Draw(blur, e.targets[0].GetColor(0), e.targets[1], func(s IShader) {
+			gl.Uniform2fv(s.UniformLocation("uDirection"), 1, &horizontal[0])
+		})
+		pp.Draw(blur, e.targets[1].GetColor(0), e.targets[0], func(s IShader) {
+			gl.Uniform2fv(s.UniformLocation("uDirection"), 1, &vertical[0])
+		})
+	}
+
+	composite := getPostShader("Bloom Composite", bloomCompositeShaderFrag)
+	pp.Draw(composite, src, dst, func(s IShader) {
+		gl.Uniform1f(s.UniformLocation("uIntensity"), e.Intensity)
+		gl.Uniform1i(s.UniformLocation("uBloom"), 1)
+		gl.ActiveTexture(gl.TEXTURE1)
+		e.targets[0].GetColor(0).Bind()
+		gl.ActiveTexture(gl.TEXTURE0)
+	})
+
+	gl.ActiveTexture(gl.TEXTURE1)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+	gl.ActiveTexture(gl.TEXTURE0)
+}
+
+// FXAAEffect smooths jagged edges, it works best on gamma corrected colors at the end of the chain
+type FXAAEffect struct{}
+
+// NewFXAAEffect returns a new FXAAEffect
+func NewFXAAEffect() *FXAAEffect {
+	return &FXAAEffect{}
+}
+
+// Delete implements the IPostEffect interface
+func (e *FXAAEffect) Delete() {}
+
+// Apply implements the IPostEffect interface
+func (e *FXAAEffect) Apply(pp *PostProcessor, src *Texture, dst *RenderTarget) {
+	s := getPostShader("FXAA", fxaaShaderFrag)
+	pp.Draw(s, src, dst, nil)
+}
+
+// VignetteEffect darkens the edges of the screen
+type VignetteEffect struct {
+	// Intensity is how much of the vignette is applied, from 0.0 to 1.0
+	Intensity float32
+	// Radius is the distance from the center, in texture coordinates, where the vignette ends
+	Radius float32
+	// Softness is the width of the fade into the vignette, or 0 for a hard edge
+	Softness float32
+	// Color is the color at the edges of the screen
+	Color mgl32.Vec3
+}
+
+// NewVignetteEffect returns a new VignetteEffect with default settings
+func NewVignetteEffect() *VignetteEffect {
+	return &VignetteEffect{
+		Intensity: 1.0,
+		Radius:    0.75,
+		Softness:  0.45,
+		Color:     mgl32.Vec3{0, 0, 0},
+	}
+}
+
+// Delete implements the IPostEffect interface
+func (e *VignetteEffect) Delete() {}
+
+// Apply implements the IPostEffect interface
+func (e *VignetteEffect) Apply(pp *PostProcessor, src *Texture, dst *RenderTarget) {
+	s := getPostShader("Vignette", vignetteShaderFrag)
+	pp.Draw(s, src, dst, func(s IShader) {
+		gl.Uniform1f(s.UniformLocation("uIntensity"), e.Intensity)
+		gl.Uniform1f(s.UniformLocation("uRadius"), e.Radius)
+		gl.Uniform1f(s.UniformLocation("uSoftness"), e.Softness)
+		gl.Uniform3fv(s.UniformLocation("uVignetteColor"), 1, &e.Color[0])
+	})
+}
+
+// ShaderEffect runs a user supplied PostShader
+type ShaderEffect struct {
+	Shader IShader
+	// Uniforms is called after the Shader is bound, to set any custom uniforms
+	Uniforms func(IShader)
+}
+
+// NewShaderEffect returns a new ShaderEffect from fragment shader code, which should #include <post.inc.glsl>
+func NewShaderEffect(frag string, uniforms func(IShader)) *ShaderEffect {
+	return &ShaderEffect{
+		Shader:   NewPostShader(frag),
+		Uniforms: uniforms,
+	}
+}
+
+// Delete implements the IPostEffect interface
+func (e *ShaderEffect) Delete() {
+	if e.Shader != nil {
+		e.Shader.Delete()
+		e.Shader = nil
+	}
+}
+
+// Apply implements the IPostEffect interface
+func (e *ShaderEffect) Apply(pp *PostProcessor, src *Texture, dst *RenderTarget) {
+	if e.Shader == nil {
+		return
+	}
+	pp.Draw(e.Shader, src, dst, e.Uniforms)
+}
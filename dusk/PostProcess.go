@@ -0,0 +1,238 @@
+package dusk
+
+import (
+	gl "github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	postShaderVert = `
+out vec2 p_TexCoord;
+
+void main() {
+    // Full screen triangle generated from the vertex ID, no buffers needed
+    p_TexCoord = vec2((gl_VertexID << 1) & 2, gl_VertexID & 2);
+    gl_Position = vec4(p_TexCoord * 2.0 - 1.0, 0.0, 1.0);
+}
+`
+)
+
+// IPostEffect is a full screen pass in a PostProcessor's chain
+type IPostEffect interface {
+	// Apply renders src into dst, or into the current framebuffer if dst is nil
+	Apply(pp *PostProcessor, src *Texture, dst *RenderTarget)
+	Delete()
+}
+
+// PostShader is a full screen shader used by IPostEffects, fragment code should #include <post.inc.glsl>
+type PostShader struct {
+	Shader
+}
+
+// NewPostShader returns a new PostShader from the given fragment shader code
+func NewPostShader(frag string) *PostShader {
+	s := &PostShader{}
+	s.InitFromData(
+		&ShaderData{
+			Code: postShaderVert,
+			Type: gl.VERTEX_SHADER,
+		},
+		&ShaderData{
+			Code: frag,
+			Type: gl.FRAGMENT_SHADER,
+		},
+	)
+	return s
+}
+
+var _postShaders = map[string]*PostShader{}
+
+// getPostShader returns a cached instance of a built-in PostShader
+func getPostShader(name, frag string) *PostShader {
+	if s, found := _postShaders[name]; found {
+		return s
+	}
+	Loadf("Loading %v Shader", name)
+	s := NewPostShader(frag)
+	_postShaders[name] = s
+	return s
+}
+
+// Bind implements the Shader interface, data is the *Texture to bind to uTexture
+func (s *PostShader) Bind(ctx *RenderContext, data interface{}) {
+	s.Shader.Bind(ctx, data)
+
+	gl.Uniform1i(s.UniformLocation("uTexture"), 0)
+	gl.ActiveTexture(gl.TEXTURE0)
+	if t, ok := data.(*Texture); ok && t != nil {
+		t.Bind()
+		texelSize := mgl32.Vec2{1.0 / t.Size[0], 1.0 / t.Size[1]}
+		gl.Uniform2fv(s.UniformLocation("uTexelSize"), 1, &texelSize[0])
+	}
+}
+
+// PostProcessor renders the scene into an HDR RenderTarget, and then runs it through a chain of IPostEffects
+type PostProcessor struct {
+	// Samples is the number of MSAA samples used for the scene, it takes effect the next time the scene is resized
+	Samples int
+
+	effects []IPostEffect
+
+	scene   *RenderTarget
+	targets [2]*RenderTarget
+
+	vao uint32
+}
+
+// NewPostProcessor returns a new PostProcessor with no effects
+func NewPostProcessor(samples int) *PostProcessor {
+	pp := &PostProcessor{
+		Samples: samples,
+		effects: []IPostEffect{},
+	}
+	gl.GenVertexArrays(1, &pp.vao)
+	return pp
+}
+
+// Delete frees all resources owned by the PostProcessor and its effects
+func (pp *PostProcessor) Delete() {
+	for _, e := range pp.effects {
+		e.Delete()
+	}
+	pp.effects = []IPostEffect{}
+
+	pp.deleteTargets()
+
+	if pp.vao != InvalidID {
+		gl.DeleteVertexArrays(1, &pp.vao)
+		pp.vao = InvalidID
+	}
+}
+
+func (pp *PostProcessor) deleteTargets() {
+	if pp.scene != nil {
+		pp.scene.Delete()
+		pp.scene = nil
+	}
+	for i := range pp.targets {
+		if pp.targets[i] != nil {
+			pp.targets[i].Delete()
+			pp.targets[i] = nil
+		}
+	}
+}
+
+// AddEffect adds an effect to the end of the chain
+func (pp *PostProcessor) AddEffect(effect IPostEffect) {
+	pp.effects = append(pp.effects, effect)
+}
+
+// RemoveEffect removes an effect from the chain, it is not deleted
+func (pp *PostProcessor) RemoveEffect(effect IPostEffect) {
+	for i := 0; i < len(pp.effects); i++ {
+		if pp.effects[i] == effect {
+			pp.effects = append(pp.effects[:i], pp.effects[i+1:]...)
+		}
+	}
+}
+
+// GetEffects returns the chain of effects
+func (pp *PostProcessor) GetEffects() []IPostEffect {
+	return pp.effects
+}
+
+// GetSceneTarget returns the HDR RenderTarget the scene is drawn into, or nil before the first Begin()
+func (pp *PostProcessor) GetSceneTarget() *RenderTarget {
+	return pp.scene
+}
+
+// Begin binds the scene RenderTarget, resizing it if needed
+func (pp *PostProcessor) Begin(width, height int) error {
+	if pp.scene == nil || pp.scene.Width != width || pp.scene.Height != height {
+		pp.deleteTargets()
+
+		var err error
+		pp.scene, err = NewRenderTarget(width, height, &RenderTargetOptions{
+			ColorFormats: []uint32{gl.RGBA16F},
+			Depth:        true,
+			Samples:      pp.Samples,
+		})
+		if err != nil {
+			return err
+		}
+
+		for i := range pp.targets {
+			pp.targets[i], err = NewRenderTarget(width, height, &RenderTargetOptions{
+				ColorFormats: []uint32{gl.RGBA16F},
+			})
+			if err != nil {
+				pp.deleteTargets()
+				return err
+			}
+		}
+	}
+
+	pp.scene.Bind()
+	return nil
+}
+
+// End unbinds the scene RenderTarget and applies every effect, the last one draws to the previously bound framebuffer
+func (pp *PostProcessor) End() {
+	pp.scene.UnBind()
+
+	depthTest := gl.IsEnabled(gl.DEPTH_TEST)
+	blend := gl.IsEnabled(gl.BLEND)
+	gl.Disable(gl.DEPTH_TEST)
+	gl.Disable(gl.BLEND)
+
+	src := pp.scene.GetColor(0)
+	for i, e := range pp.effects {
+		var dst *RenderTarget
+		if i < len(pp.effects)-1 {
+			dst = pp.targets[i%2]
+		}
+		e.Apply(pp, src, dst)
+		if dst != nil {
+			src = dst.GetColor(0)
+		}
+	}
+
+	if depthTest {
+		gl.Enable(gl.DEPTH_TEST)
+	}
+	if blend {
+		gl.Enable(gl.BLEND)
+	}
+}
+
+// BindOutput binds dst to be drawn into, or does nothing if it is nil
+func (pp *PostProcessor) BindOutput(dst *RenderTarget) {
+	if dst != nil {
+		dst.Bind()
+	}
+}
+
+// UnBindOutput unbinds dst, or does nothing if it is nil
+func (pp *PostProcessor) UnBindOutput(dst *RenderTarget) {
+	if dst != nil {
+		dst.UnBind()
+	}
+}
+
+// DrawQuad draws a full screen triangle with the currently bound shader
+func (pp *PostProcessor) DrawQuad() {
+	gl.BindVertexArray(pp.vao)
+	gl.DrawArrays(gl.TRIANGLES, 0, 3)
+	gl.BindVertexArray(0)
+}
+
+// Draw binds the shader with src, calls uniforms if it is not nil, and draws into dst
+func (pp *PostProcessor) Draw(s IShader, src *Texture, dst *RenderTarget, uniforms func(IShader)) {
+	pp.BindOutput(dst)
+	s.Bind(nil, src)
+	if uniforms != nil {
+		uniforms(s)
+	}
+	pp.DrawQuad()
+	pp.UnBindOutput(dst)
+}
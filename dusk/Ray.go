@@ -0,0 +1,259 @@
+package dusk
+
+import (
+	"github.com/WhoBrokeTheBuild/GoDusk/m32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Ray is a half-line starting at Origin, NewRay normalizes the Direction
+type Ray struct {
+	Origin    mgl32.Vec3
+	Direction mgl32.Vec3
+}
+
+// NewRay returns a new Ray, normalizing the direction
+func NewRay(origin, direction mgl32.Vec3) Ray {
+	return Ray{
+		Origin:    origin,
+		Direction: direction.Normalize(),
+	}
+}
+
+// At returns the point at distance t along the Ray
+func (r Ray) At(t float32) mgl32.Vec3 {
+	return r.Origin.Add(r.Direction.Mul(t))
+}
+
+// Transform returns the Ray transformed by m, the Direction is not normalized so distances are preserved through the transform
+func (r Ray) Transform(m mgl32.Mat4) Ray {
+	return Ray{
+		Origin:    mgl32.TransformCoordinate(r.Origin, m),
+		Direction: mgl32.TransformNormal(r.Direction, m),
+	}
+}
+
+// IntersectSphere returns the distance to the first intersection with the sphere, and whether there is one
+func (r Ray) IntersectSphere(center mgl32.Vec3, radius float32) (float32, bool) {
+	oc := r.Origin.Sub(center)
+	a := r.Direction.Dot(r.Direction)
+	b := oc.Dot(r.Direction)
+	c := oc.Dot(oc) - radius*radius
+
+	disc := b*b - a*c
+	if disc < 0 || a == 0 {
+		return 0, false
+	}
+
+	sq := m32.Sqrt(disc)
+	t := (-b - sq) / a
+	if t < 0 {
+		// The Ray starts inside the sphere
+		t = (-b + sq) / a
+	}
+	if t < 0 {
+		return 0, false
+	}
+	return t, true
+}
+
+// IntersectBox returns the distance to the first intersection with the axis-aligned box, and whether there is one
+// A Ray starting inside the box hits it at a distance of 0
+func (r Ray) IntersectBox(min, max mgl32.Vec3) (float32, bool) {
+	tmin := float32(0)
+	tmax := m32.Inf(1)
+
+	// Slab method, the intersection of the Ray with each pair of planes
+	for i := 0; i < 3; i++ {
+		if r.Direction[i] == 0 {
+			if r.Origin[i] < min[i] || r.Origin[i] > max[i] {
+				return 0, false
+			}
+			continue
+		}
+
+		inv := 1.0 / r.Direction[i]
+		t1 := (min[i] - r.Origin[i]) * inv
+		t2 := (max[i] - r.Origin[i]) * inv
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		tmin = m32.Max(tmin, t1)
+		tmax = m32.Min(tmax, t2)
+		if tmin > tmax {
+			return 0, false
+		}
+	}
+	return tmin, true
+}
+
+// IntersectBounds returns the distance to the first intersection with the box of the Bounds, and whether there is one
+func (r Ray) IntersectBounds(b Bounds) (float32, bool) {
+	if _, ok := r.IntersectSphere(b.Center, b.Radius); !ok {
+		return 0, false
+	}
+	return r.IntersectBox(b.Min, b.Max)
+}
+
+// IntersectTriangle returns the distance to the intersection with the triangle, and whether there is one
+// Both sides of the triangle are hit
+func (r Ray) IntersectTriangle(a, b, c mgl32.Vec3) (float32, bool) {
+	const epsilon = 1e-7
+
+	// Möller–Trumbore intersection
+	e1 := b.Sub(a)
+	e2 := c.Sub(a)
+	p := r.Direction.Cross(e2)
+	det := e1.Dot(p)
+	if m32.Abs(det) < epsilon {
+		return 0, false
+	}
+
+	inv := 1.0 / det
+	s := r.Origin.Sub(a)
+	u := s.Dot(p) * inv
+	if u < 0 || u > 1 {
+		return 0, false
+	}
+
+	q := s.Cross(e1)
+	v := r.Direction.Dot(q) * inv
+	if v < 0 || u+v > 1 {
+		return 0, false
+	}
+
+	t := e2.Dot(q) * inv
+	if t < 0 {
+		return 0, false
+	}
+	return t, true
+}
+
+// RaycastHit describes where a Ray hit an Entity
+type RaycastHit struct {
+	Entity   IEntity
+	Distance float32
+	Point    mgl32.Vec3
+	Normal   mgl32.Vec3
+}
+
+// IRaycaster is a Component that can be hit by a Ray
+type IRaycaster interface {
+	IComponent
+
+	Raycast(Ray) (RaycastHit, bool)
+}
+
+// Raycast returns the closest triangle of the Mesh hit by a Ray in the Mesh's space
+func (m *Mesh) Raycast(r Ray) (t float32, normal mgl32.Vec3, hit bool) {
+	if _, ok := r.IntersectBounds(m.bounds); !ok && !m.bounds.IsZero() {
+		return
+	}
+
+	for i := 0; i+2 < len(m.vertices); i += 3 {
+		a, b, c := m.vertices[i], m.vertices[i+1], m.vertices[i+2]
+		d, ok := r.IntersectTriangle(a, b, c)
+		if ok && (!hit || d < t) {
+			t = d
+			normal = b.Sub(a).Cross(c.Sub(a))
+			hit = true
+		}
+	}
+
+	// Face the normal towards the Ray
+	if hit && normal.Dot(r.Direction) > 0 {
+		normal = normal.Mul(-1)
+	}
+	return
+}
+
+// raycastMeshes returns the closest hit of a world space Ray against the Meshes transformed by matrix
+func raycastMeshes(r Ray, meshes map[string]*Mesh, bounds Bounds, matrix mgl32.Mat4) (RaycastHit, bool) {
+	inv := matrix.Inv()
+	local := r.Transform(inv)
+	if _, ok := local.IntersectBounds(bounds); !ok && !bounds.IsZero() {
+		return RaycastHit{}, false
+	}
+
+	var (
+		best   RaycastHit
+		normal mgl32.Vec3
+		found  bool
+	)
+	for _, mesh := range meshes {
+		t, n, ok := mesh.Raycast(local)
+		if !ok {
+			continue
+		}
+
+		// The local Ray's direction is scaled, so the distance is measured again in world space
+		point := mgl32.TransformCoordinate(local.At(t), matrix)
+		dist := point.Sub(r.Origin).Len()
+		if !found || dist < best.Distance {
+			best = RaycastHit{Distance: dist, Point: point}
+			normal = n
+			found = true
+		}
+	}
+	if !found {
+		return RaycastHit{}, false
+	}
+
+	// Normals are transformed by the inverse transpose, to handle non-uniform scale
+	best.Normal = mgl32.TransformNormal(normal, inv.Transpose()).Normalize()
+	return best, true
+}
+
+// Raycast implements the IRaycaster interface, testing against the triangles of every Mesh
+func (m *Model) Raycast(r Ray) (RaycastHit, bool) {
+	if m.GetEntity() == nil || m.GetEntity().Transform() == nil {
+		return RaycastHit{}, false
+	}
+
+	hit, ok := raycastMeshes(r, m.meshes, m.bounds, m.GetEntity().Transform().GetWorldMatrix())
+	hit.Entity = m.GetEntity()
+	return hit, ok
+}
+
+// Raycast implements the IRaycaster interface, the hit Entity is the closest instance rather than the InstancedModel's own
+func (m *InstancedModel) Raycast(r Ray) (RaycastHit, bool) {
+	var (
+		best  RaycastHit
+		found bool
+	)
+	for _, i := range m.instances {
+		if i.Entity.Transform() == nil {
+			continue
+		}
+
+		hit, ok := raycastMeshes(r, m.meshes, m.bounds, i.Entity.Transform().GetWorldMatrix())
+		if ok && (!found || hit.Distance < best.Distance) {
+			best = hit
+			best.Entity = i.Entity
+			found = true
+		}
+	}
+	return best, found
+}
+
+// Raycast returns the closest hit of a world space Ray against every IRaycaster Component in the Layer
+func (s *Layer) Raycast(r Ray) (RaycastHit, bool) {
+	var (
+		best  RaycastHit
+		found bool
+	)
+	for _, e := range s.entities {
+		for _, c := range e.GetComponents() {
+			rc, ok := c.(IRaycaster)
+			if !ok {
+				continue
+			}
+
+			hit, ok := rc.Raycast(r)
+			if ok && (!found || hit.Distance < best.Distance) {
+				best = hit
+				found = true
+			}
+		}
+	}
+	return best, found
+}
@@ -2,8 +2,43 @@ package dusk
 
 import "github.com/go-gl/mathgl/mgl32"
 
+// RenderPass identifies what is being rendered
+type RenderPass int
+
+const (
+	// ColorPass is the normal pass that draws to the screen
+	ColorPass RenderPass = iota
+	// ShadowPass only draws depth from the point of view of a Light
+	ShadowPass
+)
+
 // RenderContext is a context of view and shader data
 type RenderContext struct {
 	Projection mgl32.Mat4
 	Camera     *Camera
+	Pass       RenderPass
+	Lights     []*Light
+
+	// Frustum is used to skip objects outside of the view, if nil nothing is culled
+	Frustum *Frustum
+
+	// Environment is the Cubemap used for image-based lighting, may be nil
+	Environment *Cubemap
+
+	// Queue collects Meshes to be drawn sorted when the Layer is done rendering, if nil they are drawn immediately
+	Queue *RenderQueue
+
+	// Sprites collects Sprites to be drawn when the Layer is done rendering, if nil they are drawn immediately
+	Sprites *SpriteBatch
+
+	// HDR is true when rendering into a linear HDR target for post-processing
+	HDR bool
+}
+
+// IsVisible returns whether any part of the world space Bounds are inside the Frustum
+func (ctx *RenderContext) IsVisible(b Bounds) bool {
+	if ctx.Frustum == nil || b.IsZero() {
+		return true
+	}
+	return ctx.Frustum.ContainsBounds(b)
 }
@@ -0,0 +1,177 @@
+package dusk
+
+import (
+	"sort"
+
+	gl "github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// IModelShader is a Shader that can change the model matrix without rebinding all of its uniforms
+type IModelShader interface {
+	IShader
+
+	SetModel(*RenderContext, mgl32.Mat4)
+}
+
+// DrawItem is a single Mesh submitted to a RenderQueue
+type DrawItem struct {
+	Mesh     *Mesh
+	Material *Material
+	Shader   IShader
+	Matrix   mgl32.Mat4
+
+	// Depth is the view space distance from the camera, used for sorting
+	Depth float32
+
+	ReceiveShadows bool
+
+	// Bones are the matrices used to skin the Mesh, or nil if it is not skinned
+	Bones []mgl32.Mat4
+
+	transparent bool
+	materialKey int
+}
+
+// RenderStats counts the work done while rendering a frame
+type RenderStats struct {
+	DrawCalls     int
+	ShaderBinds   int
+	MaterialBinds int
+	Opaque        int
+	Transparent   int
+	// Culled is the number of Meshes skipped because they were outside the Frustum
+	Culled int
+}
+
+// RenderQueue collects DrawItems and draws them sorted to minimize state changes
+// Opaque items are grouped by Shader and Material and drawn front to back, then transparent items are drawn back to front
+type RenderQueue struct {
+	Stats RenderStats
+
+	opaque      []DrawItem
+	transparent []DrawItem
+
+	materialKeys map[*Material]int
+}
+
+// NewRenderQueue returns a new, empty RenderQueue
+func NewRenderQueue() *RenderQueue {
+	return &RenderQueue{
+		opaque:       []DrawItem{},
+		transparent:  []DrawItem{},
+		materialKeys: map[*Material]int{},
+	}
+}
+
+// Submit adds a DrawItem to the queue
+func (q *RenderQueue) Submit(item DrawItem) {
+	if item.Mesh == nil || item.Shader == nil {
+		return
+	}
+
+	// Materials are sorted in the order they are first seen
+	key, found := q.materialKeys[item.Material]
+	if !found {
+		key = len(q.materialKeys)
+		q.materialKeys[item.Material] = key
+	}
+	item.materialKey = key
+
+	if item.Material != nil && item.Material.IsTransparent() {
+		item.transparent = true
+		q.transparent = append(q.transparent, item)
+	} else {
+		q.opaque = append(q.opaque, item)
+	}
+}
+
+// Clear removes all DrawItems from the queue
+func (q *RenderQueue) Clear() {
+	q.opaque = q.opaque[:0]
+	q.transparent = q.transparent[:0]
+	for k := range q.materialKeys {
+		delete(q.materialKeys, k)
+	}
+}
+
+// ResetStats sets all RenderStats back to zero
+func (q *RenderQueue) ResetStats() {
+	q.Stats = RenderStats{}
+}
+
+// Flush sorts and draws all DrawItems, then clears the queue
+func (q *RenderQueue) Flush(ctx *RenderContext) {
+	sort.Slice(q.opaque, func(i, j int) bool {
+		a, b := &q.opaque[i], &q.opaque[j]
+		if a.Shader.ID() != b.Shader.ID() {
+			return a.Shader.ID() < b.Shader.ID()
+		}
+		if a.materialKey != b.materialKey {
+			return a.materialKey < b.materialKey
+		}
+		return a.Depth < b.Depth
+	})
+
+	sort.Slice(q.transparent, func(i, j int) bool {
+		return q.transparent[i].Depth > q.transparent[j].Depth
+	})
+
+	q.Stats.Opaque += len(q.opaque)
+	q.Stats.Transparent += len(q.transparent)
+
+	q.draw(ctx, q.opaque)
+
+	if len(q.transparent) > 0 {
+		gl.DepthMask(false)
+		q.draw(ctx, q.transparent)
+		gl.DepthMask(true)
+	}
+
+	q.Clear()
+}
+
+func (q *RenderQueue) draw(ctx *RenderContext, items []DrawItem) {
+	var (
+		shader   IShader
+		material *Material
+	)
+
+	for i := range items {
+		item := &items[i]
+
+		shaderChanged := (item.Shader != shader)
+		if shaderChanged {
+			shader = item.Shader
+			shader.Bind(ctx, item.Matrix)
+			q.Stats.ShaderBinds++
+		} else if ms, ok := shader.(IModelShader); ok {
+			ms.SetModel(ctx, item.Matrix)
+		} else {
+			shader.Bind(ctx, item.Matrix)
+		}
+
+		// Uniforms are stored per program, so a new Shader needs the Material bound again
+		if shaderChanged || item.Material != material {
+			if material != nil && item.Material == nil {
+				material.UnBind()
+				gl.Uniform1ui(shader.UniformLocation("uMapFlags"), 0)
+			}
+			material = item.Material
+			if material != nil {
+				material.Bind(shader)
+				q.Stats.MaterialBinds++
+			}
+		}
+
+		gl.Uniform1i(shader.UniformLocation("uReceiveShadows"), boolToInt32(item.ReceiveShadows))
+		setBoneUniforms(shader, item.Bones)
+
+		item.Mesh.Draw()
+		q.Stats.DrawCalls++
+	}
+
+	if material != nil {
+		material.UnBind()
+	}
+}
@@ -0,0 +1,279 @@
+package dusk
+
+import (
+	"fmt"
+
+	gl "github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// RenderTargetOptions is used to create a new RenderTarget
+type RenderTargetOptions struct {
+	// ColorFormats is the internal format of each color attachment, e.g. gl.RGBA8 or gl.RGBA16F
+	ColorFormats []uint32
+	// Depth adds a depth attachment
+	Depth bool
+	// Samples is the number of MSAA samples, values above 1 render to multisample buffers that are resolved into the color textures
+	Samples int
+	// Filter is the min/mag filter of the color textures, e.g. gl.LINEAR
+	Filter int32
+}
+
+// DefaultRenderTargetOptions returns the default values for RenderTargetOptions
+func DefaultRenderTargetOptions() *RenderTargetOptions {
+	return &RenderTargetOptions{
+		ColorFormats: []uint32{gl.RGBA8},
+		Depth:        true,
+		Samples:      0,
+		Filter:       gl.LINEAR,
+	}
+}
+
+// RenderTarget represents an OpenGL Framebuffer with color textures and an optional depth buffer
+type RenderTarget struct {
+	Width  int
+	Height int
+
+	// Colors are the color attachments, resolved if the RenderTarget is multisampled
+	Colors []*Texture
+
+	opts RenderTargetOptions
+
+	frameID uint32
+	depthID uint32
+
+	msFrameID  uint32
+	msColorIDs []uint32
+	msDepthID  uint32
+
+	prevFrameID  int32
+	prevViewport [4]int32
+}
+
+// NewRenderTarget returns a new RenderTarget of the given size
+func NewRenderTarget(width, height int, opts *RenderTargetOptions) (*RenderTarget, error) {
+	if opts == nil {
+		opts = DefaultRenderTargetOptions()
+	}
+
+	rt := &RenderTarget{
+		opts: *opts,
+	}
+	rt.opts.ColorFormats = append([]uint32{}, opts.ColorFormats...)
+	if rt.opts.Filter == 0 {
+		rt.opts.Filter = gl.LINEAR
+	}
+
+	err := rt.Resize(width, height)
+	if err != nil {
+		rt.Delete()
+		return nil, err
+	}
+	return rt, nil
+}
+
+// Delete frees all resources owned by the RenderTarget
+func (rt *RenderTarget) Delete() {
+	for _, t := range rt.Colors {
+		t.Delete()
+	}
+	rt.Colors = nil
+
+	if len(rt.msColorIDs) > 0 {
+		gl.DeleteRenderbuffers(int32(len(rt.msColorIDs)), &rt.msColorIDs[0])
+		rt.msColorIDs = nil
+	}
+	if rt.msDepthID != InvalidID {
+		gl.DeleteRenderbuffers(1, &rt.msDepthID)
+		rt.msDepthID = InvalidID
+	}
+	if rt.msFrameID != InvalidID {
+		gl.DeleteFramebuffers(1, &rt.msFrameID)
+		rt.msFrameID = InvalidID
+	}
+	if rt.depthID != InvalidID {
+		gl.DeleteRenderbuffers(1, &rt.depthID)
+		rt.depthID = InvalidID
+	}
+	if rt.frameID != InvalidID {
+		gl.DeleteFramebuffers(1, &rt.frameID)
+		rt.frameID = InvalidID
+	}
+}
+
+// isFloatFormat returns whether an internal format stores floating point values
+func isFloatFormat(format uint32) bool {
+	switch format {
+	case gl.RGBA16F, gl.RGBA32F, gl.RGB16F, gl.RGB32F, gl.RG16F, gl.RG32F, gl.R16F, gl.R32F, gl.R11F_G11F_B10F:
+		return true
+	}
+	return false
+}
+
+// Resize recreates the attachments of the RenderTarget with a new size
+func (rt *RenderTarget) Resize(width, height int) error {
+	rt.Delete()
+
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("Invalid RenderTarget size %dx%d", width, height)
+	}
+
+	rt.Width = width
+	rt.Height = height
+
+	gl.GenFramebuffers(1, &rt.frameID)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, rt.frameID)
+
+	drawBuffers := make([]uint32, 0, len(rt.opts.ColorFormats))
+	for i, format := range rt.opts.ColorFormats {
+		dataType := uint32(gl.UNSIGNED_BYTE)
+		if isFloatFormat(format) {
+			dataType = gl.FLOAT
+		}
+
+		t := &Texture{
+			Size: mgl32.Vec2{float32(width), float32(height)},
+		}
+		gl.GenTextures(1, &t.ID)
+		gl.BindTexture(gl.TEXTURE_2D, t.ID)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, rt.opts.Filter)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, rt.opts.Filter)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+		gl.TexImage2D(gl.TEXTURE_2D, 0, int32(format), int32(width), int32(height), 0, gl.RGBA, dataType, nil)
+		gl.BindTexture(gl.TEXTURE_2D, 0)
+
+		attachment := uint32(gl.COLOR_ATTACHMENT0 + i)
+		gl.FramebufferTexture2D(gl.FRAMEBUFFER, attachment, gl.TEXTURE_2D, t.ID, 0)
+		drawBuffers = append(drawBuffers, attachment)
+
+		rt.Colors = append(rt.Colors, t)
+	}
+
+	if len(drawBuffers) > 0 {
+		gl.DrawBuffers(int32(len(drawBuffers)), &drawBuffers[0])
+	} else {
+		gl.DrawBuffer(gl.NONE)
+	}
+
+	if rt.opts.Depth {
+		gl.GenRenderbuffers(1, &rt.depthID)
+		gl.BindRenderbuffer(gl.RENDERBUFFER, rt.depthID)
+		gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH_COMPONENT24, int32(width), int32(height))
+		gl.BindRenderbuffer(gl.RENDERBUFFER, 0)
+		gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.RENDERBUFFER, rt.depthID)
+	}
+
+	status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER)
+	if status != gl.FRAMEBUFFER_COMPLETE {
+		gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+		return fmt.Errorf("Failed to create Framebuffer")
+	}
+
+	if rt.opts.Samples > 1 {
+		samples := int32(rt.opts.Samples)
+
+		gl.GenFramebuffers(1, &rt.msFrameID)
+		gl.BindFramebuffer(gl.FRAMEBUFFER, rt.msFrameID)
+
+		rt.msColorIDs = make([]uint32, len(rt.opts.ColorFormats))
+		if len(rt.msColorIDs) > 0 {
+			gl.GenRenderbuffers(int32(len(rt.msColorIDs)), &rt.msColorIDs[0])
+		}
+		for i, format := range rt.opts.ColorFormats {
+			gl.BindRenderbuffer(gl.RENDERBUFFER, rt.msColorIDs[i])
+			gl.RenderbufferStorageMultisample(gl.RENDERBUFFER, samples, format, int32(width), int32(height))
+			gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, uint32(gl.COLOR_ATTACHMENT0+i), gl.RENDERBUFFER, rt.msColorIDs[i])
+		}
+
+		if len(drawBuffers) > 0 {
+			gl.DrawBuffers(int32(len(drawBuffers)), &drawBuffers[0])
+		} else {
+			gl.DrawBuffer(gl.NONE)
+		}
+
+		if rt.opts.Depth {
+			gl.GenRenderbuffers(1, &rt.msDepthID)
+			gl.BindRenderbuffer(gl.RENDERBUFFER, rt.msDepthID)
+			gl.RenderbufferStorageMultisample(gl.RENDERBUFFER, samples, gl.DEPTH_COMPONENT24, int32(width), int32(height))
+			gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.RENDERBUFFER, rt.msDepthID)
+		}
+		gl.BindRenderbuffer(gl.RENDERBUFFER, 0)
+
+		status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER)
+		if status != gl.FRAMEBUFFER_COMPLETE {
+			gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+			return fmt.Errorf("Failed to create multisample Framebuffer")
+		}
+	}
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	return nil
+}
+
+// GetSize returns the size of the RenderTarget
+func (rt *RenderTarget) GetSize() Vec2i {
+	return Vec2i{rt.Width, rt.Height}
+}
+
+// GetColor returns the resolved color texture of the given attachment, or nil
+func (rt *RenderTarget) GetColor(index int) *Texture {
+	if index < 0 || index >= len(rt.Colors) {
+		return nil
+	}
+	return rt.Colors[index]
+}
+
+// Bind sets the RenderTarget as the current framebuffer and viewport, the previous ones are restored by UnBind()
+func (rt *RenderTarget) Bind() {
+	gl.GetIntegerv(gl.DRAW_FRAMEBUFFER_BINDING, &rt.prevFrameID)
+	gl.GetIntegerv(gl.VIEWPORT, &rt.prevViewport[0])
+
+	if rt.msFrameID != InvalidID {
+		gl.BindFramebuffer(gl.FRAMEBUFFER, rt.msFrameID)
+	} else {
+		gl.BindFramebuffer(gl.FRAMEBUFFER, rt.frameID)
+	}
+	gl.Viewport(0, 0, int32(rt.Width), int32(rt.Height))
+}
+
+// UnBind resolves the RenderTarget if needed, and restores the framebuffer and viewport from before Bind()
+func (rt *RenderTarget) UnBind() {
+	rt.Resolve()
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, uint32(rt.prevFrameID))
+	gl.Viewport(rt.prevViewport[0], rt.prevViewport[1], rt.prevViewport[2], rt.prevViewport[3])
+}
+
+// Resolve copies the multisample buffers into the color textures, it does nothing if the RenderTarget is not multisampled
+func (rt *RenderTarget) Resolve() {
+	if rt.msFrameID == InvalidID {
+		return
+	}
+
+	w := int32(rt.Width)
+	h := int32(rt.Height)
+
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, rt.msFrameID)
+	gl.BindFramebuffer(gl.DRAW_FRAMEBUFFER, rt.frameID)
+	for i := range rt.Colors {
+		attachment := uint32(gl.COLOR_ATTACHMENT0 + i)
+		gl.ReadBuffer(attachment)
+		gl.DrawBuffer(attachment)
+		gl.BlitFramebuffer(0, 0, w, h, 0, 0, w, h, gl.COLOR_BUFFER_BIT, gl.NEAREST)
+	}
+	if rt.opts.Depth {
+		gl.BlitFramebuffer(0, 0, w, h, 0, 0, w, h, gl.DEPTH_BUFFER_BIT, gl.NEAREST)
+	}
+
+	// Restore the draw buffers changed for the blit
+	if len(rt.Colors) > 0 {
+		drawBuffers := make([]uint32, len(rt.Colors))
+		for i := range drawBuffers {
+			drawBuffers[i] = uint32(gl.COLOR_ATTACHMENT0 + i)
+		}
+		gl.DrawBuffers(int32(len(drawBuffers)), &drawBuffers[0])
+	}
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, 0)
+	gl.BindFramebuffer(gl.DRAW_FRAMEBUFFER, 0)
+}
@@ -0,0 +1,400 @@
+package dusk
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// SceneLoader is a function that loads a hierarchy of nodes
+type SceneLoader func(filename string) (*Scene, error)
+
+var _sceneFormats = map[string]sceneFormat{}
+
+type sceneFormat struct {
+	name   string
+	exts   []string
+	loader SceneLoader
+}
+
+// RegisterSceneFormat adds a new handler for loading scene files
+func RegisterSceneFormat(name string, exts []string, loader SceneLoader) {
+	_sceneFormats[name] = sceneFormat{
+		name:   name,
+		exts:   exts,
+		loader: loader,
+	}
+}
+
+// LoadScene loads the node hierarchy of a file, keeping the transform of each node separate from its meshes
+func LoadScene(filename string) (*Scene, error) {
+	filename = filepath.Clean(filename)
+
+	var loader SceneLoader
+
+	ext := filepath.Ext(filename)
+	for _, f := range _sceneFormats {
+		for _, e := range f.exts {
+			if e == ext {
+				loader = f.loader
+			}
+		}
+	}
+
+	if loader == nil {
+		return nil, fmt.Errorf("Unsupported scene format [%v]", ext)
+	}
+
+	Loadf("asset.Scene [%v]", filename)
+	return loader(filename)
+}
+
+// SceneNode is an object in a Scene, with a transform relative to its Parent
+type SceneNode struct {
+	Name string
+
+	Translation mgl32.Vec3
+	Rotation    mgl32.Quat
+	Scale       mgl32.Vec3
+
+	// Meshes are in the local space of the node
+	Meshes []*MeshData
+	// Camera and Light are set for nodes that view or light the Scene, a node with none of these is an empty
+	Camera *SceneCamera
+	Light  *SceneLight
+
+	Parent   *SceneNode
+	Children []*SceneNode
+}
+
+// SceneCamera is a camera loaded with a Scene, looking down the -Z axis of its SceneNode with +Y up
+type SceneCamera struct {
+	Orthographic bool
+
+	// FOV is the vertical field of view in radians, for a perspective camera
+	FOV float32
+	// OrthoSize is half of the vertical size of the view for an Orthographic camera, or 0 if the file does not give one
+	OrthoSize float32
+	// Aspect is the aspect ratio the camera was made with, or 0 if the file does not give one
+	Aspect float32
+
+	// Near and Far are the clip planes, either may be 0 to use the Camera's default
+	Near float32
+	Far  float32
+}
+
+// SceneLight is a light loaded with a Scene, shining down the -Z axis of its SceneNode
+type SceneLight struct {
+	Type      LightType
+	Color     mgl32.Vec3
+	Intensity float32
+
+	// Range is the distance at which a Point or Spot light stops having an effect, or 0 if the file does not give one
+	Range float32
+
+	// InnerAngle and OuterAngle are the angles in radians from the center to the edges of a Spot light's cone
+	InnerAngle float32
+	OuterAngle float32
+
+	CastShadows bool
+}
+
+// NewSceneNode returns a new SceneNode with an identity transform
+func NewSceneNode(name string) *SceneNode {
+	return &SceneNode{
+		Name:     name,
+		Rotation: mgl32.QuatIdent(),
+		Scale:    mgl32.Vec3{1, 1, 1},
+	}
+}
+
+// AddChild moves a node to be a child of this one
+func (n *SceneNode) AddChild(child *SceneNode) {
+	if child.Parent != nil {
+		child.Parent.RemoveChild(child)
+	}
+	child.Parent = n
+	n.Children = append(n.Children, child)
+}
+
+// RemoveChild removes a child, leaving it without a Parent
+func (n *SceneNode) RemoveChild(child *SceneNode) {
+	for i := range n.Children {
+		if n.Children[i] == child {
+			n.Children = append(n.Children[:i], n.Children[i+1:]...)
+			child.Parent = nil
+			return
+		}
+	}
+}
+
+// SetMatrix sets the Translation, Rotation and Scale from a matrix without shear
+func (n *SceneNode) SetMatrix(m mgl32.Mat4) {
+	n.Translation, n.Rotation, n.Scale = DecomposeMatrix(m)
+}
+
+// GetLocalMatrix returns the transform relative to the Parent
+func (n *SceneNode) GetLocalMatrix() mgl32.Mat4 {
+	return mgl32.Translate3D(n.Translation[0], n.Translation[1], n.Translation[2]).
+		Mul4(n.Rotation.Mat4()).
+		Mul4(mgl32.Scale3D(n.Scale[0], n.Scale[1], n.Scale[2]))
+}
+
+// GetGlobalMatrix returns the transform relative to the Scene
+func (n *SceneNode) GetGlobalMatrix() mgl32.Mat4 {
+	if n.Parent == nil {
+		return n.GetLocalMatrix()
+	}
+	return n.Parent.GetGlobalMatrix().Mul4(n.GetLocalMatrix())
+}
+
+// NewTransform returns a Transform matching the node, with the given Parent
+func (n *SceneNode) NewTransform(parent *Transform) *Transform {
+	return &Transform{
+		Parent:   parent,
+		Position: n.Translation,
+		Rotation: eulerFromMatrix(n.Rotation.Mat4().Mat3()),
+		Scale:    n.Scale,
+	}
+}
+
+// Walk calls fn for the node and all of its descendants, parents first
+func (n *SceneNode) Walk(fn func(*SceneNode)) {
+	fn(n)
+	for _, c := range n.Children {
+		c.Walk(fn)
+	}
+}
+
+// Scene is a hierarchy of SceneNodes loaded from a file
+type Scene struct {
+	// Nodes are the nodes without a Parent
+	Nodes []*SceneNode
+}
+
+// AddNode adds a node without a Parent
+func (s *Scene) AddNode(n *SceneNode) {
+	s.Nodes = append(s.Nodes, n)
+}
+
+// Walk calls fn for every node, parents first
+func (s *Scene) Walk(fn func(*SceneNode)) {
+	for _, n := range s.Nodes {
+		n.Walk(fn)
+	}
+}
+
+// FindNode returns the first node with the given name, or nil
+func (s *Scene) FindNode(name string) *SceneNode {
+	var found *SceneNode
+	s.Walk(func(n *SceneNode) {
+		if found == nil && n.Name == name {
+			found = n
+		}
+	})
+	return found
+}
+
+// Instantiate adds an Entity to the layer for every node, with a Model for its Meshes, and a Camera or Light
+// Entities are parented through their Transforms, and are returned in the same order as Walk
+// Lights shine in the direction their node faces when the Scene is instantiated, since a Light's Direction does not follow its Entity
+func (s *Scene) Instantiate(layer ILayer) ([]*Entity, error) {
+	entities := []*Entity{}
+	transforms := map[*SceneNode]*Transform{}
+
+	var err error
+	s.Walk(func(n *SceneNode) {
+		if err != nil {
+			return
+		}
+
+		e := NewEntity(layer)
+		e.SetTransform(n.NewTransform(transforms[n.Parent]))
+		transforms[n] = e.Transform()
+
+		if len(n.Meshes) > 0 {
+			var model *Model
+			model, err = NewModelFromData(e, n.Meshes)
+			if err != nil {
+				return
+			}
+			e.AddComponent(model)
+
+			if model.GetSkeleton() != nil {
+				e.AddComponent(NewAnimationPlayer(e, model))
+			}
+		}
+
+		if n.Camera != nil {
+			e.AddComponent(n.Camera.NewCamera(e))
+		}
+
+		if n.Light != nil {
+			light := n.Light.NewLight(e)
+			light.Direction = mgl32.TransformNormal(mgl32.Vec3{0, 0, -1}, n.GetGlobalMatrix()).Normalize()
+			e.AddComponent(light)
+		}
+
+		layer.AddEntity(e)
+		entities = append(entities, e)
+	})
+
+	if err != nil {
+		for _, e := range entities {
+			layer.RemoveEntity(e)
+			for _, c := range e.GetComponents() {
+				c.Delete()
+			}
+			e.Delete()
+		}
+		return nil, err
+	}
+
+	return entities, nil
+}
+
+// NewCamera returns a Camera with the settings of the SceneCamera, positioned by the entity, it still needs to be added to the Entity
+func (c *SceneCamera) NewCamera(entity IEntity) *Camera {
+	camera := NewEntityCamera(entity)
+	if c.Aspect > 0 {
+		camera.Aspect = c.Aspect
+	}
+	near, far := c.Near, c.Far
+	if near <= 0 {
+		near = camera.Near
+	}
+	if far <= near {
+		far = camera.Far
+	}
+	if c.Orthographic {
+		size := c.OrthoSize
+		if size <= 0 {
+			size = camera.OrthoSize
+		}
+		camera.SetOrthographic(size, near, far)
+	} else {
+		fov := c.FOV
+		if fov <= 0 {
+			fov = camera.FOV
+		}
+		camera.SetPerspective(fov, near, far)
+	}
+	return camera
+}
+
+// NewLight returns a Light with the settings of the SceneLight, it still needs to be added to the Entity
+func (l *SceneLight) NewLight(entity IEntity) *Light {
+	light := NewLight(entity, l.Type)
+	light.Color = l.Color
+	light.Intensity = l.Intensity
+	if l.Range > 0 {
+		light.Range = l.Range
+	}
+	if l.Type == SpotLight {
+		light.InnerAngle = l.InnerAngle
+		light.OuterAngle = l.OuterAngle
+	}
+	light.CastShadows = l.CastShadows
+	return light
+}
+
+// Flatten returns the MeshData of every node with its global transform applied to the vertices, for a single Model
+// Skinned MeshData is returned unchanged, since it is posed by its Skeleton. Names are made unique with a suffix
+func (s *Scene) Flatten() []*MeshData {
+	data := []*MeshData{}
+	names := map[string]int{}
+
+	s.Walk(func(n *SceneNode) {
+		global := n.GetGlobalMatrix()
+		for _, original := range n.Meshes {
+			d := original
+			if !d.HasBones() {
+				d = transformMeshData(d, global)
+			}
+
+			count := names[d.Name]
+			names[d.Name]++
+			if count > 0 {
+				if d == original {
+					copied := *d
+					d = &copied
+				}
+				d.Name = fmt.Sprintf("%s.%d", d.Name, count)
+			}
+
+			data = append(data, d)
+		}
+	})
+
+	return data
+}
+
+// transformMeshData returns a copy of the MeshData with a matrix applied to its vertices, or the same MeshData for an identity matrix
+func transformMeshData(d *MeshData, m mgl32.Mat4) *MeshData {
+	if m.ApproxEqual(mgl32.Ident4()) {
+		return d
+	}
+
+	out := *d
+	normMat := m.Mat3().Inv().Transpose()
+	dirMat := m.Mat3()
+
+	out.Vertices = make([]mgl32.Vec3, len(d.Vertices))
+	for i, v := range d.Vertices {
+		out.Vertices[i] = mgl32.TransformCoordinate(v, m)
+	}
+	out.Normals = transformDirections(d.Normals, normMat)
+	out.Tangents = transformDirections(d.Tangents, dirMat)
+	out.Bitangents = transformDirections(d.Bitangents, dirMat)
+	out.Bounds = Bounds{}
+
+	// A mirroring matrix turns the triangles inside out, so their winding is reversed to keep them facing outwards
+	if m.Mat3().Det() < 0 {
+		out.TexCoords = append([]mgl32.Vec2{}, d.TexCoords...)
+		out.Colors = append([]mgl32.Vec4{}, d.Colors...)
+		out.BoneIndices = append([]mgl32.Vec4{}, d.BoneIndices...)
+		out.BoneWeights = append([]mgl32.Vec4{}, d.BoneWeights...)
+		for i := 0; i+2 < len(out.Vertices); i += 3 {
+			swapCorners(out.Vertices, i)
+			swapCorners(out.Normals, i)
+			swapCorners(out.TexCoords, i)
+			swapCorners(out.Colors, i)
+			swapCorners(out.Tangents, i)
+			swapCorners(out.Bitangents, i)
+			swapCorners(out.BoneIndices, i)
+			swapCorners(out.BoneWeights, i)
+		}
+	}
+
+	return &out
+}
+
+func transformDirections(dirs []mgl32.Vec3, m mgl32.Mat3) []mgl32.Vec3 {
+	if dirs == nil {
+		return nil
+	}
+	out := make([]mgl32.Vec3, len(dirs))
+	for i, d := range dirs {
+		out[i] = m.Mul3x1(d).Normalize()
+	}
+	return out
+}
+
+// swapCorners swaps the last two corners of the triangle starting at i, if the slice has them
+func swapCorners(s interface{}, i int) {
+	switch v := s.(type) {
+	case []mgl32.Vec2:
+		if i+2 < len(v) {
+			v[i+1], v[i+2] = v[i+2], v[i+1]
+		}
+	case []mgl32.Vec3:
+		if i+2 < len(v) {
+			v[i+1], v[i+2] = v[i+2], v[i+1]
+		}
+	case []mgl32.Vec4:
+		if i+2 < len(v) {
+			v[i+1], v[i+2] = v[i+2], v[i+1]
+		}
+	}
+}
@@ -210,6 +210,15 @@ func getVersionString() string {
 	return _versionString
 }
 
+// withDefines returns the code with a #define for each name added to the start
+func withDefines(code string, names ...string) string {
+	header := ""
+	for _, name := range names {
+		header += "#define " + name + "\n"
+	}
+	return header + code
+}
+
 func preProcessFile(filename, code string) string {
 	code = preProcessCode(filepath.Dir(filename), code, GetShaderDefines())
 
@@ -229,11 +238,60 @@ func preProcessCode(dir, code string, defines map[string]string) string {
 	// PreProcessor statements
 	lines := strings.Split(code+"\n", "\n")
 
-	skipLines := false
+	// Each #ifdef/#ifndef pushes a state, so blocks can be nested and have an #else
+	type condState struct {
+		active bool
+		parent bool
+		taken  bool
+	}
+	stack := []condState{}
+	isActive := func() bool {
+		return len(stack) == 0 || stack[len(stack)-1].active
+	}
+	pushCond := func(cond bool) {
+		parent := isActive()
+		stack = append(stack, condState{
+			active: parent && cond,
+			parent: parent,
+			taken:  cond,
+		})
+	}
 
 	newLines := make([]string, 0, len(lines))
 	for _, line := range lines {
 		if len(line) > 0 && line[0] == '#' {
+			if strings.HasPrefix(line, "#ifdef") {
+				name := strings.TrimSpace(line[7:])
+				_, found := defines[name]
+				pushCond(found)
+				continue
+			} else if strings.HasPrefix(line, "#ifndef") {
+				name := strings.TrimSpace(line[8:])
+				_, found := defines[name]
+				pushCond(!found)
+				continue
+			} else if strings.HasPrefix(line, "#else") {
+				if len(stack) == 0 {
+					Warnf("#else without #ifdef or #ifndef")
+					continue
+				}
+				top := &stack[len(stack)-1]
+				top.active = top.parent && !top.taken
+				top.taken = true
+				continue
+			} else if strings.HasPrefix(line, "#endif") {
+				if len(stack) == 0 {
+					Warnf("#endif without #ifdef or #ifndef")
+					continue
+				}
+				stack = stack[:len(stack)-1]
+				continue
+			}
+
+			if !isActive() {
+				continue
+			}
+
 			if strings.HasPrefix(line, "#include") {
 				file := strings.TrimSpace(line[8:])
 				if len(file) < 3 {
@@ -270,25 +328,13 @@ func preProcessCode(dir, code string, defines map[string]string) string {
 				}
 				defines[name] = value
 			} else if strings.HasPrefix(line, "#undef") {
-				name := strings.TrimSpace(line[8:])
-				delete(defines, name)
-			} else if strings.HasPrefix(line, "#ifdef") {
 				name := strings.TrimSpace(line[7:])
-				if _, found := defines[name]; !found {
-					skipLines = true
-				}
-			} else if strings.HasPrefix(line, "#ifndef") {
-				name := strings.TrimSpace(line[8:])
-				if _, found := defines[name]; found {
-					skipLines = true
-				}
-			} else if strings.HasPrefix(line, "#endif") {
-				skipLines = false
+				delete(defines, name)
 			}
 			continue
 		}
 
-		if skipLines {
+		if !isActive() {
 			continue
 		}
 
@@ -299,6 +345,10 @@ func preProcessCode(dir, code string, defines map[string]string) string {
 		newLines = append(newLines, line)
 	}
 
+	if len(stack) > 0 {
+		Warnf("Missing #endif")
+	}
+
 	code = strings.Join(newLines, "\n")
 
 	return code
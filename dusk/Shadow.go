@@ -0,0 +1,351 @@
+package dusk
+
+import (
+	"fmt"
+
+	gl "github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/WhoBrokeTheBuild/GoDusk/m32"
+)
+
+const (
+	// ShadowMapTextureUnit is the first texture unit used for shadow maps
+	ShadowMapTextureUnit = 12
+)
+
+const (
+	shadowShaderVert = `
+#include <mvp.inc.glsl>
+#include <attribute.inc.glsl>
+#include <instance.inc.glsl>
+#include <skin.inc.glsl>
+
+void main() {
+    gl_Position = uProjection * uView * GetModelMatrix() * GetSkinMatrix() * vec4(_Position, 1);
+}
+`
+	shadowShaderFrag = `
+void main() {
+}
+`
+)
+
+// ShadowShader is the shader used to render depth into a ShadowMap
+type ShadowShader struct {
+	Shader
+}
+
+var (
+	_shadowShader          *ShadowShader
+	_shadowInstancedShader *ShadowShader
+)
+
+// GetShadowShader returns an instance of the ShadowShader
+func GetShadowShader() *ShadowShader {
+	if _shadowShader != nil {
+		return _shadowShader
+	}
+	Loadf("Loading Shadow Shader")
+	_shadowShader = &ShadowShader{}
+	_shadowShader.InitFromData(
+		&ShaderData{
+			Code: shadowShaderVert,
+			Type: gl.VERTEX_SHADER,
+		},
+		&ShaderData{
+			Code: shadowShaderFrag,
+			Type: gl.FRAGMENT_SHADER,
+		},
+	)
+	return _shadowShader
+}
+
+// GetShadowInstancedShader returns an instance of the ShadowShader that reads the model matrix from instance attributes
+func GetShadowInstancedShader() *ShadowShader {
+	if _shadowInstancedShader != nil {
+		return _shadowInstancedShader
+	}
+	Loadf("Loading Shadow Instanced Shader")
+	_shadowInstancedShader = &ShadowShader{}
+	_shadowInstancedShader.InitFromData(
+		&ShaderData{
+			Code: withDefines(shadowShaderVert, "USE_INSTANCING"),
+			Type: gl.VERTEX_SHADER,
+		},
+		&ShaderData{
+			Code: shadowShaderFrag,
+			Type: gl.FRAGMENT_SHADER,
+		},
+	)
+	return _shadowInstancedShader
+}
+
+// Bind implements the Shader interface
+func (s *ShadowShader) Bind(ctx *RenderContext, data interface{}) {
+	s.Shader.Bind(ctx, data)
+	model := mgl32.Mat4{}
+	if data != nil {
+		model = data.(mgl32.Mat4)
+	}
+
+	gl.UniformMatrix4fv(s.UniformLocation("uProjection"), 1, false, &ctx.Projection[0])
+	gl.UniformMatrix4fv(s.UniformLocation("uView"), 1, false, &ctx.Camera.View[0])
+	gl.UniformMatrix4fv(s.UniformLocation("uModel"), 1, false, &model[0])
+}
+
+// ShadowMap is an array of depth textures rendered from the point of view of a Light
+type ShadowMap struct {
+	Resolution int
+	Layers     int
+
+	// Matrices are the view-projection matrices used to render each layer
+	Matrices []mgl32.Mat4
+	// Splits are the view space distances where each cascade ends
+	Splits []float32
+
+	bias      float32
+	textureID uint32
+	frameID   uint32
+}
+
+// NewShadowMap returns a new ShadowMap with the given resolution and number of layers
+func NewShadowMap(resolution, layers int) (*ShadowMap, error) {
+	s := &ShadowMap{
+		Resolution: resolution,
+		Layers:     layers,
+		Matrices:   make([]mgl32.Mat4, layers),
+		Splits:     make([]float32, layers),
+	}
+
+	gl.GenTextures(1, &s.textureID)
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, s.textureID)
+	gl.TexImage3D(gl.TEXTURE_2D_ARRAY, 0, gl.DEPTH_COMPONENT24,
+		int32(resolution), int32(resolution), int32(layers),
+		0, gl.DEPTH_COMPONENT, gl.FLOAT, nil)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_BORDER)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_BORDER)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_COMPARE_MODE, gl.COMPARE_REF_TO_TEXTURE)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_COMPARE_FUNC, gl.LEQUAL)
+	border := mgl32.Vec4{1, 1, 1, 1}
+	gl.TexParameterfv(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_BORDER_COLOR, &border[0])
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, 0)
+
+	gl.GenFramebuffers(1, &s.frameID)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, s.frameID)
+	gl.FramebufferTextureLayer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, s.textureID, 0, 0)
+	gl.DrawBuffer(gl.NONE)
+	gl.ReadBuffer(gl.NONE)
+
+	status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	if status != gl.FRAMEBUFFER_COMPLETE {
+		s.Delete()
+		return nil, fmt.Errorf("Failed to create Shadow Map Framebuffer")
+	}
+
+	return s, nil
+}
+
+// Delete frees all resources owned by the ShadowMap
+func (s *ShadowMap) Delete() {
+	if s.frameID != InvalidID {
+		gl.DeleteFramebuffers(1, &s.frameID)
+		s.frameID = InvalidID
+	}
+	if s.textureID != InvalidID {
+		gl.DeleteTextures(1, &s.textureID)
+		s.textureID = InvalidID
+	}
+}
+
+// BindLayer binds the ShadowMap's framebuffer to render into the given layer
+func (s *ShadowMap) BindLayer(layer int) {
+	gl.BindFramebuffer(gl.FRAMEBUFFER, s.frameID)
+	gl.FramebufferTextureLayer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, s.textureID, 0, int32(layer))
+	gl.Viewport(0, 0, int32(s.Resolution), int32(s.Resolution))
+}
+
+func (s *ShadowMap) bindUniforms(sh IShader, index int) {
+	u := &shadowUniforms[index]
+
+	gl.ActiveTexture(gl.TEXTURE0 + uint32(ShadowMapTextureUnit+index))
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, s.textureID)
+	gl.Uniform1i(sh.UniformLocation(u.Map), int32(ShadowMapTextureUnit+index))
+
+	gl.Uniform1f(sh.UniformLocation(u.Bias), s.bias)
+	gl.Uniform1i(sh.UniformLocation(u.Cascades), int32(s.Layers))
+
+	splits := [MaxShadowCascades]float32{}
+	for i := 0; i < s.Layers && i < MaxShadowCascades; i++ {
+		splits[i] = s.Splits[i]
+	}
+	gl.Uniform1fv(sh.UniformLocation(u.Splits), MaxShadowCascades, &splits[0])
+
+	for i := 0; i < s.Layers; i++ {
+		gl.UniformMatrix4fv(sh.UniformLocation(u.Matrices[i]), 1, false, &s.Matrices[i][0])
+	}
+	gl.ActiveTexture(gl.TEXTURE0)
+}
+
+// updateShadowMap reallocates the Light's ShadowMap if needed, and calculates the matrices for each layer
+func (l *Light) updateShadowMap(ctx *RenderContext) error {
+	layers := 1
+	if l.Type == DirectionalLight {
+		layers = l.ShadowCascades
+		if layers < 1 {
+			layers = 1
+		}
+		if layers > MaxShadowCascades {
+			layers = MaxShadowCascades
+		}
+	}
+
+	if l.shadowMap != nil && (l.shadowMap.Resolution != l.ShadowResolution || l.shadowMap.Layers != layers) {
+		l.shadowMap.Delete()
+		l.shadowMap = nil
+	}
+
+	if l.shadowMap == nil {
+		var err error
+		l.shadowMap, err = NewShadowMap(l.ShadowResolution, layers)
+		if err != nil {
+			return err
+		}
+	}
+
+	s := l.shadowMap
+	s.bias = l.ShadowBias
+
+	dir := l.Direction.Normalize()
+	up := mgl32.Vec3{0, 1, 0}
+	if m32.Abs(dir.Dot(up)) > 0.99 {
+		up = mgl32.Vec3{0, 0, 1}
+	}
+
+	if l.Type == SpotLight {
+		pos := l.GetPosition()
+		proj := mgl32.Perspective(l.OuterAngle*2.0, 1.0, 0.1, l.Range)
+		view := mgl32.LookAtV(pos, pos.Add(dir), up)
+		s.Matrices[0] = proj.Mul4(view)
+		s.Splits[0] = l.Range
+		return nil
+	}
+
+	camNear := ctx.Camera.Near
+	camFar := ctx.Camera.Far
+
+	near := camNear
+	far := m32.Min(camFar, l.ShadowDistance)
+
+	// World space corners of the camera frustum on the near and far planes
+	inv := ctx.Projection.Mul4(ctx.Camera.View).Inv()
+	var nearCorners, farCorners [4]mgl32.Vec3
+	ndc := [4]mgl32.Vec2{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}}
+	for i, c := range ndc {
+		nearCorners[i] = mgl32.TransformCoordinate(mgl32.Vec3{c[0], c[1], -1}, inv)
+		farCorners[i] = mgl32.TransformCoordinate(mgl32.Vec3{c[0], c[1], 1}, inv)
+	}
+
+	// Practical split scheme, blending logarithmic and uniform splits
+	const lambda = 0.75
+	start := near
+	for i := 0; i < layers; i++ {
+		p := float32(i+1) / float32(layers)
+		log := near * m32.Pow(far/near, p)
+		uni := near + (far-near)*p
+		end := lambda*log + (1.0-lambda)*uni
+
+		var corners [8]mgl32.Vec3
+		center := mgl32.Vec3{}
+		for j := 0; j < 4; j++ {
+			edge := farCorners[j].Sub(nearCorners[j])
+			corners[j] = nearCorners[j].Add(edge.Mul((start - camNear) / (camFar - camNear)))
+			corners[j+4] = nearCorners[j].Add(edge.Mul((end - camNear) / (camFar - camNear)))
+			center = center.Add(corners[j]).Add(corners[j+4])
+		}
+		center = center.Mul(1.0 / 8.0)
+
+		// Fitting a sphere keeps the size constant as the camera rotates, which reduces shimmering
+		radius := float32(0)
+		for _, c := range corners {
+			radius = m32.Max(radius, c.Sub(center).Len())
+		}
+		radius = m32.Ceil(radius*16.0) / 16.0
+
+		// Snap the center to the size of a texel to reduce shimmering as the camera moves
+		view := mgl32.LookAtV(mgl32.Vec3{}, dir, up)
+		texel := (radius * 2.0) / float32(s.Resolution)
+		lc := mgl32.TransformCoordinate(center, view)
+		lc[0] = m32.Floor(lc[0]/texel) * texel
+		lc[1] = m32.Floor(lc[1]/texel) * texel
+		center = mgl32.TransformCoordinate(lc, view.Inv())
+
+		// Pull the eye back so that casters outside of the view frustum still cast shadows
+		eye := center.Sub(dir.Mul(radius * 2.0))
+		view = mgl32.LookAtV(eye, center, up)
+		proj := mgl32.Ortho(-radius, radius, -radius, radius, 0.0, radius*4.0)
+
+		s.Matrices[i] = proj.Mul4(view)
+		s.Splits[i] = end
+		start = end
+	}
+
+	return nil
+}
+
+// renderShadows renders the depth of all layers into the ShadowMap of each shadow casting Light
+// The framebuffer and viewport bound before the call are restored afterwards
+func renderShadows(ctx *RenderContext, layers []ILayer) {
+	var (
+		prevFrameID  int32
+		prevViewport [4]int32
+	)
+	gl.GetIntegerv(gl.DRAW_FRAMEBUFFER_BINDING, &prevFrameID)
+	gl.GetIntegerv(gl.VIEWPORT, &prevViewport[0])
+
+	shadowCtx := &RenderContext{
+		Projection: mgl32.Ident4(),
+		Camera:     &Camera{},
+		Pass:       ShadowPass,
+	}
+
+	gl.Enable(gl.POLYGON_OFFSET_FILL)
+	gl.PolygonOffset(2.0, 4.0)
+
+	for _, l := range ctx.Lights {
+		if !l.CastShadows {
+			continue
+		}
+		if l.Type == PointLight {
+			continue
+		}
+
+		err := l.updateShadowMap(ctx)
+		if err != nil {
+			Errorf("%v", err)
+			l.CastShadows = false
+			continue
+		}
+
+		s := l.shadowMap
+		for i := 0; i < s.Layers; i++ {
+			s.BindLayer(i)
+			gl.Clear(gl.DEPTH_BUFFER_BIT)
+
+			// The light's view-projection is used as the projection, so the view is identity
+			shadowCtx.Projection = s.Matrices[i]
+			shadowCtx.Camera.View = mgl32.Ident4()
+
+			for _, layer := range layers {
+				layer.Render(shadowCtx)
+			}
+		}
+	}
+
+	gl.Disable(gl.POLYGON_OFFSET_FILL)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, uint32(prevFrameID))
+	gl.Viewport(prevViewport[0], prevViewport[1], prevViewport[2], prevViewport[3])
+}
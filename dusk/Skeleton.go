@@ -0,0 +1,66 @@
+package dusk
+
+import (
+	gl "github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// MaxBones is the maximum number of bones passed to a shader for skinning
+	MaxBones = 100
+)
+
+func init() {
+	AddShaderDefines(map[string]interface{}{
+		"MAX_BONES": MaxBones,
+	})
+}
+
+// Bone is a joint of a Skeleton
+type Bone struct {
+	Name string
+
+	// Parent is the index of the parent Bone, or -1 for a root
+	Parent int
+
+	// Offset transforms from mesh space to the space of the Bone in the bind pose, the inverse of its bind matrix
+	Offset mgl32.Mat4
+
+	// Translation, Rotation and Scale are the rest pose, relative to the Parent
+	Translation mgl32.Vec3
+	Rotation    mgl32.Quat
+	Scale       mgl32.Vec3
+}
+
+// GetRestMatrix returns the rest pose of the Bone, relative to its Parent
+func (b *Bone) GetRestMatrix() mgl32.Mat4 {
+	return composeMatrix(b.Translation, b.Rotation, b.Scale)
+}
+
+// Skeleton is a hierarchy of Bones, every Bone comes after its Parent
+type Skeleton struct {
+	Bones []Bone
+}
+
+// FindBone returns the index of the named Bone, or -1
+func (s *Skeleton) FindBone(name string) int {
+	for i := range s.Bones {
+		if s.Bones[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// composeMatrix returns the matrix that scales, then rotates, then translates
+func composeMatrix(t mgl32.Vec3, r mgl32.Quat, s mgl32.Vec3) mgl32.Mat4 {
+	return mgl32.Translate3D(t[0], t[1], t[2]).Mul4(r.Mat4()).Mul4(mgl32.Scale3D(s[0], s[1], s[2]))
+}
+
+// setBoneUniforms enables skinning with the given bone matrices, or disables it if there are none
+func setBoneUniforms(s IShader, bones []mgl32.Mat4) {
+	gl.Uniform1i(s.UniformLocation("uSkinned"), boolToInt32(len(bones) > 0))
+	if len(bones) > 0 {
+		gl.UniformMatrix4fv(s.UniformLocation("uBones[0]"), int32(len(bones)), false, &bones[0][0])
+	}
+}
@@ -0,0 +1,192 @@
+package dusk
+
+import (
+	gl "github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	skyboxShaderVert = `
+#include <mvp.inc.glsl>
+#include <attribute.inc.glsl>
+
+out vec3 p_Direction;
+
+void main() {
+    p_Direction = _Position;
+
+    // Only the rotation of the view is used, so the sky is infinitely far away
+    vec4 pos = uProjection * mat4(mat3(uView)) * vec4(_Position, 1.0);
+
+    // Setting z to w puts the sky on the far plane
+    gl_Position = pos.xyww;
+}
+`
+	skyboxShaderFrag = `
+uniform samplerCube uSkybox;
+uniform float uIntensity;
+uniform int uSkyboxHDR;
+uniform int uHDROutput;
+
+in vec3 p_Direction;
+
+out vec4 _Color;
+
+void main() {
+    vec3 color = texture(uSkybox, p_Direction).rgb;
+    if (uSkyboxHDR == 0) {
+        color = pow(color, vec3(2.2));
+    }
+    color *= uIntensity;
+
+    // Tone mapping and gamma correction are left to post-processing when rendering to HDR
+    if (uHDROutput == 0) {
+        if (uSkyboxHDR != 0) {
+            color = color / (color + vec3(1.0));
+        }
+        color = pow(color, vec3(1.0 / 2.2));
+    }
+
+    _Color = vec4(color, 1.0);
+}
+`
+)
+
+// SkyboxShader draws a Cubemap around the camera
+type SkyboxShader struct {
+	Shader
+}
+
+var _skyboxShader *SkyboxShader
+
+// GetSkyboxShader returns an instance of the SkyboxShader
+func GetSkyboxShader() *SkyboxShader {
+	if _skyboxShader != nil {
+		return _skyboxShader
+	}
+	Loadf("Loading Skybox Shader")
+	_skyboxShader = &SkyboxShader{}
+	_skyboxShader.InitFromData(
+		&ShaderData{
+			Code: skyboxShaderVert,
+			Type: gl.VERTEX_SHADER,
+		},
+		&ShaderData{
+			Code: skyboxShaderFrag,
+			Type: gl.FRAGMENT_SHADER,
+		},
+	)
+	return _skyboxShader
+}
+
+// Bind implements the Shader interface, data is the *Skybox being drawn
+func (s *SkyboxShader) Bind(ctx *RenderContext, data interface{}) {
+	s.Shader.Bind(ctx, data)
+
+	gl.UniformMatrix4fv(s.UniformLocation("uProjection"), 1, false, &ctx.Projection[0])
+	gl.UniformMatrix4fv(s.UniformLocation("uView"), 1, false, &ctx.Camera.View[0])
+	gl.Uniform1i(s.UniformLocation("uHDROutput"), boolToInt32(ctx.HDR))
+
+	if sky, ok := data.(*Skybox); ok {
+		gl.Uniform1f(s.UniformLocation("uIntensity"), sky.Intensity)
+		gl.Uniform1i(s.UniformLocation("uSkyboxHDR"), boolToInt32(sky.Cubemap.HDR))
+	}
+	gl.Uniform1i(s.UniformLocation("uSkybox"), 0)
+}
+
+var _skyboxMesh *Mesh
+
+// getSkyboxMesh returns a cube from -1 to 1, shared by all Skyboxes
+func getSkyboxMesh() (*Mesh, error) {
+	if _skyboxMesh != nil {
+		return _skyboxMesh, nil
+	}
+
+	corners := [8]mgl32.Vec3{
+		{-1, -1, -1}, {1, -1, -1}, {1, 1, -1}, {-1, 1, -1},
+		{-1, -1, 1}, {1, -1, 1}, {1, 1, 1}, {-1, 1, 1},
+	}
+	faces := [6][4]int{
+		{1, 5, 6, 2}, // +X
+		{4, 0, 3, 7}, // -X
+		{3, 2, 6, 7}, // +Y
+		{4, 5, 1, 0}, // -Y
+		{5, 4, 7, 6}, // +Z
+		{0, 1, 2, 3}, // -Z
+	}
+
+	data := &MeshData{
+		Name:     "skybox",
+		Vertices: []mgl32.Vec3{},
+	}
+	for _, f := range faces {
+		data.Vertices = append(data.Vertices,
+			corners[f[0]], corners[f[1]], corners[f[2]],
+			corners[f[0]], corners[f[2]], corners[f[3]])
+	}
+
+	var err error
+	_skyboxMesh, err = NewMeshFromData(data)
+	if err != nil {
+		_skyboxMesh = nil
+		return nil, err
+	}
+	return _skyboxMesh, nil
+}
+
+// Skybox is a Component that draws a Cubemap behind everything else in its Layer
+// It can also be set as a Camera's Skybox, to be drawn when the Camera clears with ClearSkybox
+type Skybox struct {
+	Component
+
+	Cubemap *Cubemap
+
+	// Intensity multiplies the color of the Cubemap
+	Intensity float32
+}
+
+// NewSkybox returns a new Skybox of the given Cubemap
+func NewSkybox(entity IEntity, cubemap *Cubemap) *Skybox {
+	s := &Skybox{
+		Cubemap:   cubemap,
+		Intensity: 1.0,
+	}
+	s.Init(entity)
+	return s
+}
+
+// Render implements the Component interface
+func (s *Skybox) Render(ctx *RenderContext) {
+	if ctx.Pass != ColorPass {
+		return
+	}
+	s.Draw(ctx)
+}
+
+// Draw draws the Skybox on the far plane, behind anything already drawn
+func (s *Skybox) Draw(ctx *RenderContext) {
+	if s.Cubemap == nil || ctx.Camera == nil {
+		return
+	}
+
+	mesh, err := getSkyboxMesh()
+	if err != nil {
+		Errorf("%v", err)
+		return
+	}
+
+	shader := GetSkyboxShader()
+	shader.Bind(ctx, s)
+
+	gl.ActiveTexture(gl.TEXTURE0)
+	s.Cubemap.Bind()
+
+	// The sky is drawn at a depth of exactly 1.0, so it must pass when equal to a cleared depth buffer
+	gl.DepthFunc(gl.LEQUAL)
+	gl.DepthMask(false)
+	mesh.Draw()
+	gl.DepthMask(true)
+	gl.DepthFunc(gl.LESS)
+
+	gl.BindTexture(gl.TEXTURE_CUBE_MAP, 0)
+}
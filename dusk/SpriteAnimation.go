@@ -0,0 +1,310 @@
+package dusk
+
+import (
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// AnimationMode controls what happens when a SpriteAnimation reaches its last frame
+type AnimationMode int
+
+const (
+	// AnimationLoop starts again from the first frame
+	AnimationLoop AnimationMode = iota
+	// AnimationPingPong plays the frames backwards, then forwards again
+	AnimationPingPong
+	// AnimationOnce stops on the last frame
+	AnimationOnce
+)
+
+// AnimationFrame is a single image of a SpriteAnimation
+type AnimationFrame struct {
+	Texture *Texture
+	// UV is the area of the Texture to draw as (left, top, right, bottom), from 0 to 1
+	UV mgl32.Vec4
+	// Size is the size of the frame in pixels
+	Size mgl32.Vec2
+	// Duration is how long the frame is shown, in seconds
+	Duration float32
+	// Event is passed to the Animator's FrameFuncs when the frame is shown, it may be empty
+	Event string
+}
+
+// SpriteAnimation is a sequence of AnimationFrames
+type SpriteAnimation struct {
+	Name   string
+	Frames []AnimationFrame
+	Mode   AnimationMode
+}
+
+// NewSpriteAnimationFromAtlas returns a new SpriteAnimation of the named regions of a TextureAtlas, each shown for duration seconds
+func NewSpriteAnimationFromAtlas(name string, a *TextureAtlas, regions []string, duration float32, mode AnimationMode) (*SpriteAnimation, error) {
+	anim := &SpriteAnimation{
+		Name:   name,
+		Frames: make([]AnimationFrame, 0, len(regions)),
+		Mode:   mode,
+	}
+	for _, r := range regions {
+		region, found := a.GetRegion(r)
+		if !found {
+			return nil, fmt.Errorf("Atlas region [%v] not found", r)
+		}
+		anim.Frames = append(anim.Frames, AnimationFrame{
+			Texture:  a.Texture,
+			UV:       region.UV,
+			Size:     region.Size,
+			Duration: duration,
+		})
+	}
+	return anim, nil
+}
+
+// NewSpriteAnimationFromGrid returns a new SpriteAnimation from a sprite sheet sliced into frames of frameWidth x frameHeight pixels
+// Frames are numbered left to right, then top to bottom, and count frames are used starting from first
+func NewSpriteAnimationFromGrid(name string, texture *Texture, frameWidth, frameHeight, first, count int, duration float32, mode AnimationMode) (*SpriteAnimation, error) {
+	if frameWidth <= 0 || frameHeight <= 0 {
+		return nil, fmt.Errorf("Invalid frame size %dx%d", frameWidth, frameHeight)
+	}
+
+	w := texture.Size.X()
+	h := texture.Size.Y()
+	columns := int(w) / frameWidth
+	rows := int(h) / frameHeight
+	if first < 0 || count <= 0 || first+count > columns*rows {
+		return nil, fmt.Errorf("Frames %d to %d are outside of the %dx%d grid", first, first+count-1, columns, rows)
+	}
+
+	anim := &SpriteAnimation{
+		Name:   name,
+		Frames: make([]AnimationFrame, 0, count),
+		Mode:   mode,
+	}
+	for i := first; i < first+count; i++ {
+		x := float32((i % columns) * frameWidth)
+		y := float32((i / columns) * frameHeight)
+		anim.Frames = append(anim.Frames, AnimationFrame{
+			Texture:  texture,
+			UV:       mgl32.Vec4{x / w, y / h, (x + float32(frameWidth)) / w, (y + float32(frameHeight)) / h},
+			Size:     mgl32.Vec2{float32(frameWidth), float32(frameHeight)},
+			Duration: duration,
+		})
+	}
+	return anim, nil
+}
+
+// GetDuration returns the total duration of one pass through the frames, in seconds
+func (a *SpriteAnimation) GetDuration() float32 {
+	total := float32(0)
+	for _, f := range a.Frames {
+		total += f.Duration
+	}
+	return total
+}
+
+// IAnimationTarget is something that can show an AnimationFrame, such as a Sprite or UIImage
+type IAnimationTarget interface {
+	SetAnimationFrame(AnimationFrame)
+}
+
+// SetAnimationFrame implements the IAnimationTarget interface, the Size is not changed
+func (s *Sprite) SetAnimationFrame(f AnimationFrame) {
+	s.Texture = f.Texture
+	s.UV = f.UV
+}
+
+// SetAnimationFrame implements the IAnimationTarget interface, the Texture is owned by the SpriteAnimation
+func (c *UIImage) SetAnimationFrame(f AnimationFrame) {
+	if c.Texture != f.Texture {
+		c.Delete()
+		c.Texture = f.Texture
+		c.sharedTexture = true
+	}
+	c.UV = f.UV
+}
+
+// AnimationFrameFunc is called when an Animator shows a new frame
+type AnimationFrameFunc func(animation *SpriteAnimation, frame int, event string)
+
+// AnimationFinishedFunc is called when an AnimationOnce reaches its last frame
+type AnimationFinishedFunc func(animation *SpriteAnimation)
+
+// Animator is a Component that plays SpriteAnimations on an IAnimationTarget
+type Animator struct {
+	Component
+
+	// Target shows the frames, if nil the Animator's Entity or its first IAnimationTarget Component is used
+	Target IAnimationTarget
+
+	// Speed is the playback rate, 2.0 plays twice as fast, halving the time each frame is shown
+	Speed float32
+
+	animations map[string]*SpriteAnimation
+	current    *SpriteAnimation
+	frame      int
+	time       float32
+	direction  int
+	playing    bool
+
+	frameFuncs    []AnimationFrameFunc
+	finishedFuncs []AnimationFinishedFunc
+}
+
+// NewAnimator returns a new Animator with no SpriteAnimations
+func NewAnimator(entity IEntity) *Animator {
+	a := &Animator{
+		Speed:      1.0,
+		animations: map[string]*SpriteAnimation{},
+		direction:  1,
+	}
+	a.Init(entity)
+	return a
+}
+
+// AddAnimation adds a SpriteAnimation that can be played by its Name
+func (a *Animator) AddAnimation(anim *SpriteAnimation) {
+	a.animations[anim.Name] = anim
+}
+
+// GetAnimation returns the SpriteAnimation with the given Name, or nil
+func (a *Animator) GetAnimation(name string) *SpriteAnimation {
+	return a.animations[name]
+}
+
+// RegisterFrameFunc adds a function called whenever a new frame is shown
+func (a *Animator) RegisterFrameFunc(fun AnimationFrameFunc) {
+	a.frameFuncs = append(a.frameFuncs, fun)
+}
+
+// RegisterFinishedFunc adds a function called when an AnimationOnce finishes
+func (a *Animator) RegisterFinishedFunc(fun AnimationFinishedFunc) {
+	a.finishedFuncs = append(a.finishedFuncs, fun)
+}
+
+// Play starts the named SpriteAnimation from its first frame, unless it is already playing
+func (a *Animator) Play(name string) error {
+	anim, found := a.animations[name]
+	if !found {
+		return fmt.Errorf("Animation [%v] not found", name)
+	}
+	if anim == a.current && a.playing {
+		return nil
+	}
+
+	a.current = anim
+	a.time = 0
+	a.direction = 1
+	a.playing = true
+	a.setFrame(0)
+	return nil
+}
+
+// Stop stops the current SpriteAnimation, leaving its current frame shown
+func (a *Animator) Stop() {
+	a.playing = false
+}
+
+// Resume continues the current SpriteAnimation after Stop
+func (a *Animator) Resume() {
+	if a.current != nil {
+		a.playing = true
+	}
+}
+
+// IsPlaying returns whether a SpriteAnimation is playing
+func (a *Animator) IsPlaying() bool {
+	return a.playing
+}
+
+// GetCurrent returns the current SpriteAnimation, or nil
+func (a *Animator) GetCurrent() *SpriteAnimation {
+	return a.current
+}
+
+// GetFrame returns the index of the current frame
+func (a *Animator) GetFrame() int {
+	return a.frame
+}
+
+// Update implements the Component interface
+func (a *Animator) Update(ctx *UpdateContext) {
+	if !a.playing || a.current == nil || len(a.current.Frames) == 0 {
+		return
+	}
+
+	a.time += ctx.DeltaSeconds() * a.Speed
+
+	// A long update can skip several frames, but never more than one pass through the animation
+	for steps := 0; a.playing && steps < len(a.current.Frames); steps++ {
+		d := a.current.Frames[a.frame].Duration
+		if a.time < d {
+			break
+		}
+		a.time -= d
+		a.advance()
+	}
+}
+
+// advance moves to the next frame according to the current AnimationMode
+func (a *Animator) advance() {
+	n := len(a.current.Frames)
+	next := a.frame + a.direction
+
+	switch a.current.Mode {
+	case AnimationLoop:
+		next = (a.frame + 1) % n
+	case AnimationPingPong:
+		if next < 0 || next >= n {
+			a.direction = -a.direction
+			next = a.frame + a.direction
+		}
+		if next < 0 || next >= n {
+			next = a.frame
+		}
+	case AnimationOnce:
+		if next >= n {
+			a.playing = false
+			a.time = 0
+			for _, f := range a.finishedFuncs {
+				f(a.current)
+			}
+			return
+		}
+	}
+
+	a.setFrame(next)
+}
+
+// setFrame shows a frame on the target and calls the FrameFuncs
+func (a *Animator) setFrame(i int) {
+	a.frame = i
+	f := a.current.Frames[i]
+
+	if t := a.getTarget(); t != nil {
+		t.SetAnimationFrame(f)
+	}
+
+	for _, fun := range a.frameFuncs {
+		fun(a.current, i, f.Event)
+	}
+}
+
+func (a *Animator) getTarget() IAnimationTarget {
+	if a.Target != nil {
+		return a.Target
+	}
+
+	e := a.GetEntity()
+	if e == nil {
+		return nil
+	}
+	if t, ok := e.(IAnimationTarget); ok {
+		return t
+	}
+	for _, c := range e.GetComponents() {
+		if t, ok := c.(IAnimationTarget); ok {
+			return t
+		}
+	}
+	return nil
+}
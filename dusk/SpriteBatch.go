@@ -0,0 +1,412 @@
+package dusk
+
+import (
+	"C"
+	"sort"
+
+	"github.com/WhoBrokeTheBuild/GoDusk/m32"
+
+	gl "github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	spriteShaderVert = `
+#include <mvp.inc.glsl>
+
+layout(location = ATTR_POSITION) in vec3 _Position;
+layout(location = ATTR_TEXCOORD) in vec2 _TexCoord;
+layout(location = ATTR_COLOR)    in vec4 _VertexColor;
+
+out vec2 p_TexCoord;
+out vec4 p_Color;
+
+void main() {
+    p_TexCoord = _TexCoord;
+    p_Color = _VertexColor;
+
+    gl_Position = uProjection * uView * vec4(_Position, 1.0);
+}
+`
+	spriteShaderFrag = `
+uniform sampler2D uTexture;
+
+in vec2 p_TexCoord;
+in vec4 p_Color;
+
+out vec4 _Color;
+
+void main() {
+    _Color = texture(uTexture, p_TexCoord) * p_Color;
+}
+`
+)
+
+// SpriteShader draws the quads of a SpriteBatch
+type SpriteShader struct {
+	Shader
+}
+
+var _spriteShader *SpriteShader
+
+// GetSpriteShader returns an instance of the SpriteShader
+func GetSpriteShader() *SpriteShader {
+	if _spriteShader != nil {
+		return _spriteShader
+	}
+	Loadf("Loading Sprite Shader")
+	_spriteShader = &SpriteShader{}
+	_spriteShader.InitFromData(
+		&ShaderData{
+			Code: spriteShaderVert,
+			Type: gl.VERTEX_SHADER,
+		},
+		&ShaderData{
+			Code: spriteShaderFrag,
+			Type: gl.FRAGMENT_SHADER,
+		},
+	)
+	return _spriteShader
+}
+
+// Bind implements the Shader interface
+func (s *SpriteShader) Bind(ctx *RenderContext, data interface{}) {
+	s.Shader.Bind(ctx, data)
+
+	view := mgl32.Ident4()
+	if ctx.Camera != nil {
+		view = ctx.Camera.View
+	}
+
+	gl.UniformMatrix4fv(s.UniformLocation("uProjection"), 1, false, &ctx.Projection[0])
+	gl.UniformMatrix4fv(s.UniformLocation("uView"), 1, false, &view[0])
+	gl.Uniform1i(s.UniformLocation("uTexture"), 0)
+}
+
+// SpriteQuad is a single textured quad drawn by a SpriteBatch
+type SpriteQuad struct {
+	Texture *Texture
+
+	Position mgl32.Vec2
+	// Size is the size of the quad before Scale is applied
+	Size  mgl32.Vec2
+	Scale mgl32.Vec2
+	// Rotation is in radians, counter-clockwise around the Origin
+	Rotation float32
+	// Origin is the point of the quad placed at Position, from (0, 0) at the bottom left to (1, 1) at the top right
+	Origin mgl32.Vec2
+
+	// UV is the area of the Texture to draw as (left, top, right, bottom), with (0, 0) at the top left of the image
+	UV mgl32.Vec4
+
+	FlipX bool
+	FlipY bool
+
+	Tint mgl32.Vec4
+
+	// Depth orders the quads, higher values are drawn first and appear behind lower ones
+	Depth float32
+}
+
+// NewSpriteQuad returns a SpriteQuad that draws the whole Texture at its own size
+func NewSpriteQuad(texture *Texture, position mgl32.Vec2) SpriteQuad {
+	q := SpriteQuad{
+		Texture:  texture,
+		Position: position,
+		Scale:    mgl32.Vec2{1, 1},
+		UV:       mgl32.Vec4{0, 0, 1, 1},
+		Tint:     mgl32.Vec4{1, 1, 1, 1},
+	}
+	if texture != nil {
+		q.Size = texture.Size
+	}
+	return q
+}
+
+// spriteVertexSize is the number of floats in each vertex: position (3), texcoord (2), color (4)
+const spriteVertexSize = 9
+
+// SpriteBatch collects textured quads and draws them with one draw call per run of quads that share a Texture
+type SpriteBatch struct {
+	// SortByDepth sorts quads by Depth before drawing, quads with the same Depth are drawn in the order they were added
+	SortByDepth bool
+
+	quads    []SpriteQuad
+	vertices []float32
+
+	vao      uint32
+	vbo      uint32
+	ibo      uint32
+	capacity int
+}
+
+// NewSpriteBatch returns a new, empty SpriteBatch
+func NewSpriteBatch() *SpriteBatch {
+	const F = C.sizeof_float
+	const stride = spriteVertexSize * F
+
+	b := &SpriteBatch{
+		SortByDepth: true,
+		quads:       []SpriteQuad{},
+		vertices:    []float32{},
+	}
+
+	gl.GenVertexArrays(1, &b.vao)
+	gl.BindVertexArray(b.vao)
+
+	gl.GenBuffers(1, &b.vbo)
+	gl.BindBuffer(gl.ARRAY_BUFFER, b.vbo)
+
+	gl.EnableVertexAttribArray(PositionAttrID)
+	gl.VertexAttribPointer(PositionAttrID, 3, gl.FLOAT, false, stride, gl.PtrOffset(0))
+	gl.EnableVertexAttribArray(TexCoordAttrID)
+	gl.VertexAttribPointer(TexCoordAttrID, 2, gl.FLOAT, false, stride, gl.PtrOffset(3*F))
+	gl.EnableVertexAttribArray(ColorAttrID)
+	gl.VertexAttribPointer(ColorAttrID, 4, gl.FLOAT, false, stride, gl.PtrOffset(5*F))
+
+	// The index buffer is bound to the Vertex Array, and is filled when the capacity grows
+	gl.GenBuffers(1, &b.ibo)
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, b.ibo)
+
+	gl.BindVertexArray(0)
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+	return b
+}
+
+// Delete frees all resources owned by the SpriteBatch
+func (b *SpriteBatch) Delete() {
+	if b.ibo != InvalidID {
+		gl.DeleteBuffers(1, &b.ibo)
+		b.ibo = InvalidID
+	}
+	if b.vbo != InvalidID {
+		gl.DeleteBuffers(1, &b.vbo)
+		b.vbo = InvalidID
+	}
+	if b.vao != InvalidID {
+		gl.DeleteVertexArrays(1, &b.vao)
+		b.vao = InvalidID
+	}
+	b.capacity = 0
+	b.quads = b.quads[:0]
+}
+
+// Count returns the number of quads waiting to be drawn
+func (b *SpriteBatch) Count() int {
+	return len(b.quads)
+}
+
+// Draw adds a quad to be drawn on the next Flush
+func (b *SpriteBatch) Draw(q SpriteQuad) {
+	if q.Texture == nil {
+		return
+	}
+	b.quads = append(b.quads, q)
+}
+
+// Flush draws all quads and empties the batch
+func (b *SpriteBatch) Flush(ctx *RenderContext) {
+	if len(b.quads) == 0 {
+		return
+	}
+
+	if b.SortByDepth {
+		// Quads with the same Depth keep their order, so that overlapping quads are not reordered by Texture
+		sort.SliceStable(b.quads, func(i, j int) bool {
+			return b.quads[i].Depth > b.quads[j].Depth
+		})
+	}
+
+	b.vertices = b.vertices[:0]
+	for i := range b.quads {
+		b.vertices = appendSpriteVertices(b.vertices, &b.quads[i])
+	}
+	b.upload()
+
+	depthTest := gl.IsEnabled(gl.DEPTH_TEST)
+	gl.Disable(gl.DEPTH_TEST)
+
+	s := GetSpriteShader()
+	s.Bind(ctx, nil)
+
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindVertexArray(b.vao)
+
+	const I = C.sizeof_uint
+	start := 0
+	for i := 1; i <= len(b.quads); i++ {
+		if i < len(b.quads) && b.quads[i].Texture.ID == b.quads[start].Texture.ID {
+			continue
+		}
+
+		b.quads[start].Texture.Bind()
+		gl.DrawElements(gl.TRIANGLES, int32((i-start)*6), gl.UNSIGNED_INT, gl.PtrOffset(start*6*I))
+		if ctx.Queue != nil {
+			ctx.Queue.Stats.DrawCalls++
+		}
+		start = i
+	}
+
+	gl.BindVertexArray(0)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+	if depthTest {
+		gl.Enable(gl.DEPTH_TEST)
+	}
+
+	b.quads = b.quads[:0]
+}
+
+// upload copies the vertices to the GPU, growing the buffers if needed
+func (b *SpriteBatch) upload() {
+	const F = C.sizeof_float
+	const I = C.sizeof_uint
+
+	count := len(b.quads)
+	gl.BindBuffer(gl.ARRAY_BUFFER, b.vbo)
+	if count > b.capacity {
+		b.capacity = count * 2
+
+		gl.BufferData(gl.ARRAY_BUFFER, b.capacity*4*spriteVertexSize*F, nil, gl.DYNAMIC_DRAW)
+
+		// Every quad uses the same pattern of indices, so they only change when the capacity does
+		indices := make([]uint32, 0, b.capacity*6)
+		for i := 0; i < b.capacity; i++ {
+			v := uint32(i * 4)
+			indices = append(indices, v, v+1, v+2, v, v+2, v+3)
+		}
+		gl.BindVertexArray(b.vao)
+		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, b.ibo)
+		gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*I, gl.Ptr(indices), gl.STATIC_DRAW)
+		gl.BindVertexArray(0)
+	}
+	gl.BufferSubData(gl.ARRAY_BUFFER, 0, len(b.vertices)*F, gl.Ptr(b.vertices))
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+}
+
+// appendSpriteVertices appends the four corners of a quad, counter-clockwise from the bottom left
+func appendSpriteVertices(buf []float32, q *SpriteQuad) []float32 {
+	w := q.Size.X() * q.Scale.X()
+	h := q.Size.Y() * q.Scale.Y()
+	ox := q.Origin.X() * w
+	oy := q.Origin.Y() * h
+
+	corners := [4]mgl32.Vec2{
+		{-ox, -oy},
+		{w - ox, -oy},
+		{w - ox, h - oy},
+		{-ox, h - oy},
+	}
+
+	// The top of the image is drawn at the top of the quad
+	u0, v0, u1, v1 := q.UV[0], q.UV[1], q.UV[2], q.UV[3]
+	if q.FlipX {
+		u0, u1 = u1, u0
+	}
+	if q.FlipY {
+		v0, v1 = v1, v0
+	}
+	uvs := [4]mgl32.Vec2{
+		{u0, v1},
+		{u1, v1},
+		{u1, v0},
+		{u0, v0},
+	}
+
+	rot := mgl32.Rotate2D(q.Rotation)
+	for i, c := range corners {
+		p := rot.Mul2x1(c).Add(q.Position)
+		buf = append(buf,
+			p.X(), p.Y(), 0,
+			uvs[i].X(), uvs[i].Y(),
+			q.Tint[0], q.Tint[1], q.Tint[2], q.Tint[3])
+	}
+	return buf
+}
+
+var _spriteBatch *SpriteBatch
+
+// getSpriteBatch returns a shared SpriteBatch, for drawing when a RenderContext has none
+func getSpriteBatch() *SpriteBatch {
+	if _spriteBatch == nil {
+		_spriteBatch = NewSpriteBatch()
+	}
+	return _spriteBatch
+}
+
+// drawSprite adds a quad to the RenderContext's SpriteBatch, or draws it immediately if there is none
+func drawSprite(ctx *RenderContext, q SpriteQuad) {
+	if ctx.Sprites != nil {
+		ctx.Sprites.Draw(q)
+		return
+	}
+
+	b := getSpriteBatch()
+	b.Draw(q)
+	b.Flush(ctx)
+}
+
+// Sprite is a Component that draws a Texture at its Entity's position, rotation around Z, and scale
+type Sprite struct {
+	Component
+
+	Texture *Texture
+
+	// Size is the size of the Sprite in world units, before the Entity's scale
+	Size   mgl32.Vec2
+	Origin mgl32.Vec2
+	UV     mgl32.Vec4
+	Tint   mgl32.Vec4
+	Depth  float32
+
+	FlipX bool
+	FlipY bool
+
+	Visible bool
+}
+
+// NewSprite returns a new Sprite of the whole Texture, centered on its Entity and sized in pixels
+func NewSprite(entity IEntity, texture *Texture) *Sprite {
+	s := &Sprite{
+		Texture: texture,
+		Origin:  mgl32.Vec2{0.5, 0.5},
+		UV:      mgl32.Vec4{0, 0, 1, 1},
+		Tint:    mgl32.Vec4{1, 1, 1, 1},
+		Visible: true,
+	}
+	if texture != nil {
+		s.Size = texture.Size
+	}
+	s.Init(entity)
+	return s
+}
+
+// Render implements the Component interface
+func (s *Sprite) Render(ctx *RenderContext) {
+	if ctx.Pass != ColorPass || !s.Visible || s.Texture == nil {
+		return
+	}
+
+	q := SpriteQuad{
+		Texture: s.Texture,
+		Size:    s.Size,
+		Scale:   mgl32.Vec2{1, 1},
+		Origin:  s.Origin,
+		UV:      s.UV,
+		FlipX:   s.FlipX,
+		FlipY:   s.FlipY,
+		Tint:    s.Tint,
+		Depth:   s.Depth,
+	}
+	if e := s.GetEntity(); e != nil && e.Transform() != nil {
+		// The world matrix includes any Parents, and is flattened to a 2D position, rotation and scale
+		world := e.Transform().GetWorldMatrix()
+		x, y := world.Col(0).Vec2(), world.Col(1).Vec2()
+		q.Position = world.Col(3).Vec2()
+		q.Rotation = m32.Atan2(x.Y(), x.X())
+		q.Scale = mgl32.Vec2{x.Len(), y.Len()}
+		if x.X()*y.Y()-x.Y()*y.X() < 0 {
+			q.Scale[1] = -q.Scale[1]
+		}
+	}
+	drawSprite(ctx, q)
+}
@@ -0,0 +1,207 @@
+package dusk
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"path/filepath"
+	"sort"
+
+	"github.com/WhoBrokeTheBuild/GoDusk/dusk/atlas"
+
+	gl "github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// AtlasRegion is a named area of a TextureAtlas
+type AtlasRegion struct {
+	Name string
+	// UV is the area as (left, top, right, bottom), from 0 to 1
+	UV mgl32.Vec4
+	// Size is the size of the area in pixels
+	Size mgl32.Vec2
+}
+
+// TextureAtlas is a Texture containing many images, each in a named AtlasRegion
+type TextureAtlas struct {
+	Texture *Texture
+	Regions map[string]AtlasRegion
+}
+
+// NewTextureAtlasFromFile returns a new TextureAtlas from a JSON sprite sheet, the image is loaded from the same directory
+func NewTextureAtlasFromFile(filename string) (*TextureAtlas, error) {
+	a := &TextureAtlas{}
+	err := a.LoadFromFile(filename)
+	if err != nil {
+		a.Delete()
+		return nil, err
+	}
+	return a, nil
+}
+
+// NewTextureAtlasFromImages returns a new TextureAtlas with the given image files packed together, each region is named after its file
+func NewTextureAtlasFromImages(files []string, maxSize int) (*TextureAtlas, error) {
+	a := &TextureAtlas{}
+	err := a.LoadFromImages(files, maxSize)
+	if err != nil {
+		a.Delete()
+		return nil, err
+	}
+	return a, nil
+}
+
+// Delete frees all resources owned by the TextureAtlas
+func (a *TextureAtlas) Delete() {
+	if a.Texture != nil {
+		a.Texture.Delete()
+		a.Texture = nil
+	}
+	a.Regions = map[string]AtlasRegion{}
+}
+
+// LoadFromFile loads a TextureAtlas from a JSON sprite sheet, the image is loaded from the same directory
+func (a *TextureAtlas) LoadFromFile(filename string) error {
+	a.Delete()
+	filename = filepath.Clean(filename)
+
+	Loadf("asset.TextureAtlas [%v]", filename)
+	b, err := Load(filename)
+	if err != nil {
+		return err
+	}
+
+	meta, err := atlas.Parse(b)
+	if err != nil {
+		return fmt.Errorf("Failed to parse [%v]: %v", filename, err)
+	}
+
+	a.Texture, err = NewTextureFromFile(filepath.Join(filepath.Dir(filename), meta.Meta.Image))
+	if err != nil {
+		return err
+	}
+
+	a.setRegions(meta)
+	return nil
+}
+
+// LoadFromImages packs the given image files into a TextureAtlas, each region is named after its file
+func (a *TextureAtlas) LoadFromImages(files []string, maxSize int) error {
+	a.Delete()
+
+	images := make([]atlas.Image, 0, len(files))
+	for _, filename := range files {
+		filename = filepath.Clean(filename)
+
+		Loadf("asset.TextureAtlas [%v]", filename)
+		b, err := Load(filename)
+		if err != nil {
+			return err
+		}
+
+		img, _, err := image.Decode(bytes.NewReader(b))
+		if err != nil {
+			return fmt.Errorf("Failed to decode [%v]: %v", filename, err)
+		}
+		images = append(images, atlas.Image{
+			Name:  filepath.Base(filename),
+			Image: img,
+		})
+	}
+
+	img, meta, err := atlas.Pack(images, maxSize, 1)
+	if err != nil {
+		return err
+	}
+
+	size := img.Bounds().Size()
+	a.Texture, err = NewTextureFromData(img.Pix, gl.RGBA, gl.RGBA, size.X, size.Y)
+	if err != nil {
+		return err
+	}
+
+	a.setRegions(meta)
+	return nil
+}
+
+func (a *TextureAtlas) setRegions(meta *atlas.Atlas) {
+	w := a.Texture.Size.X()
+	h := a.Texture.Size.Y()
+	if meta.Meta.Size.W > 0 && meta.Meta.Size.H > 0 {
+		w = float32(meta.Meta.Size.W)
+		h = float32(meta.Meta.Size.H)
+	}
+
+	a.Regions = map[string]AtlasRegion{}
+	for name, f := range meta.Frames {
+		if f.Rotated {
+			Warnf("Atlas region [%v] is rotated, which is not supported", name)
+		}
+		r := f.Frame
+		a.Regions[name] = AtlasRegion{
+			Name: name,
+			UV: mgl32.Vec4{
+				float32(r.X) / w,
+				float32(r.Y) / h,
+				float32(r.X+r.W) / w,
+				float32(r.Y+r.H) / h,
+			},
+			Size: mgl32.Vec2{float32(r.W), float32(r.H)},
+		}
+	}
+}
+
+// GetRegion returns the AtlasRegion with the given name
+func (a *TextureAtlas) GetRegion(name string) (AtlasRegion, bool) {
+	r, found := a.Regions[name]
+	return r, found
+}
+
+// GetRegionNames returns the names of all regions, sorted
+func (a *TextureAtlas) GetRegionNames() []string {
+	names := make([]string, 0, len(a.Regions))
+	for name := range a.Regions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetRegion changes the Sprite to draw a region of the TextureAtlas at its size in pixels
+func (s *Sprite) SetRegion(a *TextureAtlas, name string) error {
+	r, found := a.GetRegion(name)
+	if !found {
+		return fmt.Errorf("Atlas region [%v] not found", name)
+	}
+	s.Texture = a.Texture
+	s.UV = r.UV
+	s.Size = r.Size
+	return nil
+}
+
+// NewUIImageFromAtlas returns a new UIImage that draws a region of the TextureAtlas, the Texture is owned by the TextureAtlas
+func NewUIImageFromAtlas(layer ILayer, a *TextureAtlas, name string) *UIImage {
+	c := &UIImage{}
+	c.Init(layer)
+
+	err := c.SetRegion(a, name)
+	if err != nil {
+		Errorf("%v", err)
+		return nil
+	}
+	return c
+}
+
+// SetRegion changes the UIImage to draw a region of the TextureAtlas at its size in pixels, the Texture is owned by the TextureAtlas
+func (c *UIImage) SetRegion(a *TextureAtlas, name string) error {
+	r, found := a.GetRegion(name)
+	if !found {
+		return fmt.Errorf("Atlas region [%v] not found", name)
+	}
+
+	c.Delete()
+	c.Texture = a.Texture
+	c.UV = r.UV
+	c.sharedTexture = true
+	c.SetSize(r.Size)
+	return nil
+}
@@ -1,9 +1,15 @@
 package dusk
 
-import "github.com/go-gl/mathgl/mgl32"
+import (
+	"github.com/WhoBrokeTheBuild/GoDusk/m32"
+	"github.com/go-gl/mathgl/mgl32"
+)
 
 // Transform represents a position, rotation, and scale
 type Transform struct {
+	// Parent is an optional Transform that this one is relative to, such as for a node of a Scene
+	Parent *Transform
+
 	Position mgl32.Vec3
 	Rotation mgl32.Vec3
 	Scale    mgl32.Vec3
@@ -27,3 +33,86 @@ func (t *Transform) GetMatrix() mgl32.Mat4 {
 		Mul4(mgl32.HomogRotate3DZ(t.Rotation[2])).
 		Mul4(mgl32.Scale3D(t.Scale[0], t.Scale[1], t.Scale[2]))
 }
+
+// GetWorldMatrix returns the calculated 4x4 Matrix, including all Parents
+func (t *Transform) GetWorldMatrix() mgl32.Mat4 {
+	if t.Parent == nil {
+		return t.GetMatrix()
+	}
+	return t.Parent.GetWorldMatrix().Mul4(t.GetMatrix())
+}
+
+// GetWorldPosition returns the Position, including all Parents
+func (t *Transform) GetWorldPosition() mgl32.Vec3 {
+	if t.Parent == nil {
+		return t.Position
+	}
+	return mgl32.TransformCoordinate(t.Position, t.Parent.GetWorldMatrix())
+}
+
+// SetWorldPosition sets the Position so that the Transform is at pos, including all Parents
+func (t *Transform) SetWorldPosition(pos mgl32.Vec3) {
+	if t.Parent == nil {
+		t.Position = pos
+		return
+	}
+	t.Position = mgl32.TransformCoordinate(pos, t.Parent.GetWorldMatrix().Inv())
+}
+
+// LookAt sets the Rotation so that the Transform's forward axis (-Z) points at target
+// target and up are in world space, and are converted into the space of the Parent
+func (t *Transform) LookAt(target, up mgl32.Vec3) {
+	if t.Parent != nil {
+		inv := t.Parent.GetWorldMatrix().Inv()
+		target = mgl32.TransformCoordinate(target, inv)
+		up = mgl32.TransformNormal(up, inv)
+	}
+	if target.Sub(t.Position).Len() == 0 {
+		return
+	}
+
+	// The rotation is the inverse of a view matrix looking from Position to target
+	view := mgl32.LookAtV(t.Position, target, up)
+	t.Rotation = eulerFromMatrix(view.Mat3().Transpose())
+}
+
+// eulerFromMatrix returns the X, Y, Z angles of a rotation matrix composed as Rx * Ry * Rz, matching GetMatrix
+func eulerFromMatrix(m mgl32.Mat3) mgl32.Vec3 {
+	sy := m32.Max(-1, m32.Min(1, m.At(0, 2)))
+	y := m32.Asin(sy)
+
+	// Away from gimbal lock, the X and Z angles can be separated
+	if m32.Abs(sy) < 0.9999 {
+		return mgl32.Vec3{
+			m32.Atan2(-m.At(1, 2), m.At(2, 2)),
+			y,
+			m32.Atan2(-m.At(0, 1), m.At(0, 0)),
+		}
+	}
+	return mgl32.Vec3{
+		m32.Atan2(m.At(2, 1), m.At(1, 1)),
+		y,
+		0,
+	}
+}
+
+// DecomposeMatrix splits a matrix without shear into its translation, rotation and scale
+func DecomposeMatrix(m mgl32.Mat4) (mgl32.Vec3, mgl32.Quat, mgl32.Vec3) {
+	translation := m.Col(3).Vec3()
+	scale := mgl32.Vec3{m.Col(0).Vec3().Len(), m.Col(1).Vec3().Len(), m.Col(2).Vec3().Len()}
+
+	// A mirrored matrix needs one negative scale for the rest to be a rotation
+	if m.Mat3().Det() < 0 {
+		scale[0] = -scale[0]
+	}
+
+	r := mgl32.Mat3{}
+	for i := 0; i < 3; i++ {
+		col := m.Col(i).Vec3()
+		if scale[i] != 0 {
+			col = col.Mul(1 / scale[i])
+		}
+		r.SetCol(i, col)
+	}
+	return translation, mgl32.Mat4ToQuat(r.Mat4()).Normalize(), scale
+}
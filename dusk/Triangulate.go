@@ -0,0 +1,130 @@
+package dusk
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/WhoBrokeTheBuild/GoDusk/m32"
+)
+
+// TriangulatePolygon splits a polygon into triangles by ear clipping, returning the indices of the points of each triangle
+// The polygon can be concave but not self-intersecting, and the triangles keep its winding order
+func TriangulatePolygon(points []mgl32.Vec3) [][3]int {
+	n := len(points)
+	if n < 3 {
+		return nil
+	}
+	if n == 3 {
+		return [][3]int{{0, 1, 2}}
+	}
+
+	idx := make([]int, n)
+	for i := range idx {
+		idx[i] = i
+	}
+
+	flat, ok := flattenPolygon(points)
+	if !ok {
+		return fanPolygon(idx)
+	}
+
+	tris := make([][3]int, 0, n-2)
+	for len(idx) > 3 {
+		ear := -1
+		for i := range idx {
+			a, b, c := idx[(i+len(idx)-1)%len(idx)], idx[i], idx[(i+1)%len(idx)]
+			if cross2D(flat[a], flat[b], flat[c]) <= 0 {
+				continue
+			}
+
+			inside := false
+			for _, j := range idx {
+				if j != a && j != b && j != c && pointInTriangle2D(flat[j], flat[a], flat[b], flat[c]) {
+					inside = true
+					break
+				}
+			}
+			if !inside {
+				ear = i
+				tris = append(tris, [3]int{a, b, c})
+				break
+			}
+		}
+
+		// Without an ear, drop a point with no area around it, such as one on a straight edge
+		if ear < 0 {
+			for i := range idx {
+				a, b, c := idx[(i+len(idx)-1)%len(idx)], idx[i], idx[(i+1)%len(idx)]
+				if m32.Abs(cross2D(flat[a], flat[b], flat[c])) <= 1e-12 {
+					ear = i
+					break
+				}
+			}
+		}
+
+		// The polygon intersects itself, so the rest is only approximated
+		if ear < 0 {
+			return append(tris, fanPolygon(idx)...)
+		}
+
+		idx = append(idx[:ear], idx[ear+1:]...)
+	}
+
+	return append(tris, [3]int{idx[0], idx[1], idx[2]})
+}
+
+// flattenPolygon projects the points onto the plane of the polygon, oriented so that it winds counter-clockwise
+func flattenPolygon(points []mgl32.Vec3) ([]mgl32.Vec2, bool) {
+	// Newell's method gives a normal even for concave polygons
+	normal := mgl32.Vec3{}
+	for i := range points {
+		a, b := points[i], points[(i+1)%len(points)]
+		normal[0] += (a[1] - b[1]) * (a[2] + b[2])
+		normal[1] += (a[2] - b[2]) * (a[0] + b[0])
+		normal[2] += (a[0] - b[0]) * (a[1] + b[1])
+	}
+	if normal.Len() == 0 {
+		return nil, false
+	}
+	normal = normal.Normalize()
+
+	u := normal.Cross(mgl32.Vec3{1, 0, 0})
+	if m32.Abs(normal[0]) > 0.9 {
+		u = normal.Cross(mgl32.Vec3{0, 1, 0})
+	}
+	u = u.Normalize()
+	v := normal.Cross(u)
+
+	flat := make([]mgl32.Vec2, len(points))
+	for i, p := range points {
+		flat[i] = mgl32.Vec2{p.Dot(u), p.Dot(v)}
+	}
+	return flat, true
+}
+
+// fanPolygon splits the polygon into triangles that all share its first point
+func fanPolygon(idx []int) [][3]int {
+	tris := make([][3]int, 0, len(idx)-2)
+	for i := 1; i+1 < len(idx); i++ {
+		tris = append(tris, [3]int{idx[0], idx[i], idx[i+1]})
+	}
+	return tris
+}
+
+// cross2D returns twice the signed area of a triangle, which is positive when it winds counter-clockwise
+func cross2D(a, b, c mgl32.Vec2) float32 {
+	return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+}
+
+// pointInTriangle2D returns whether p is inside or on an edge of the counter-clockwise triangle a, b, c
+// Points at the same position as a corner are not counted, so that repeated points do not block every ear
+// Points within a small distance of an edge count as on it, since flattening can move them to either side
+func pointInTriangle2D(p, a, b, c mgl32.Vec2) bool {
+	if p == a || p == b || p == c {
+		return false
+	}
+	inside := func(a, b mgl32.Vec2) bool {
+		e := b.Sub(a)
+		return cross2D(a, b, p) >= -1e-5*e.Dot(e)
+	}
+	return inside(a, b) && inside(b, c) && inside(c, a)
+}
@@ -0,0 +1,75 @@
+package dusk
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// polygonNormal returns the normal of a polygon by Newell's method, with a length of twice its area
+func polygonNormal(points []mgl32.Vec3) mgl32.Vec3 {
+	normal := mgl32.Vec3{}
+	for i := range points {
+		a, b := points[i], points[(i+1)%len(points)]
+		normal = normal.Add(a.Cross(b))
+	}
+	return normal
+}
+
+func TestTriangulatePolygon(t *testing.T) {
+	// An L shape, which is concave at (1, 1)
+	l := []mgl32.Vec3{{0, 0, 0}, {2, 0, 0}, {2, 1, 0}, {1, 1, 0}, {1, 2, 0}, {0, 2, 0}}
+
+	// The L shape tilted out of every axis plane
+	tilt := mgl32.HomogRotate3D(0.7, mgl32.Vec3{1, 2, 3}.Normalize())
+	tilted := make([]mgl32.Vec3, len(l))
+	for i, p := range l {
+		tilted[i] = mgl32.TransformCoordinate(p, tilt)
+	}
+
+	tests := []struct {
+		name      string
+		points    []mgl32.Vec3
+		triangles int
+	}{
+		{"too few points", []mgl32.Vec3{{0, 0, 0}, {1, 0, 0}}, 0},
+		{"triangle", []mgl32.Vec3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}, 1},
+		{"convex quad", []mgl32.Vec3{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}}, 2},
+		{"concave L", l, 4},
+		{"tilted concave L", tilted, 4},
+		{"collinear points", []mgl32.Vec3{{0, 0, 0}, {1, 0, 0}, {2, 0, 0}, {2, 2, 0}, {1, 2, 0}, {0, 2, 0}}, 4},
+		{"clockwise", []mgl32.Vec3{{0, 1, 0}, {1, 1, 0}, {1, 0, 0}, {0, 0, 0}}, 2},
+		{"clockwise concave L", []mgl32.Vec3{{0, 2, 0}, {1, 2, 0}, {1, 1, 0}, {2, 1, 0}, {2, 0, 0}, {0, 0, 0}}, 4},
+		{"zero area", []mgl32.Vec3{{0, 0, 0}, {1, 0, 0}, {2, 0, 0}, {3, 0, 0}}, 2},
+	}
+
+	for _, test := range tests {
+		tris := TriangulatePolygon(test.points)
+		if len(tris) != test.triangles {
+			t.Errorf("%v: %d triangles, expected %d", test.name, len(tris), test.triangles)
+			continue
+		}
+
+		normal := polygonNormal(test.points)
+		area := float32(0)
+		for _, tri := range tris {
+			for _, i := range tri {
+				if i < 0 || i >= len(test.points) {
+					t.Fatalf("%v: Triangle %v has an invalid index", test.name, tri)
+				}
+			}
+
+			a, b, c := test.points[tri[0]], test.points[tri[1]], test.points[tri[2]]
+			n := b.Sub(a).Cross(c.Sub(a))
+			if normal.Len() > 0 && n.Dot(normal) <= 0 {
+				t.Errorf("%v: Triangle %v does not keep the polygon's winding", test.name, tri)
+			}
+			area += n.Len() / 2
+		}
+
+		// The triangles cover the polygon without overlapping
+		if want := normal.Len() / 2; mgl32.Abs(area-want) > 1e-4 {
+			t.Errorf("%v: Triangles have an area of %v, expected %v", test.name, area, want)
+		}
+	}
+}
@@ -0,0 +1,467 @@
+package dusk
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// ITween is an animation that advances over time, such as a Tween, TweenSequence or TweenGroup
+type ITween interface {
+	// Advance moves the animation forward by seconds, and returns whether it has finished and how much of the time was left over
+	Advance(seconds float32) (float32, bool)
+	// Reset returns the animation to its start, so it can be played again
+	Reset()
+}
+
+// tweenCallbacks holds the functions called when an ITween finishes
+type tweenCallbacks struct {
+	completeFuncs []func()
+}
+
+// RegisterCompleteFunc adds a function called when the animation finishes, after all repeats
+func (c *tweenCallbacks) RegisterCompleteFunc(fun func()) {
+	c.completeFuncs = append(c.completeFuncs, fun)
+}
+
+func (c *tweenCallbacks) complete() {
+	for _, f := range c.completeFuncs {
+		f()
+	}
+}
+
+// Tween animates a value from 0 to 1 over a Duration, passing the eased progress to a function
+type Tween struct {
+	tweenCallbacks
+
+	// Duration is the length of one play in seconds
+	Duration float32
+	// Delay is the time in seconds to wait before starting
+	Delay float32
+	// Easing changes the speed of the animation over time, it defaults to EaseLinear
+	Easing EasingFunc
+	// Repeat is the number of extra times to play, or -1 to repeat forever
+	Repeat int
+	// Yoyo plays every other repeat backwards
+	Yoyo bool
+
+	apply func(float32)
+	// start is called when the Delay has passed, to capture the starting value
+	start func()
+
+	waited  float32
+	elapsed float32
+	count   int
+	started bool
+	done    bool
+}
+
+// NewTween returns a new Tween that calls apply with the eased progress, from 0 to 1
+func NewTween(duration float32, apply func(progress float32)) *Tween {
+	return &Tween{
+		Duration: duration,
+		Easing:   EaseLinear,
+		apply:    apply,
+	}
+}
+
+// NewTweenDelay returns a new Tween that does nothing for some seconds, for use in a TweenSequence
+func NewTweenDelay(seconds float32) *Tween {
+	return NewTween(seconds, nil)
+}
+
+// NewTweenCall returns a new Tween that calls fun once and finishes immediately, for use in a TweenSequence
+func NewTweenCall(fun func()) *Tween {
+	t := NewTween(0, nil)
+	t.start = fun
+	return t
+}
+
+// Advance implements the ITween interface
+func (t *Tween) Advance(seconds float32) (float32, bool) {
+	if t.done {
+		return seconds, true
+	}
+
+	if !t.started {
+		t.waited += seconds
+		if t.waited < t.Delay {
+			return 0, false
+		}
+		seconds = t.waited - t.Delay
+		t.started = true
+		if t.start != nil {
+			t.start()
+		}
+	}
+
+	t.elapsed += seconds
+	if t.Duration > 0 {
+		for t.elapsed >= t.Duration && (t.Repeat < 0 || t.count < t.Repeat) {
+			t.elapsed -= t.Duration
+			t.count++
+		}
+		if t.elapsed < t.Duration {
+			t.set(t.elapsed / t.Duration)
+			return 0, false
+		}
+	}
+
+	left := t.elapsed - t.Duration
+	if left < 0 {
+		left = 0
+	}
+	t.set(1)
+	t.done = true
+	t.complete()
+	return left, true
+}
+
+// Reset implements the ITween interface
+func (t *Tween) Reset() {
+	t.waited = 0
+	t.elapsed = 0
+	t.count = 0
+	t.started = false
+	t.done = false
+}
+
+// IsDone returns whether the Tween has finished
+func (t *Tween) IsDone() bool {
+	return t.done
+}
+
+// set applies the eased progress, reversed on every other repeat when Yoyo is set
+func (t *Tween) set(progress float32) {
+	if t.apply == nil {
+		return
+	}
+	if t.Yoyo && t.count%2 == 1 {
+		progress = 1 - progress
+	}
+	if t.Easing != nil {
+		progress = t.Easing(progress)
+	}
+	t.apply(progress)
+}
+
+// TweenFloat returns a new Tween that moves target from its value when the Tween starts to the given value
+func TweenFloat(target *float32, to float32, duration float32) *Tween {
+	var from float32
+	t := NewTween(duration, func(p float32) {
+		*target = from + (to-from)*p
+	})
+	t.start = func() {
+		from = *target
+	}
+	return t
+}
+
+// TweenVec2 returns a new Tween that moves target from its value when the Tween starts to the given value
+func TweenVec2(target *mgl32.Vec2, to mgl32.Vec2, duration float32) *Tween {
+	var from mgl32.Vec2
+	t := NewTween(duration, func(p float32) {
+		*target = from.Add(to.Sub(from).Mul(p))
+	})
+	t.start = func() {
+		from = *target
+	}
+	return t
+}
+
+// TweenVec3 returns a new Tween that moves target from its value when the Tween starts to the given value
+func TweenVec3(target *mgl32.Vec3, to mgl32.Vec3, duration float32) *Tween {
+	var from mgl32.Vec3
+	t := NewTween(duration, func(p float32) {
+		*target = from.Add(to.Sub(from).Mul(p))
+	})
+	t.start = func() {
+		from = *target
+	}
+	return t
+}
+
+// TweenVec4 returns a new Tween that moves target from its value when the Tween starts to the given value
+// It can be used with the colors of a Material, such as TweenVec4(&material.Diffuse, red, 1.0)
+func TweenVec4(target *mgl32.Vec4, to mgl32.Vec4, duration float32) *Tween {
+	var from mgl32.Vec4
+	t := NewTween(duration, func(p float32) {
+		*target = from.Add(to.Sub(from).Mul(p))
+	})
+	t.start = func() {
+		from = *target
+	}
+	return t
+}
+
+// TweenPosition returns a new Tween that moves an Entity to the given Position
+func TweenPosition(e IEntity, to mgl32.Vec3, duration float32) *Tween {
+	return TweenVec3(&e.Transform().Position, to, duration)
+}
+
+// TweenRotation returns a new Tween that turns an Entity to the given Rotation, in radians
+func TweenRotation(e IEntity, to mgl32.Vec3, duration float32) *Tween {
+	return TweenVec3(&e.Transform().Rotation, to, duration)
+}
+
+// TweenScale returns a new Tween that scales an Entity to the given Scale
+func TweenScale(e IEntity, to mgl32.Vec3, duration float32) *Tween {
+	return TweenVec3(&e.Transform().Scale, to, duration)
+}
+
+// TweenUIPosition returns a new Tween that moves a UIImage to the given position, in pixels
+func TweenUIPosition(c *UIImage, to mgl32.Vec2, duration float32) *Tween {
+	return TweenVec3(&c.Transform().Position, mgl32.Vec3{to.X(), to.Y(), 0}, duration)
+}
+
+// TweenUISize returns a new Tween that resizes a UIImage to the given size, in pixels
+func TweenUISize(c *UIImage, to mgl32.Vec2, duration float32) *Tween {
+	return TweenVec3(&c.Transform().Scale, mgl32.Vec3{to.X(), to.Y(), 0}, duration)
+}
+
+// TweenSequence plays ITweens one after another
+type TweenSequence struct {
+	tweenCallbacks
+
+	Tweens []ITween
+	// Repeat is the number of extra times to play the whole sequence, or -1 to repeat forever
+	Repeat int
+
+	index int
+	count int
+	done  bool
+}
+
+// NewTweenSequence returns a new TweenSequence of the given ITweens
+func NewTweenSequence(tweens ...ITween) *TweenSequence {
+	return &TweenSequence{
+		Tweens: tweens,
+	}
+}
+
+// Append adds ITweens to the end of the TweenSequence
+func (s *TweenSequence) Append(tweens ...ITween) {
+	s.Tweens = append(s.Tweens, tweens...)
+}
+
+// Advance implements the ITween interface
+func (s *TweenSequence) Advance(seconds float32) (float32, bool) {
+	if s.done {
+		return seconds, true
+	}
+
+	for {
+		start := seconds
+		for s.index < len(s.Tweens) {
+			left, done := s.Tweens[s.index].Advance(seconds)
+			if !done {
+				return 0, false
+			}
+			seconds = left
+			s.index++
+		}
+
+		if s.Repeat >= 0 && s.count >= s.Repeat {
+			break
+		}
+		s.count++
+		s.index = 0
+		for _, t := range s.Tweens {
+			t.Reset()
+		}
+
+		// Wait for the next update rather than repeating a sequence that takes no time forever
+		if seconds <= 0 || seconds >= start {
+			return 0, false
+		}
+	}
+
+	s.done = true
+	s.complete()
+	return seconds, true
+}
+
+// Reset implements the ITween interface
+func (s *TweenSequence) Reset() {
+	s.index = 0
+	s.count = 0
+	s.done = false
+	for _, t := range s.Tweens {
+		t.Reset()
+	}
+}
+
+// TweenGroup plays ITweens at the same time, and finishes when all of them have finished
+type TweenGroup struct {
+	tweenCallbacks
+
+	Tweens []ITween
+	// Repeat is the number of extra times to play the whole group, or -1 to repeat forever
+	Repeat int
+
+	finished []bool
+	count    int
+	done     bool
+}
+
+// NewTweenGroup returns a new TweenGroup of the given ITweens
+func NewTweenGroup(tweens ...ITween) *TweenGroup {
+	return &TweenGroup{
+		Tweens: tweens,
+	}
+}
+
+// Add adds ITweens to the TweenGroup
+func (g *TweenGroup) Add(tweens ...ITween) {
+	g.Tweens = append(g.Tweens, tweens...)
+}
+
+// Advance implements the ITween interface
+func (g *TweenGroup) Advance(seconds float32) (float32, bool) {
+	if g.done {
+		return seconds, true
+	}
+
+	for {
+		if len(g.finished) != len(g.Tweens) {
+			g.finished = append(g.finished, make([]bool, len(g.Tweens)-len(g.finished))...)
+		}
+
+		// The time left over is from whichever ITween finished last
+		start := seconds
+		left := seconds
+		all := true
+		for i, t := range g.Tweens {
+			if g.finished[i] {
+				continue
+			}
+			l, done := t.Advance(seconds)
+			if !done {
+				all = false
+				continue
+			}
+			g.finished[i] = true
+			if l < left {
+				left = l
+			}
+		}
+		if !all {
+			return 0, false
+		}
+		seconds = left
+
+		if g.Repeat >= 0 && g.count >= g.Repeat {
+			break
+		}
+		g.count++
+		g.resetTweens()
+
+		// Wait for the next update rather than repeating a group that takes no time forever
+		if seconds <= 0 || seconds >= start {
+			return 0, false
+		}
+	}
+
+	g.done = true
+	g.complete()
+	return seconds, true
+}
+
+// Reset implements the ITween interface
+func (g *TweenGroup) Reset() {
+	g.count = 0
+	g.done = false
+	g.resetTweens()
+}
+
+func (g *TweenGroup) resetTweens() {
+	g.finished = make([]bool, len(g.Tweens))
+	for _, t := range g.Tweens {
+		t.Reset()
+	}
+}
+
+// Tweener is a Component that plays ITweens, advancing them every Update
+type Tweener struct {
+	Component
+
+	// Speed multiplies the time passed to every ITween
+	Speed float32
+
+	tweens []ITween
+	// stopped are the ITweens stopped during the current Update
+	stopped []ITween
+}
+
+// NewTweener returns a new Tweener with nothing playing
+func NewTweener(entity IEntity) *Tweener {
+	t := &Tweener{
+		Speed:  1.0,
+		tweens: []ITween{},
+	}
+	t.Init(entity)
+	return t
+}
+
+// Play starts an ITween from the beginning, it is removed when it finishes
+func (t *Tweener) Play(tween ITween) {
+	t.Stop(tween)
+	tween.Reset()
+	t.tweens = append(t.tweens, tween)
+}
+
+// Stop removes an ITween, leaving its values where they are
+func (t *Tweener) Stop(tween ITween) {
+	t.stopped = append(t.stopped, tween)
+	for i, other := range t.tweens {
+		if other == tween {
+			t.tweens = append(t.tweens[:i], t.tweens[i+1:]...)
+			return
+		}
+	}
+}
+
+// StopAll removes every ITween
+func (t *Tweener) StopAll() {
+	t.stopped = append(t.stopped, t.tweens...)
+	t.tweens = []ITween{}
+}
+
+// IsPlaying returns whether an ITween is playing
+func (t *Tweener) IsPlaying(tween ITween) bool {
+	for _, other := range t.tweens {
+		if other == tween {
+			return true
+		}
+	}
+	return false
+}
+
+// Update implements the Component interface
+func (t *Tweener) Update(ctx *UpdateContext) {
+	seconds := ctx.DeltaSeconds() * t.Speed
+
+	// Complete functions can Play or Stop ITweens while these are advanced
+	playing := t.tweens
+	t.tweens = []ITween{}
+	t.stopped = t.stopped[:0]
+
+	running := make([]ITween, 0, len(playing))
+	for _, tween := range playing {
+		if t.wasStopped(tween) {
+			continue
+		}
+		if _, done := tween.Advance(seconds); !done && !t.wasStopped(tween) {
+			running = append(running, tween)
+		}
+	}
+	t.tweens = append(running, t.tweens...)
+}
+
+// wasStopped returns whether an ITween was stopped or played again during the current Update
+func (t *Tweener) wasStopped(tween ITween) bool {
+	for _, other := range t.stopped {
+		if other == tween {
+			return true
+		}
+	}
+	return false
+}
@@ -8,7 +8,6 @@ import (
 	// PNG support
 	_ "image/png"
 
-	gl "github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
@@ -17,7 +16,11 @@ type UIImage struct {
 	Entity
 	Bounds  mgl32.Vec4
 	Texture *Texture
-	Mesh    *Mesh
+	// UV is the area of the Texture to draw as (left, top, right, bottom), from 0 to 1
+	UV mgl32.Vec4
+
+	// sharedTexture is true when the Texture belongs to a TextureAtlas
+	sharedTexture bool
 }
 
 // NewUIImageFromFile returns a new UIImage from the given file
@@ -50,9 +53,12 @@ func NewUIImageFromData(layer ILayer, data []uint8, intFormat uint32, format int
 // Delete frees all resources owned by the UIImage
 func (c *UIImage) Delete() {
 	if c.Texture != nil {
-		c.Texture.Delete()
+		if !c.sharedTexture {
+			c.Texture.Delete()
+		}
 		c.Texture = nil
 	}
+	c.sharedTexture = false
 }
 
 // LoadFromFile loads an UIImage from the given file
@@ -66,6 +72,7 @@ func (c *UIImage) LoadFromFile(filename string) error {
 		return err
 	}
 
+	c.UV = mgl32.Vec4{0, 0, 1, 1}
 	c.SetSize(c.Texture.Size)
 
 	return nil
@@ -82,6 +89,7 @@ func (c *UIImage) LoadFromData(data []uint8, intFormat uint32, format int32, wid
 		return err
 	}
 
+	c.UV = mgl32.Vec4{0, 0, 1, 1}
 	c.SetSize(c.Texture.Size)
 
 	return nil
@@ -90,55 +98,28 @@ func (c *UIImage) LoadFromData(data []uint8, intFormat uint32, format int32, wid
 // SetPosition sets the UIImage's position
 func (c *UIImage) SetPosition(pos mgl32.Vec2) {
 	c.Transform().Position = mgl32.Vec3{pos.X(), pos.Y(), 0.0}
-	c.updateMesh()
 }
 
 // SetSize sets the UIImage's size
 func (c *UIImage) SetSize(size mgl32.Vec2) {
 	c.Transform().Scale = mgl32.Vec3{size.X(), size.Y(), 0.0}
-	c.updateMesh()
 }
 
-func (c *UIImage) updateMesh() {
-	var err error
+// Render adds the UIImage to the RenderContext's SpriteBatch
+func (c *UIImage) Render(ctx *RenderContext) {
+	if c.Texture == nil {
+		return
+	}
+
 	pos := c.Transform().Position
 	size := c.Transform().Scale
 
-	x := pos.X()
-	y := pos.Y()
-	w := size.X()
-	h := size.Y()
-
-	if c.Mesh == nil {
-		c.Mesh, err = new2DMesh(
-			mgl32.Vec4{x, y, x + w, y + h},
-			mgl32.Vec4{0, 0, 1, 1})
-		if err != nil {
-			c.Delete()
-			Errorf("%v", err)
-		}
-	} else {
-		err = update2DMesh(c.Mesh,
-			mgl32.Vec4{x, y, x + w, y + h},
-			mgl32.Vec4{0, 0, 1, 1})
-		if err != nil {
-			c.Delete()
-			Errorf("%v", err)
-		}
-	}
-}
-
-// Render renders the UIImage to the buffer
-func (c *UIImage) Render(ctx *RenderContext) {
-	s := GetUIShader()
+	q := NewSpriteQuad(c.Texture, pos.Vec2())
+	q.Size = size.Vec2()
+	q.UV = c.UV
 
-	gl.Uniform1i(s.UniformLocation("uTexture"), 0)
+	// The UI is laid out from the top left, so the image is flipped to keep its top row at the top
+	q.FlipY = true
 
-	gl.ActiveTexture(gl.TEXTURE0)
-	if c.Texture != nil {
-		c.Texture.Bind()
-	}
-	if c.Mesh != nil {
-		c.Mesh.Render(s)
-	}
+	drawSprite(ctx, q)
 }
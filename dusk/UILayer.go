@@ -1,8 +1,6 @@
 package dusk
 
 import (
-	"fmt"
-
 	gl "github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
 )
@@ -11,15 +9,16 @@ import (
 type UILayer struct {
 	Layer
 
-	Buffer    *Texture
+	Target    *RenderTarget
 	Shader    *UIShader
 	Mesh      *Mesh
 	RenderCtx RenderContext
 	Size      Vec2i
 
-	frameID           uint32
-	depthID           uint32
 	needTextureUpdate bool
+
+	window         *Window
+	resizeCallback CallbackID
 }
 
 // NewUILayer returns a new UILayer of the given size
@@ -31,56 +30,74 @@ func NewUILayer(app *App) (*UILayer, error) {
 		return nil, err
 	}
 
-	var (
-		frameID uint32
-		depthID uint32
-	)
-
-	buffer, err := NewTextureFromData(nil, gl.RGBA8, gl.RGBA, int(size.X()), int(size.Y()))
+	target, err := NewRenderTarget(size.X(), size.Y(), &RenderTargetOptions{
+		ColorFormats: []uint32{gl.RGBA8},
+		Depth:        true,
+		Filter:       gl.NEAREST,
+	})
 	if err != nil {
+		mesh.Delete()
 		return nil, err
 	}
 
-	gl.GenRenderbuffers(1, &depthID)
-	gl.BindRenderbuffer(gl.RENDERBUFFER, depthID)
-	gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH_COMPONENT, int32(size.X()), int32(size.Y()))
-	gl.BindRenderbuffer(gl.RENDERBUFFER, 0)
-
-	gl.GenFramebuffers(1, &frameID)
-	gl.BindFramebuffer(gl.FRAMEBUFFER, frameID)
-
-	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, buffer.ID, 0)
-	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.RENDERBUFFER, depthID)
-
-	status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER)
-	if status != gl.FRAMEBUFFER_COMPLETE {
-		return nil, fmt.Errorf("Failed to create Framebuffer")
-	}
-
-	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
-
-	return &UILayer{
-		Buffer: buffer,
+	ui := &UILayer{
+		Target: target,
 		Shader: GetUIShader(),
 		Mesh:   mesh,
 		Size:   size,
 
 		RenderCtx: RenderContext{
 			Projection: mgl32.Ortho2D(0, float32(size.X()), 0, float32(size.Y())),
+			Sprites:    NewSpriteBatch(),
 		},
 
-		frameID: frameID,
-		depthID: depthID,
-
 		needTextureUpdate: true,
-	}, nil
+
+		window: app.Window,
+	}
+
+	// The resize callback is given the framebuffer size, but the UI is laid out in window coordinates
+	ui.resizeCallback = app.Window.RegisterResizeFunc(func(width, height int) {
+		err := ui.Resize(app.Window.Width, app.Window.Height)
+		if err != nil {
+			Errorf("%v", err)
+		}
+	})
+
+	return ui, nil
+}
+
+// Resize reallocates the framebuffer and updates the projection for a new window size
+func (ui *UILayer) Resize(width, height int) error {
+	if width <= 0 || height <= 0 || (width == ui.Size.X() && height == ui.Size.Y()) {
+		return nil
+	}
+
+	err := ui.Target.Resize(width, height)
+	if err != nil {
+		return err
+	}
+
+	err = update2DMesh(ui.Mesh, mgl32.Vec4{0, 0, float32(width), float32(height)}, mgl32.Vec4{0, 1, 1, 0})
+	if err != nil {
+		return err
+	}
+
+	ui.Size = Vec2i{width, height}
+	ui.RenderCtx.Projection = mgl32.Ortho2D(0, float32(width), 0, float32(height))
+	return nil
 }
 
 // Delete frees all resources owned by the UI
 func (ui *UILayer) Delete() {
-	if ui.Buffer != nil {
-		ui.Buffer.Delete()
-		ui.Buffer = nil
+	if ui.window != nil {
+		ui.window.UnregisterFunc(ui.resizeCallback)
+		ui.window = nil
+	}
+
+	if ui.Target != nil {
+		ui.Target.Delete()
+		ui.Target = nil
 	}
 
 	if ui.Shader != nil {
@@ -93,36 +110,42 @@ func (ui *UILayer) Delete() {
 		ui.Mesh = nil
 	}
 
-	if ui.depthID != 0 {
-		gl.DeleteRenderbuffers(1, &ui.depthID)
-		ui.depthID = 0
+	if ui.RenderCtx.Sprites != nil {
+		ui.RenderCtx.Sprites.Delete()
+		ui.RenderCtx.Sprites = nil
 	}
+}
 
-	if ui.frameID != 0 {
-		gl.DeleteFramebuffers(1, &ui.frameID)
-		ui.frameID = 0
-	}
+// IsOverlay implements the IOverlayLayer interface, the UI is drawn after post-processing
+func (ui *UILayer) IsOverlay() bool {
+	return true
 }
 
 // Render renders the current buffer to the screen
-func (ui *UILayer) Render(_ *RenderContext) {
-	ui.Shader.Bind(&ui.RenderCtx, nil)
-	gl.UniformMatrix4fv(ui.Shader.UniformLocation("uProjection"), 1, false, &ui.RenderCtx.Projection[0])
+func (ui *UILayer) Render(ctx *RenderContext) {
+	if ctx.Pass != ColorPass {
+		return
+	}
 
-	gl.BindFramebuffer(gl.FRAMEBUFFER, ui.frameID)
+	ui.Target.Bind()
 	gl.ClearColor(0, 0, 0, 0)
 	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
+	// Elements that draw directly are cleared between each other, while the sprites of UIImage and UIText
+	// are drawn together afterwards, in the order of their entities
 	for _, e := range ui.GetEntities() {
 		e.Render(&ui.RenderCtx)
 		gl.Clear(gl.DEPTH_BUFFER_BIT)
 	}
+	ui.RenderCtx.Sprites.Flush(&ui.RenderCtx)
 
-	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	ui.Target.UnBind()
 
+	// Elements may have bound other Shaders, such as the SpriteShader
+	ui.Shader.Bind(&ui.RenderCtx, nil)
 	gl.Uniform1i(ui.Shader.UniformLocation("uTexture"), 0)
 	gl.ActiveTexture(gl.TEXTURE0)
-	ui.Buffer.Bind()
+	ui.Target.GetColor(0).Bind()
 
 	gl.Clear(gl.DEPTH_BUFFER_BIT)
 	ui.Mesh.Render(ui.Shader)
@@ -104,5 +104,6 @@ func (c *UIText) updateUITexture() {
 		Errorf("%v", err)
 	}
 
+	c.UV = mgl32.Vec4{0, 0, 1, 1}
 	c.SetSize(mgl32.Vec2{float32(s.X), float32(s.Y)})
 }
@@ -7,3 +7,8 @@ type UpdateContext struct {
 	ElapsedTime float64
 	TotalTime   float64
 }
+
+// DeltaSeconds returns the time since the last update in seconds, DeltaTime is measured in frames at 60 FPS
+func (ctx *UpdateContext) DeltaSeconds() float32 {
+	return ctx.DeltaTime / 60.0
+}
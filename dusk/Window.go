@@ -38,6 +38,35 @@ type ResizeFunc func(int, int)
 type KeyFunc func(Key, InputAction)
 type MouseFunc func(MouseButton, InputAction)
 type MouseMoveFunc func(mgl32.Vec2)
+type ScrollFunc func(mgl32.Vec2)
+
+// CallbackID identifies a function registered with a Window, so that it can be unregistered
+type CallbackID int
+
+type resizeCallback struct {
+	id  CallbackID
+	fun ResizeFunc
+}
+
+type keyCallback struct {
+	id  CallbackID
+	fun KeyFunc
+}
+
+type mouseCallback struct {
+	id  CallbackID
+	fun MouseFunc
+}
+
+type mouseMoveCallback struct {
+	id  CallbackID
+	fun MouseMoveFunc
+}
+
+type scrollCallback struct {
+	id  CallbackID
+	fun ScrollFunc
+}
 
 // Window represents a Window
 type Window struct {
@@ -45,10 +74,12 @@ type Window struct {
 	Height int
 	Title  string
 
-	resizeFuncs    []ResizeFunc
-	keyFuncs       []KeyFunc
-	mouseFuncs     []MouseFunc
-	mouseMoveFuncs []MouseMoveFunc
+	resizeFuncs    []resizeCallback
+	keyFuncs       []keyCallback
+	mouseFuncs     []mouseCallback
+	mouseMoveFuncs []mouseMoveCallback
+	scrollFuncs    []scrollCallback
+	lastCallbackID CallbackID
 
 	glfwWindow *glfw.Window
 }
@@ -86,25 +117,31 @@ func NewWindow(opts *WindowOptions) (w *Window, err error) {
 		gl.Viewport(0, 0, int32(width), int32(height))
 
 		for _, f := range w.resizeFuncs {
-			f(width, height)
+			f.fun(width, height)
 		}
 	})
 
 	w.glfwWindow.SetKeyCallback(func(_ *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
 		for _, f := range w.keyFuncs {
-			f(Key(key), InputAction(action))
+			f.fun(Key(key), InputAction(action))
 		}
 	})
 
 	w.glfwWindow.SetMouseButtonCallback(func(_ *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
 		for _, f := range w.mouseFuncs {
-			f(MouseButton(button), InputAction(action))
+			f.fun(MouseButton(button), InputAction(action))
 		}
 	})
 
 	w.glfwWindow.SetCursorPosCallback(func(_ *glfw.Window, x, y float64) {
 		for _, f := range w.mouseMoveFuncs {
-			f(mgl32.Vec2{float32(x), float32(y)})
+			f.fun(mgl32.Vec2{float32(x), float32(y)})
+		}
+	})
+
+	w.glfwWindow.SetScrollCallback(func(_ *glfw.Window, x, y float64) {
+		for _, f := range w.scrollFuncs {
+			f.fun(mgl32.Vec2{float32(x), float32(y)})
 		}
 	})
 
@@ -142,6 +179,7 @@ func NewWindow(opts *WindowOptions) (w *Window, err error) {
 	Infof("OpenGL Renderer: [%s]", gl.GoStr(gl.GetString(gl.RENDERER)))
 
 	gl.Enable(gl.DEPTH_TEST)
+	gl.Enable(gl.TEXTURE_CUBE_MAP_SEAMLESS)
 	gl.DepthFunc(gl.LESS)
 
 	gl.Enable(gl.BLEND)
@@ -162,20 +200,89 @@ func (w *Window) Delete() {
 	glfw.Terminate()
 }
 
-func (w *Window) RegisterResizeFunc(fun ResizeFunc) {
-	w.resizeFuncs = append(w.resizeFuncs, fun)
+func (w *Window) nextCallbackID() CallbackID {
+	w.lastCallbackID++
+	return w.lastCallbackID
+}
+
+func (w *Window) RegisterResizeFunc(fun ResizeFunc) CallbackID {
+	id := w.nextCallbackID()
+	w.resizeFuncs = append(w.resizeFuncs, resizeCallback{id, fun})
+	return id
+}
+
+func (w *Window) RegisterKeyFunc(fun KeyFunc) CallbackID {
+	id := w.nextCallbackID()
+	w.keyFuncs = append(w.keyFuncs, keyCallback{id, fun})
+	return id
+}
+
+func (w *Window) RegisterMouseFunc(fun MouseFunc) CallbackID {
+	id := w.nextCallbackID()
+	w.mouseFuncs = append(w.mouseFuncs, mouseCallback{id, fun})
+	return id
+}
+
+func (w *Window) RegisterMouseMoveFunc(fun MouseMoveFunc) CallbackID {
+	id := w.nextCallbackID()
+	w.mouseMoveFuncs = append(w.mouseMoveFuncs, mouseMoveCallback{id, fun})
+	return id
+}
+
+// RegisterScrollFunc adds a function called with the scroll offset when the mouse wheel or touchpad is scrolled
+func (w *Window) RegisterScrollFunc(fun ScrollFunc) CallbackID {
+	id := w.nextCallbackID()
+	w.scrollFuncs = append(w.scrollFuncs, scrollCallback{id, fun})
+	return id
+}
+
+// UnregisterFunc removes a function added by any of the Register functions
+func (w *Window) UnregisterFunc(id CallbackID) {
+	for i := range w.resizeFuncs {
+		if w.resizeFuncs[i].id == id {
+			w.resizeFuncs = append(w.resizeFuncs[:i], w.resizeFuncs[i+1:]...)
+			return
+		}
+	}
+	for i := range w.keyFuncs {
+		if w.keyFuncs[i].id == id {
+			w.keyFuncs = append(w.keyFuncs[:i], w.keyFuncs[i+1:]...)
+			return
+		}
+	}
+	for i := range w.mouseFuncs {
+		if w.mouseFuncs[i].id == id {
+			w.mouseFuncs = append(w.mouseFuncs[:i], w.mouseFuncs[i+1:]...)
+			return
+		}
+	}
+	for i := range w.mouseMoveFuncs {
+		if w.mouseMoveFuncs[i].id == id {
+			w.mouseMoveFuncs = append(w.mouseMoveFuncs[:i], w.mouseMoveFuncs[i+1:]...)
+			return
+		}
+	}
+	for i := range w.scrollFuncs {
+		if w.scrollFuncs[i].id == id {
+			w.scrollFuncs = append(w.scrollFuncs[:i], w.scrollFuncs[i+1:]...)
+			return
+		}
+	}
 }
 
-func (w *Window) RegisterKeyFunc(fun KeyFunc) {
-	w.keyFuncs = append(w.keyFuncs, fun)
+// IsKeyDown returns whether the key is currently held
+func (w *Window) IsKeyDown(key Key) bool {
+	return w.glfwWindow.GetKey(glfw.Key(key)) != glfw.Release
 }
 
-func (w *Window) RegisterMouseFunc(fun MouseFunc) {
-	w.mouseFuncs = append(w.mouseFuncs, fun)
+// IsMouseDown returns whether the mouse button is currently held
+func (w *Window) IsMouseDown(button MouseButton) bool {
+	return w.glfwWindow.GetMouseButton(glfw.MouseButton(button)) != glfw.Release
 }
 
-func (w *Window) RegisterMouseMoveFunc(fun MouseMoveFunc) {
-	w.mouseMoveFuncs = append(w.mouseMoveFuncs, fun)
+// GetFramebufferSize returns the size of the Window's framebuffer in pixels
+func (w *Window) GetFramebufferSize() (int, int) {
+	return w.glfwWindow.GetFramebufferSize()
 }
 
 func (w *Window) GetMousePos() mgl32.Vec2 {
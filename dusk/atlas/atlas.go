@@ -0,0 +1,314 @@
+// Package atlas packs images into a single texture atlas, and reads and writes its JSON metadata
+//
+// The metadata uses the "JSON (Hash)" layout of common sprite-sheet exporters such as TexturePacker,
+// and the "JSON (Array)" layout can also be read.
+package atlas
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"sort"
+)
+
+// Rect is a rectangle in pixels, from the top left of the image
+type Rect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// Size is a width and height in pixels
+type Size struct {
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// Frame is a named region of the atlas
+type Frame struct {
+	Frame            Rect `json:"frame"`
+	Rotated          bool `json:"rotated"`
+	Trimmed          bool `json:"trimmed"`
+	SpriteSourceSize Rect `json:"spriteSourceSize"`
+	SourceSize       Size `json:"sourceSize"`
+}
+
+// Meta describes the atlas image
+type Meta struct {
+	App     string `json:"app,omitempty"`
+	Version string `json:"version,omitempty"`
+	Image   string `json:"image"`
+	Format  string `json:"format,omitempty"`
+	Size    Size   `json:"size"`
+	Scale   string `json:"scale,omitempty"`
+}
+
+// Frames maps the names of regions to their Frames
+type Frames map[string]Frame
+
+// UnmarshalJSON accepts both the hash layout, and the array layout where each frame has a "filename"
+func (f *Frames) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if len(b) > 0 && b[0] == '[' {
+		list := []struct {
+			Frame
+			Filename string `json:"filename"`
+		}{}
+		err := json.Unmarshal(b, &list)
+		if err != nil {
+			return err
+		}
+
+		*f = Frames{}
+		for _, fr := range list {
+			(*f)[fr.Filename] = fr.Frame
+		}
+		return nil
+	}
+
+	m := map[string]Frame{}
+	err := json.Unmarshal(b, &m)
+	if err != nil {
+		return err
+	}
+	*f = m
+	return nil
+}
+
+// Atlas is the metadata of a packed image
+type Atlas struct {
+	Frames Frames `json:"frames"`
+	Meta   Meta   `json:"meta"`
+}
+
+// Parse reads Atlas metadata from JSON
+func Parse(b []byte) (*Atlas, error) {
+	a := &Atlas{}
+	err := json.Unmarshal(b, a)
+	if err != nil {
+		return nil, err
+	}
+	if a.Frames == nil {
+		return nil, fmt.Errorf("Atlas has no frames")
+	}
+	return a, nil
+}
+
+// Marshal returns the Atlas metadata as JSON
+func (a *Atlas) Marshal() ([]byte, error) {
+	return json.MarshalIndent(a, "", "    ")
+}
+
+// Image is an image to be packed, and the name of its region
+type Image struct {
+	Name  string
+	Image image.Image
+}
+
+// Pack combines the images into the smallest power of two sized image it can, up to maxSize x maxSize
+// Each image is separated from the others by padding pixels
+func Pack(images []Image, maxSize, padding int) (*image.RGBA, *Atlas, error) {
+	if len(images) == 0 {
+		return nil, nil, fmt.Errorf("No images to pack")
+	}
+
+	sorted := make([]Image, len(images))
+	copy(sorted, images)
+
+	// Packing the tallest images first leaves fewer gaps
+	sort.SliceStable(sorted, func(i, j int) bool {
+		bi, bj := sorted[i].Image.Bounds(), sorted[j].Image.Bounds()
+		if bi.Dy() != bj.Dy() {
+			return bi.Dy() > bj.Dy()
+		}
+		return bi.Dx() > bj.Dx()
+	})
+
+	area := 0
+	for _, img := range sorted {
+		b := img.Image.Bounds()
+		if b.Dx()+padding > maxSize || b.Dy()+padding > maxSize {
+			return nil, nil, fmt.Errorf("Image [%v] is larger than the maximum atlas size %d", img.Name, maxSize)
+		}
+		area += (b.Dx() + padding) * (b.Dy() + padding)
+	}
+
+	// Start from the smallest power of two that could hold the total area, and grow until everything fits
+	w, h := 1, 1
+	for w*h < area {
+		if w <= h {
+			w *= 2
+		} else {
+			h *= 2
+		}
+	}
+
+	for w <= maxSize && h <= maxSize {
+		rects, ok := packRects(sorted, w, h, padding)
+		if ok {
+			return drawAtlas(sorted, rects, w, h)
+		}
+
+		if w <= h {
+			w *= 2
+		} else {
+			h *= 2
+		}
+	}
+
+	return nil, nil, fmt.Errorf("Images do not fit in a %dx%d atlas", maxSize, maxSize)
+}
+
+// packRects finds a place for every image in a w x h area, and returns whether they all fit
+func packRects(images []Image, w, h, padding int) ([]Rect, bool) {
+	p := NewPacker(w, h, padding)
+	rects := make([]Rect, len(images))
+	for i, img := range images {
+		b := img.Image.Bounds()
+		r, ok := p.Insert(b.Dx(), b.Dy())
+		if !ok {
+			return nil, false
+		}
+		rects[i] = r
+	}
+	return rects, true
+}
+
+func drawAtlas(images []Image, rects []Rect, w, h int) (*image.RGBA, *Atlas, error) {
+	out := image.NewRGBA(image.Rect(0, 0, w, h))
+	a := &Atlas{
+		Frames: Frames{},
+		Meta: Meta{
+			App:    "GoDusk",
+			Format: "RGBA8888",
+			Size:   Size{W: w, H: h},
+			Scale:  "1",
+		},
+	}
+
+	for i, img := range images {
+		r := rects[i]
+		b := img.Image.Bounds()
+		draw.Draw(out, image.Rect(r.X, r.Y, r.X+r.W, r.Y+r.H), img.Image, b.Min, draw.Src)
+
+		if _, found := a.Frames[img.Name]; found {
+			return nil, nil, fmt.Errorf("Duplicate image name [%v]", img.Name)
+		}
+		a.Frames[img.Name] = Frame{
+			Frame:            r,
+			SpriteSourceSize: Rect{W: r.W, H: r.H},
+			SourceSize:       Size{W: r.W, H: r.H},
+		}
+	}
+	return out, a, nil
+}
+
+// skylineSegment is a horizontal edge of the packed area, everything below it is used
+type skylineSegment struct {
+	X, Y, W int
+}
+
+// Packer places rectangles in a fixed size area, using the skyline bottom-left method
+// With images, "bottom" is the top of the image, as Y increases downwards
+type Packer struct {
+	Width   int
+	Height  int
+	Padding int
+
+	skyline []skylineSegment
+}
+
+// NewPacker returns a new, empty Packer for an area of width x height
+func NewPacker(width, height, padding int) *Packer {
+	return &Packer{
+		Width:   width,
+		Height:  height,
+		Padding: padding,
+		skyline: []skylineSegment{{X: 0, Y: 0, W: width}},
+	}
+}
+
+// Insert finds a place for a w x h rectangle, and returns false if there is no room left
+func (p *Packer) Insert(w, h int) (Rect, bool) {
+	pw, ph := w+p.Padding, h+p.Padding
+
+	best := -1
+	bestX, bestY := 0, 0
+	for i := range p.skyline {
+		y, ok := p.fit(i, pw, ph)
+		if !ok {
+			continue
+		}
+		if best < 0 || y < bestY || (y == bestY && p.skyline[i].X < bestX) {
+			best = i
+			bestX = p.skyline[i].X
+			bestY = y
+		}
+	}
+	if best < 0 {
+		return Rect{}, false
+	}
+
+	p.addSegment(best, skylineSegment{X: bestX, Y: bestY + ph, W: pw})
+	return Rect{X: bestX, Y: bestY, W: w, H: h}, true
+}
+
+// fit returns the lowest Y a w x h rectangle can be placed at, starting at segment i
+func (p *Packer) fit(i, w, h int) (int, bool) {
+	x := p.skyline[i].X
+	if x+w > p.Width {
+		return 0, false
+	}
+
+	y := 0
+	remaining := w
+	for j := i; remaining > 0; j++ {
+		if j >= len(p.skyline) {
+			return 0, false
+		}
+		if p.skyline[j].Y > y {
+			y = p.skyline[j].Y
+		}
+		if y+h > p.Height {
+			return 0, false
+		}
+		remaining -= p.skyline[j].W
+	}
+	return y, true
+}
+
+// addSegment inserts a segment at index i, shrinking or removing the segments it covers
+func (p *Packer) addSegment(i int, s skylineSegment) {
+	p.skyline = append(p.skyline, skylineSegment{})
+	copy(p.skyline[i+1:], p.skyline[i:])
+	p.skyline[i] = s
+
+	for j := i + 1; j < len(p.skyline); j++ {
+		prev := p.skyline[j-1]
+		cur := &p.skyline[j]
+		if cur.X >= prev.X+prev.W {
+			break
+		}
+
+		shrink := prev.X + prev.W - cur.X
+		cur.X += shrink
+		cur.W -= shrink
+		if cur.W > 0 {
+			break
+		}
+		p.skyline = append(p.skyline[:j], p.skyline[j+1:]...)
+		j--
+	}
+
+	// Merge neighbouring segments at the same height
+	for j := 0; j+1 < len(p.skyline); j++ {
+		if p.skyline[j].Y == p.skyline[j+1].Y {
+			p.skyline[j].W += p.skyline[j+1].W
+			p.skyline = append(p.skyline[:j+1], p.skyline[j+2:]...)
+			j--
+		}
+	}
+}
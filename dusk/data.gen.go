@@ -10,8 +10,13 @@
 // data\models\uvsphere.mtl
 // data\models\uvsphere.obj
 // data\shaders\include\attribute.inc.glsl
+// data\shaders\include\instance.inc.glsl
+// data\shaders\include\lighting.inc.glsl
 // data\shaders\include\material.inc.glsl
 // data\shaders\include\mvp.inc.glsl
+// data\shaders\include\post.inc.glsl
+// data\shaders\include\shadow.inc.glsl
+// data\shaders\include\skin.inc.glsl
 // +build !release
 
 
@@ -219,6 +224,42 @@ func bindataDatashadersincludeattributeincglsl() (*asset, error) {
 	return a, err
 }
 
+// bindataDatashadersincludeinstanceincglsl reads file data from disk. It returns an error on failure.
+func bindataDatashadersincludeinstanceincglsl() (*asset, error) {
+	path := "C:\\Go\\src\\github.com\\WhoBrokeTheBuild\\GoDusk\\dusk\\data\\shaders\\include\\instance.inc.glsl"
+	name := "data/shaders/include/instance.inc.glsl"
+	bytes, err := bindataRead(path, name)
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		err = fmt.Errorf("Error reading asset info %s at %s: %v", name, path, err)
+	}
+
+	a := &asset{bytes: bytes, info: fi}
+	return a, err
+}
+
+// bindataDatashadersincludelightingincglsl reads file data from disk. It returns an error on failure.
+func bindataDatashadersincludelightingincglsl() (*asset, error) {
+	path := "C:\\Go\\src\\github.com\\WhoBrokeTheBuild\\GoDusk\\dusk\\data\\shaders\\include\\lighting.inc.glsl"
+	name := "data/shaders/include/lighting.inc.glsl"
+	bytes, err := bindataRead(path, name)
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		err = fmt.Errorf("Error reading asset info %s at %s: %v", name, path, err)
+	}
+
+	a := &asset{bytes: bytes, info: fi}
+	return a, err
+}
+
 // bindataDatashadersincludematerialincglsl reads file data from disk. It returns an error on failure.
 func bindataDatashadersincludematerialincglsl() (*asset, error) {
 	path := "C:\\Go\\src\\github.com\\WhoBrokeTheBuild\\GoDusk\\dusk\\data\\shaders\\include\\material.inc.glsl"
@@ -255,6 +296,60 @@ func bindataDatashadersincludemvpincglsl() (*asset, error) {
 	return a, err
 }
 
+// bindataDatashadersincludepostincglsl reads file data from disk. It returns an error on failure.
+func bindataDatashadersincludepostincglsl() (*asset, error) {
+	path := "C:\\Go\\src\\github.com\\WhoBrokeTheBuild\\GoDusk\\dusk\\data\\shaders\\include\\post.inc.glsl"
+	name := "data/shaders/include/post.inc.glsl"
+	bytes, err := bindataRead(path, name)
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		err = fmt.Errorf("Error reading asset info %s at %s: %v", name, path, err)
+	}
+
+	a := &asset{bytes: bytes, info: fi}
+	return a, err
+}
+
+// bindataDatashadersincludeshadowincglsl reads file data from disk. It returns an error on failure.
+func bindataDatashadersincludeshadowincglsl() (*asset, error) {
+	path := "C:\\Go\\src\\github.com\\WhoBrokeTheBuild\\GoDusk\\dusk\\data\\shaders\\include\\shadow.inc.glsl"
+	name := "data/shaders/include/shadow.inc.glsl"
+	bytes, err := bindataRead(path, name)
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		err = fmt.Errorf("Error reading asset info %s at %s: %v", name, path, err)
+	}
+
+	a := &asset{bytes: bytes, info: fi}
+	return a, err
+}
+
+// bindataDatashadersincludeskinincglsl reads file data from disk. It returns an error on failure.
+func bindataDatashadersincludeskinincglsl() (*asset, error) {
+	path := "C:\\Go\\src\\github.com\\WhoBrokeTheBuild\\GoDusk\\dusk\\data\\shaders\\include\\skin.inc.glsl"
+	name := "data/shaders/include/skin.inc.glsl"
+	bytes, err := bindataRead(path, name)
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		err = fmt.Errorf("Error reading asset info %s at %s: %v", name, path, err)
+	}
+
+	a := &asset{bytes: bytes, info: fi}
+	return a, err
+}
+
 
 //
 // Asset loads and returns the asset for the given name.
@@ -329,8 +424,13 @@ var _bindata = map[string]func() (*asset, error){
 	"data/models/uvsphere.mtl":                bindataDatamodelsuvspheremtl,
 	"data/models/uvsphere.obj":                bindataDatamodelsuvsphereobj,
 	"data/shaders/include/attribute.inc.glsl": bindataDatashadersincludeattributeincglsl,
+	"data/shaders/include/instance.inc.glsl":  bindataDatashadersincludeinstanceincglsl,
+	"data/shaders/include/lighting.inc.glsl":  bindataDatashadersincludelightingincglsl,
 	"data/shaders/include/material.inc.glsl":  bindataDatashadersincludematerialincglsl,
 	"data/shaders/include/mvp.inc.glsl":       bindataDatashadersincludemvpincglsl,
+	"data/shaders/include/post.inc.glsl":      bindataDatashadersincludepostincglsl,
+	"data/shaders/include/shadow.inc.glsl":    bindataDatashadersincludeshadowincglsl,
+	"data/shaders/include/skin.inc.glsl":      bindataDatashadersincludeskinincglsl,
 }
 
 //
@@ -402,8 +502,13 @@ var _bintree = &bintree{Func: nil, Children: map[string]*bintree{
 		"shaders": {Func: nil, Children: map[string]*bintree{
 			"include": {Func: nil, Children: map[string]*bintree{
 				"attribute.inc.glsl": {Func: bindataDatashadersincludeattributeincglsl, Children: map[string]*bintree{}},
+				"instance.inc.glsl": {Func: bindataDatashadersincludeinstanceincglsl, Children: map[string]*bintree{}},
+				"lighting.inc.glsl": {Func: bindataDatashadersincludelightingincglsl, Children: map[string]*bintree{}},
 				"material.inc.glsl": {Func: bindataDatashadersincludematerialincglsl, Children: map[string]*bintree{}},
 				"mvp.inc.glsl": {Func: bindataDatashadersincludemvpincglsl, Children: map[string]*bintree{}},
+				"post.inc.glsl": {Func: bindataDatashadersincludepostincglsl, Children: map[string]*bintree{}},
+				"shadow.inc.glsl": {Func: bindataDatashadersincludeshadowincglsl, Children: map[string]*bintree{}},
+				"skin.inc.glsl": {Func: bindataDatashadersincludeskinincglsl, Children: map[string]*bintree{}},
 			}},
 		}},
 	}},
@@ -4,5 +4,6 @@
 layout(location = ATTR_POSITION) in vec3 _Position;
 layout(location = ATTR_NORMAL)   in vec3 _Normal;
 layout(location = ATTR_TEXCOORD) in vec2 _TexCoord;
+layout(location = ATTR_TANGENT)  in vec4 _Tangent;
 
 #endif ATTRIBUTES_INC
\ No newline at end of file
@@ -0,0 +1,29 @@
+#ifndef INSTANCE_INC
+#define INSTANCE_INC
+
+#include <mvp.inc.glsl>
+
+#ifdef USE_INSTANCING
+layout(location = ATTR_INSTANCE_MATRIX) in mat4 _InstanceMatrix;
+layout(location = ATTR_INSTANCE_COLOR)  in vec4 _InstanceColor;
+#endif
+
+// GetModelMatrix returns the per-instance matrix when instancing, or uModel otherwise
+mat4 GetModelMatrix() {
+#ifdef USE_INSTANCING
+    return _InstanceMatrix;
+#else
+    return uModel;
+#endif
+}
+
+// GetInstanceColor returns the per-instance color when instancing, or white otherwise
+vec4 GetInstanceColor() {
+#ifdef USE_INSTANCING
+    return _InstanceColor;
+#else
+    return vec4(1.0);
+#endif
+}
+
+#endif INSTANCE_INC
\ No newline at end of file
@@ -0,0 +1,45 @@
+#ifndef LIGHTING_INC
+#define LIGHTING_INC
+
+#include <shadow.inc.glsl>
+
+struct Light {
+    int   Type;
+    vec3  Position;
+    vec3  Direction;
+    vec3  Color;
+    float Range;
+    float InnerCutoff;
+    float OuterCutoff;
+    int   ShadowIndex;
+};
+
+uniform Light uLights[MAX_LIGHTS];
+uniform int   uLightCount;
+
+uniform vec3 uCameraPosition;
+
+// Returns the normalized direction from the point to the light in L, and the light's color and intensity
+vec3 GetLightRadiance(Light light, vec3 worldPos, out vec3 L) {
+    if (light.Type == LIGHT_DIRECTIONAL) {
+        L = -light.Direction;
+        return light.Color;
+    }
+
+    vec3 toLight = light.Position - worldPos;
+    float dist = length(toLight);
+    L = toLight / dist;
+
+    // Inverse square falloff, windowed to reach zero at the light's range
+    float window = clamp(1.0 - pow(dist / light.Range, 4.0), 0.0, 1.0);
+    float attenuation = (window * window) / (dist * dist + 1.0);
+
+    if (light.Type == LIGHT_SPOT) {
+        float theta = dot(L, -light.Direction);
+        attenuation *= clamp((theta - light.OuterCutoff) / (light.InnerCutoff - light.OuterCutoff), 0.0, 1.0);
+    }
+
+    return light.Color * attenuation;
+}
+
+#endif LIGHTING_INC
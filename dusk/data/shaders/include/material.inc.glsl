@@ -4,13 +4,29 @@
 uniform vec4 uAmbient;
 uniform vec4 uDiffuse;
 uniform vec4 uSpecular;
+uniform float uShininess;
 
 uniform sampler2D uAmbientMap; 
 uniform sampler2D uDiffuseMap; 
 uniform sampler2D uSpecularMap; 
 uniform sampler2D uNormalMap; 
 
+uniform vec4 uBaseColor;
+uniform float uMetallic;
+uniform float uRoughness;
+uniform float uOcclusion;
+uniform vec3 uEmissive;
+
+uniform sampler2D uBaseColorMap;
+uniform sampler2D uMetallicMap;
+uniform sampler2D uRoughnessMap;
+uniform sampler2D uOcclusionMap;
+uniform sampler2D uEmissiveMap;
+
+uniform sampler2D uAlphaMap;
+
 uniform uint uMapFlags;
+uniform int uFlipNormalMapGreen;
 
 bool HasAmbientMap() {
     return ((uMapFlags & FLAG_AMBIENT_MAPu) > 0u);
@@ -24,5 +40,65 @@ bool HasSpecularMap() {
 bool HasNormalMap() {
     return ((uMapFlags & FLAG_NORMAL_MAPu) > 0u);
 }
+bool HasBaseColorMap() {
+    return ((uMapFlags & FLAG_BASE_COLOR_MAPu) > 0u);
+}
+bool HasMetallicMap() {
+    return ((uMapFlags & FLAG_METALLIC_MAPu) > 0u);
+}
+bool HasRoughnessMap() {
+    return ((uMapFlags & FLAG_ROUGHNESS_MAPu) > 0u);
+}
+bool HasOcclusionMap() {
+    return ((uMapFlags & FLAG_OCCLUSION_MAPu) > 0u);
+}
+bool HasEmissiveMap() {
+    return ((uMapFlags & FLAG_EMISSIVE_MAPu) > 0u);
+}
+bool HasAlphaMap() {
+    return ((uMapFlags & FLAG_ALPHA_MAPu) > 0u);
+}
+
+// GetMappedNormal returns the normal N perturbed by the tangent-space uNormalMap, sampled at sampleCoord
+// tangent is the world space tangent with the handedness of the bitangent in w, or zero to find it from the
+// screen-space derivatives of position and texCoord, the texcoords of the mesh before they are flipped for sampling
+vec3 GetMappedNormal(vec3 N, vec4 tangent, vec3 position, vec2 texCoord, vec2 sampleCoord) {
+    if (!HasNormalMap()) {
+        return N;
+    }
+
+    vec3 mapped = texture(uNormalMap, sampleCoord).xyz * 2.0 - 1.0;
+    if (uFlipNormalMapGreen != 0) {
+        mapped.y = -mapped.y;
+    }
+
+    vec3 T;
+    vec3 B;
+    if (dot(tangent.xyz, tangent.xyz) > 0.0) {
+        T = normalize(tangent.xyz - N * dot(N, tangent.xyz));
+        B = cross(N, T) * tangent.w;
+    } else {
+        // Cotangent frame from Schueler's "Normal Mapping Without Precomputed Tangents"
+        vec3 dp1 = dFdx(position);
+        vec3 dp2 = dFdy(position);
+        vec2 duv1 = dFdx(texCoord);
+        vec2 duv2 = dFdy(texCoord);
+
+        vec3 dp2perp = cross(dp2, N);
+        vec3 dp1perp = cross(N, dp1);
+        T = dp2perp * duv1.x + dp1perp * duv2.x;
+        B = dp2perp * duv1.y + dp1perp * duv2.y;
+
+        float scale = max(dot(T, T), dot(B, B));
+        if (scale == 0.0) {
+            return N;
+        }
+        scale = inversesqrt(scale);
+        T *= scale;
+        B *= scale;
+    }
+
+    return normalize(mat3(T, B, N) * mapped);
+}
 
-#endif MATERIAL_INC
\ No newline at end of file
+#endif MATERIAL_INC
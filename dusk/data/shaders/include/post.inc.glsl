@@ -0,0 +1,11 @@
+#ifndef POST_INC
+#define POST_INC
+
+uniform sampler2D uTexture;
+uniform vec2 uTexelSize;
+
+in vec2 p_TexCoord;
+
+out vec4 _Color;
+
+#endif POST_INC
\ No newline at end of file
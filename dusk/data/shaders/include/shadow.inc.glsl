@@ -0,0 +1,60 @@
+#ifndef SHADOW_INC
+#define SHADOW_INC
+
+uniform sampler2DArrayShadow uShadowMaps[MAX_SHADOW_MAPS];
+uniform mat4  uShadowMatrices[MAX_SHADOW_MAPS * MAX_CASCADES];
+uniform float uShadowSplits[MAX_SHADOW_MAPS * MAX_CASCADES];
+uniform float uShadowBias[MAX_SHADOW_MAPS];
+uniform int   uShadowCascades[MAX_SHADOW_MAPS];
+
+uniform bool uReceiveShadows;
+
+float SampleShadowMap(sampler2DArrayShadow shadowMap, vec3 coord, float layer, float bias) {
+    vec2 texel = 1.0 / vec2(textureSize(shadowMap, 0).xy);
+
+    // 3x3 Percentage Closer Filtering
+    float lit = 0.0;
+    for (int x = -1; x <= 1; ++x) {
+        for (int y = -1; y <= 1; ++y) {
+            vec2 uv = coord.xy + vec2(x, y) * texel;
+            lit += texture(shadowMap, vec4(uv, layer, coord.z - bias));
+        }
+    }
+    return lit / 9.0;
+}
+
+// Returns 1.0 if the point is fully lit, and 0.0 if it is fully in shadow
+float GetShadow(int index, vec3 worldPos, float viewDepth, float NdotL) {
+    if (!uReceiveShadows || index < 0) {
+        return 1.0;
+    }
+
+    int cascade = uShadowCascades[index] - 1;
+    for (int i = 0; i < uShadowCascades[index]; ++i) {
+        if (viewDepth < uShadowSplits[index * MAX_CASCADES + i]) {
+            cascade = i;
+            break;
+        }
+    }
+
+    vec4 coord = uShadowMatrices[index * MAX_CASCADES + cascade] * vec4(worldPos, 1.0);
+    coord.xyz = (coord.xyz / coord.w) * 0.5 + 0.5;
+    if (coord.z > 1.0) {
+        return 1.0;
+    }
+
+    // Scale the bias by the slope of the surface
+    float bias = uShadowBias[index] * clamp(tan(acos(clamp(NdotL, 0.0, 1.0))), 1.0, 10.0);
+
+    // Dynamic indexing into an array of samplers is not allowed
+    if (index == 0) {
+        return SampleShadowMap(uShadowMaps[0], coord.xyz, float(cascade), bias);
+    } else if (index == 1) {
+        return SampleShadowMap(uShadowMaps[1], coord.xyz, float(cascade), bias);
+    } else if (index == 2) {
+        return SampleShadowMap(uShadowMaps[2], coord.xyz, float(cascade), bias);
+    }
+    return SampleShadowMap(uShadowMaps[3], coord.xyz, float(cascade), bias);
+}
+
+#endif SHADOW_INC
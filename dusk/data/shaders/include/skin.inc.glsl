@@ -0,0 +1,31 @@
+#ifndef SKIN_INC
+#define SKIN_INC
+
+layout(location = ATTR_BONE_INDICES) in vec4 _BoneIndices;
+layout(location = ATTR_BONE_WEIGHTS) in vec4 _BoneWeights;
+
+uniform int uSkinned;
+uniform mat4 uBones[MAX_BONES];
+
+// GetSkinMatrix returns the weighted sum of the bone matrices for the vertex, or the identity when not skinned
+// Bones past MAX_BONES are ignored, and a vertex with no weight left is not moved
+mat4 GetSkinMatrix() {
+    if (uSkinned == 0) {
+        return mat4(1.0);
+    }
+    mat4 skin = mat4(0.0);
+    float total = 0.0;
+    for (int i = 0; i < 4; ++i) {
+        int bone = int(_BoneIndices[i]);
+        if (bone < MAX_BONES) {
+            skin += uBones[bone] * _BoneWeights[i];
+            total += _BoneWeights[i];
+        }
+    }
+    if (total == 0.0) {
+        return mat4(1.0);
+    }
+    return skin;
+}
+
+#endif SKIN_INC
\ No newline at end of file
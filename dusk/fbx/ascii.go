@@ -0,0 +1,387 @@
+package fbx
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/WhoBrokeTheBuild/GoDusk/dusk"
+)
+
+// asciiParser reads an ASCII FBX file into the same tree of nodes and props as the binary reader
+//
+// Nodes are written as 'Name: prop, prop, ... { children }', and arrays as '*count { a: value, value, ... }'
+// Since ASCII values have no types, they are given the types that the binary format uses for the same node
+type asciiParser struct {
+	data []byte
+	pos  int
+	line int
+
+	version int
+}
+
+// readASCII parses an ASCII FBX file into its tree of nodes
+// Only version 7 and later are supported, as earlier versions connect objects by name instead of ID
+func readASCII(file []byte) (*node, error) {
+	p := &asciiParser{
+		data: file,
+		line: 1,
+	}
+
+	root := &node{}
+	nodes, err := p.parseNodes("")
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.data) {
+		return nil, p.errorf("Unexpected '%c'", p.data[p.pos])
+	}
+	root.Nodes = nodes
+
+	if len(root.Nodes) == 0 {
+		return nil, fmt.Errorf("Invalid file format")
+	}
+	if p.version != 0 && p.version < 7000 {
+		return nil, fmt.Errorf("Unsupported ASCII FBX Version %d.%d", p.version/1000, (p.version%1000)/100)
+	}
+
+	dusk.Verbosef("ASCII FBX Version %d.%d", p.version/1000, (p.version%1000)/100)
+	return root, nil
+}
+
+func (p *asciiParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("Line %d: %v", p.line, fmt.Sprintf(format, args...))
+}
+
+// skip moves past whitespace and comments
+func (p *asciiParser) skip() {
+	for p.pos < len(p.data) {
+		switch c := p.data[p.pos]; c {
+		case '\n':
+			p.line++
+			p.pos++
+		case ' ', '\t', '\r':
+			p.pos++
+		case ';':
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// peek returns the next character after any whitespace, or 0 at the end of the file
+func (p *asciiParser) peek() byte {
+	p.skip()
+	if p.pos >= len(p.data) {
+		return 0
+	}
+	return p.data[p.pos]
+}
+
+// expect moves past the next character, if it is c
+func (p *asciiParser) expect(c byte) error {
+	if next := p.peek(); next != c {
+		if next == 0 {
+			return p.errorf("Expected '%c', found end of file", c)
+		}
+		return p.errorf("Expected '%c', found '%c'", c, next)
+	}
+	p.pos++
+	return nil
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c == '|' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// word reads a name or bare value, such as 'Vertices' or 'T'
+func (p *asciiParser) word() string {
+	start := p.pos
+	for p.pos < len(p.data) && isWordChar(p.data[p.pos]) {
+		p.pos++
+	}
+	return string(p.data[start:p.pos])
+}
+
+// number reads the text of a number, such as '-1.5e-3'
+func (p *asciiParser) number() string {
+	start := p.pos
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if (c >= '0' && c <= '9') || c == '-' || c == '+' || c == '.' || c == 'e' || c == 'E' {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return string(p.data[start:p.pos])
+}
+
+// parseNodes reads nodes until the end of the file or a closing '}'
+func (p *asciiParser) parseNodes(parent string) ([]*node, error) {
+	nodes := []*node{}
+	for {
+		c := p.peek()
+		if c == 0 || c == '}' {
+			return nodes, nil
+		}
+
+		n, err := p.parseNode(parent)
+		if err != nil {
+			return nil, err
+		}
+
+		if !isIgnored(n.Name) {
+			nodes = append(nodes, n)
+		}
+	}
+}
+
+// parseNode reads one node, its props, and its children
+func (p *asciiParser) parseNode(parent string) (*node, error) {
+	p.skip()
+	name := p.word()
+	if name == "" {
+		return nil, p.errorf("Expected a node name, found '%c'", p.data[p.pos])
+	}
+	if err := p.expect(':'); err != nil {
+		return nil, err
+	}
+
+	n := &node{
+		Name:  name,
+		Props: []*prop{},
+		Nodes: []*node{},
+	}
+
+	// Props continue until the children or the next node, which starts with a name followed by ':'
+	for {
+		c := p.peek()
+		if c == 0 || c == '{' || c == '}' {
+			break
+		}
+		if len(n.Props) > 0 || c == ',' {
+			// Some nodes, such as 'Content', start with an empty value
+			if c != ',' {
+				break
+			}
+			p.pos++
+		} else if isWordChar(c) && p.isNodeStart() {
+			break
+		}
+
+		pr, err := p.parseProp(parent, n.Name, len(n.Props))
+		if err != nil {
+			return nil, err
+		}
+		n.Props = append(n.Props, pr)
+	}
+
+	if p.peek() == '{' {
+		p.pos++
+		children, err := p.parseNodes(n.Name)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect('}'); err != nil {
+			return nil, err
+		}
+		n.Nodes = children
+	}
+
+	if n.Name == "FBXVersion" && len(n.Props) > 0 {
+		if v, ok := toFloat32(n.Props[0].Value); ok {
+			p.version = int(v)
+		}
+	}
+
+	return n, nil
+}
+
+// isNodeStart returns whether the word at the current position is followed by ':', making it the name of a node
+func (p *asciiParser) isNodeStart() bool {
+	i := p.pos
+	for i < len(p.data) && isWordChar(p.data[i]) {
+		i++
+	}
+	for i < len(p.data) && (p.data[i] == ' ' || p.data[i] == '\t') {
+		i++
+	}
+	return i < len(p.data) && p.data[i] == ':'
+}
+
+// parseProp reads a single value, typed to match the binary format
+// index is the position of the prop in its node, which decides whether integers are IDs
+func (p *asciiParser) parseProp(parent, name string, index int) (*prop, error) {
+	c := p.peek()
+	switch {
+	case c == '"':
+		p.pos++
+		start := p.pos
+		for p.pos < len(p.data) && p.data[p.pos] != '"' {
+			if p.data[p.pos] == '\n' {
+				p.line++
+			}
+			p.pos++
+		}
+		if p.pos >= len(p.data) {
+			return nil, p.errorf("Unterminated string")
+		}
+		value := strings.Replace(string(p.data[start:p.pos]), "&quot;", "\"", -1)
+		p.pos++
+
+		// Object names are written as 'Class::Name', but stored as 'Name\x00\x01Class' in binary
+		if parent == "Objects" && index == 1 {
+			if i := strings.Index(value, "::"); i >= 0 {
+				value = value[i+2:] + "\x00\x01" + value[:i]
+			}
+		}
+		return &prop{Type: typeString, Value: value}, nil
+
+	case c == '*':
+		p.pos++
+		return p.parseArray(name)
+
+	case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+		text := p.number()
+		// Object IDs and the IDs in connections are always 64-bit
+		long := name == "C" || (parent == "Objects" && index == 0)
+		return p.parseNumber(text, long)
+
+	case isWordChar(c):
+		switch word := p.word(); word {
+		case "T", "Y":
+			return &prop{Type: typeBool, Value: uint8(1)}, nil
+		case "F", "N":
+			return &prop{Type: typeBool, Value: uint8(0)}, nil
+		default:
+			return &prop{Type: typeString, Value: word}, nil
+		}
+	}
+
+	return nil, p.errorf("Unexpected '%c'", c)
+}
+
+// parseNumber returns an integer prop if possible, or a double
+func (p *asciiParser) parseNumber(text string, long bool) (*prop, error) {
+	if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+		if long || i < math.MinInt32 || i > math.MaxInt32 {
+			return &prop{Type: typeLong, Value: i}, nil
+		}
+		return &prop{Type: typeInt, Value: int32(i)}, nil
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return nil, p.errorf("Invalid number '%v'", text)
+	}
+	return &prop{Type: typeDouble, Value: f}, nil
+}
+
+// arrayType returns the type the binary format uses for the array of a node
+func arrayType(name string) uint8 {
+	switch name {
+	case "KeyTime":
+		return typeLongArray
+	case "KeyValueFloat", "KeyAttrDataFloat":
+		return typeFloatArray
+	case "Materials", "Indexes", "Edges", "KeyAttrFlags", "KeyAttrRefCount":
+		return typeIntArray
+	}
+	if strings.HasSuffix(name, "Index") {
+		return typeIntArray
+	}
+	return typeDoubleArray
+}
+
+// parseArray reads an array written as '*count { a: value, ... }'
+func (p *asciiParser) parseArray(name string) (*prop, error) {
+	count, err := strconv.Atoi(p.number())
+	if err != nil || count < 0 {
+		return nil, p.errorf("Invalid array length")
+	}
+	if err := p.expect('{'); err != nil {
+		return nil, err
+	}
+
+	values := make([]string, 0, count)
+	if p.peek() != '}' {
+		if p.word() != "a" {
+			return nil, p.errorf("Expected 'a:' in array")
+		}
+		if err := p.expect(':'); err != nil {
+			return nil, err
+		}
+		for {
+			p.skip()
+			text := p.number()
+			if text == "" {
+				return nil, p.errorf("Expected a number in array")
+			}
+			values = append(values, text)
+			if p.peek() != ',' {
+				break
+			}
+			p.pos++
+		}
+	}
+	if err := p.expect('}'); err != nil {
+		return nil, err
+	}
+
+	if len(values) != count {
+		dusk.Warnf("Array '%v' has %d values, expected %d", name, len(values), count)
+	}
+
+	pr := &prop{Type: arrayType(name)}
+	switch pr.Type {
+	case typeLongArray:
+		tmp := make([]int64, len(values))
+		for i := range values {
+			tmp[i], err = strconv.ParseInt(values[i], 10, 64)
+			if err != nil {
+				return nil, p.errorf("Invalid integer '%v' in array", values[i])
+			}
+		}
+		pr.Value = tmp
+
+	case typeIntArray:
+		tmp := make([]int32, len(values))
+		for i := range values {
+			var v int64
+			v, err = strconv.ParseInt(values[i], 10, 32)
+			if err != nil {
+				return nil, p.errorf("Invalid integer '%v' in array", values[i])
+			}
+			tmp[i] = int32(v)
+		}
+		pr.Value = tmp
+
+	case typeFloatArray:
+		tmp := make([]float32, len(values))
+		for i := range values {
+			var v float64
+			v, err = strconv.ParseFloat(values[i], 32)
+			if err != nil {
+				return nil, p.errorf("Invalid number '%v' in array", values[i])
+			}
+			tmp[i] = float32(v)
+		}
+		pr.Value = tmp
+
+	default:
+		tmp := make([]float64, len(values))
+		for i := range values {
+			tmp[i], err = strconv.ParseFloat(values[i], 64)
+			if err != nil {
+				return nil, p.errorf("Invalid number '%v' in array", values[i])
+			}
+		}
+		pr.Value = tmp
+	}
+
+	return pr, nil
+}
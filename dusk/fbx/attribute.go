@@ -0,0 +1,115 @@
+package fbx
+
+import (
+	"github.com/WhoBrokeTheBuild/GoDusk/dusk"
+	"github.com/WhoBrokeTheBuild/GoDusk/m32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var (
+	// cameraRotation turns -Z to +X, the direction FBX cameras look in
+	cameraRotation = mgl32.QuatRotate(-m32.Pi/2, mgl32.Vec3{0, 1, 0})
+	// lightRotation turns -Z to -Y, the direction FBX lights shine in
+	lightRotation = mgl32.QuatRotate(-m32.Pi/2, mgl32.Vec3{1, 0, 0})
+)
+
+// FBX camera ApertureModes, which decide how the field of view is stored
+const (
+	apertureHorizAndVert = 0
+	apertureHorizontal   = 1
+	apertureVertical     = 2
+	apertureFocalLength  = 3
+)
+
+// propFloat returns a number property, or def if it is missing
+func propFloat(pMap propMap, name string, def float32) float32 {
+	if value, found := pMap[name].(float32); found {
+		return value
+	}
+	if value, found := pMap[name].(int); found {
+		return float32(value)
+	}
+	return def
+}
+
+// propInt returns an integer or enum property, or def if it is missing
+func propInt(pMap propMap, name string, def int) int {
+	if value, found := pMap[name].(int); found {
+		return value
+	}
+	return def
+}
+
+// readCamera reads a 'NodeAttribute' node of the Camera class
+// Distances are multiplied by unitScale, to convert them to meters
+func readCamera(attrNode *node, template propMap, unitScale float32) *dusk.SceneCamera {
+	pMap := readProps(attrNode, template)
+
+	c := &dusk.SceneCamera{
+		Orthographic: propInt(pMap, "CameraProjectionType", 0) == 1,
+		Near:         propFloat(pMap, "NearPlane", 10) * unitScale,
+		Far:          propFloat(pMap, "FarPlane", 4000) * unitScale,
+	}
+
+	if w, h := propFloat(pMap, "AspectWidth", 0), propFloat(pMap, "AspectHeight", 0); w > 0 && h > 0 {
+		c.Aspect = w / h
+	}
+
+	fov := propFloat(pMap, "FieldOfView", 45)
+	switch propInt(pMap, "ApertureMode", apertureVertical) {
+	case apertureHorizAndVert:
+		fov = propFloat(pMap, "FieldOfViewY", fov)
+	case apertureHorizontal:
+		if c.Aspect > 0 {
+			fov = mgl32.RadToDeg(2 * m32.Atan(m32.Tan(mgl32.DegToRad(fov)/2)/c.Aspect))
+		}
+	case apertureFocalLength:
+		// Film sizes are in inches, and focal lengths in millimeters
+		focal := propFloat(pMap, "FocalLength", 0)
+		film := propFloat(pMap, "FilmHeight", 0)
+		if focal > 0 && film > 0 {
+			fov = mgl32.RadToDeg(2 * m32.Atan(film*25.4/(2*focal)))
+		}
+	}
+	c.FOV = mgl32.DegToRad(fov)
+
+	return c
+}
+
+// readLight reads a 'NodeAttribute' node of the Light class
+// Area and volume lights are loaded as point lights
+func readLight(attrNode *node, template propMap, unitScale float32) *dusk.SceneLight {
+	pMap := readProps(attrNode, template)
+
+	l := &dusk.SceneLight{
+		Type:  dusk.PointLight,
+		Color: mgl32.Vec3{1, 1, 1},
+		// FBX intensities are percentages
+		Intensity: propFloat(pMap, "Intensity", 100) / 100,
+		// Cone angles are the full width of the cone in degrees
+		InnerAngle: mgl32.DegToRad(propFloat(pMap, "InnerAngle", 0) / 2),
+		OuterAngle: mgl32.DegToRad(propFloat(pMap, "OuterAngle", 45) / 2),
+	}
+
+	switch t := propInt(pMap, "LightType", 0); t {
+	case 0:
+	case 1:
+		l.Type = dusk.DirectionalLight
+	case 2:
+		l.Type = dusk.SpotLight
+	default:
+		dusk.Warnf("Unsupported light type %d in [%v], using a point light", t, nodeName(attrNode))
+	}
+
+	if value, found := pMap["Color"].(mgl32.Vec3); found {
+		l.Color = value
+	}
+	if value, found := pMap["CastShadows"].(bool); found {
+		l.CastShadows = value
+	}
+	if value, found := pMap["EnableFarAttenuation"].(bool); found && value {
+		l.Range = propFloat(pMap, "FarAttenuationEnd", 0) * unitScale
+	}
+
+	return l
+}
@@ -8,15 +8,17 @@ import (
 	"io"
 	"io/ioutil"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/WhoBrokeTheBuild/GoDusk/dusk"
-	"github.com/WhoBrokeTheBuild/GoDusk/m32"
+	"github.com/WhoBrokeTheBuild/GoDusk/dusk/meshutil"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
 func init() {
 	dusk.RegisterModelFormat("fbx", []string{".fbx"}, Load)
+	dusk.RegisterSceneFormat("fbx", []string{".fbx"}, LoadScene)
 }
 
 const (
@@ -59,6 +61,16 @@ var ignore = []string{
 	"Version",
 }
 
+// isIgnored returns whether nodes with the given name are skipped when reading a file
+func isIgnored(name string) bool {
+	for _, ignored := range ignore {
+		if name == ignored {
+			return true
+		}
+	}
+	return false
+}
+
 type header struct {
 	Magic   [21]byte
 	_       [2]byte
@@ -324,7 +336,7 @@ type nodeHeader interface {
 
 func (n *node) read(r *reader) (err error) {
 	var h nodeHeader
-	if r.Header.Version > 7500 {
+	if r.Header.Version >= 7500 {
 		h = &nodeHeader64{}
 	} else {
 		h = &nodeHeader32{}
@@ -342,14 +354,7 @@ func (n *node) read(r *reader) (err error) {
 	r.Buffer.Read(tmp)
 	n.Name = string(tmp)
 
-	skip := false
-	for _, name := range ignore {
-		if n.Name == name {
-			skip = true
-		}
-	}
-
-	if skip {
+	if isIgnored(n.Name) {
 		r.Buffer.Seek(int64(h.GetEndOffset()), io.SeekStart)
 		return
 	}
@@ -375,6 +380,10 @@ func (n *node) read(r *reader) (err error) {
 		if err != nil {
 			return
 		}
+		// The list of children ends with an empty node, which is left out along with ignored nodes
+		if nn.Name == "" || isIgnored(nn.Name) {
+			continue
+		}
 		n.Nodes = append(n.Nodes, nn)
 	}
 
@@ -437,13 +446,16 @@ func newConn(root, n *node) *conn {
 		return nil
 	}
 
-	c.Type = n.Props[0].Value.(string)
-	a := n.Props[1].Value.(int64)
-	b := n.Props[2].Value.(int64)
+	c.Type, _ = n.Props[0].Value.(string)
+	a, okA := n.Props[1].Value.(int64)
+	b, okB := n.Props[2].Value.(int64)
+	if !okA || !okB {
+		return nil
+	}
 
 	if c.Type == "OP" {
 		if len(n.Props) >= 4 {
-			c.Bind = n.Props[3].Value.(string)
+			c.Bind, _ = n.Props[3].Value.(string)
 		}
 	}
 
@@ -469,14 +481,26 @@ func newPropMap(n *node) propMap {
 			dusk.Warnf("Not enough props in 'P' node")
 			continue
 		}
-		pName := pNode.Props[0].Value.(string)
-		pType := pNode.Props[1].Value.(string)
+		pName, ok := pNode.Props[0].Value.(string)
+		if !ok {
+			dusk.Warnf("Invalid name in 'P' node")
+			continue
+		}
+		pType, _ := pNode.Props[1].Value.(string)
 
 		switch pType {
 		case "ColorRGB":
 			fallthrough
+		case "Color":
+			fallthrough
+		case "ColorAndAlpha":
+			fallthrough
+		case "Vector":
+			fallthrough
 		case "Vector3D":
 			fallthrough
+		case "Lcl Translation":
+			fallthrough
 		case "Lcl Rotation":
 			fallthrough
 		case "Lcl Scaling":
@@ -484,34 +508,168 @@ func newPropMap(n *node) propMap {
 				dusk.Warnf("Not enough props in 'P' node")
 				continue
 			}
-			pm[pName] = mgl32.Vec3{
-				float32(pNode.Props[4].Value.(float64)),
-				float32(pNode.Props[5].Value.(float64)),
-				float32(pNode.Props[6].Value.(float64)),
+			x, _ := toFloat32(pNode.Props[4].Value)
+			y, _ := toFloat32(pNode.Props[5].Value)
+			z, _ := toFloat32(pNode.Props[6].Value)
+			pm[pName] = mgl32.Vec3{x, y, z}
+		case "FieldOfView", "FieldOfViewX", "FieldOfViewY":
+			fallthrough
+		case "double":
+			fallthrough
+		case "Number":
+			fallthrough
+		case "float":
+			fallthrough
+		case "Float":
+			if len(pNode.Props) < 5 {
+				dusk.Warnf("Not enough props in 'P' node")
+				continue
+			}
+			if value, ok := toFloat32(pNode.Props[4].Value); ok {
+				pm[pName] = value
+			}
+		case "int":
+			fallthrough
+		case "Integer":
+			fallthrough
+		case "enum":
+			if len(pNode.Props) < 5 {
+				dusk.Warnf("Not enough props in 'P' node")
+				continue
+			}
+			if value, ok := toFloat32(pNode.Props[4].Value); ok {
+				pm[pName] = int(value)
+			}
+		case "bool":
+			fallthrough
+		case "Bool":
+			if len(pNode.Props) < 5 {
+				dusk.Warnf("Not enough props in 'P' node")
+				continue
+			}
+			if value, ok := toFloat32(pNode.Props[4].Value); ok {
+				pm[pName] = (value != 0)
 			}
 		}
 	}
 	return pm
 }
 
-// Load parses and returns the data from the file
-func Load(filename string) (data []*dusk.MeshData, err error) {
-	filename = filepath.Clean(filename)
-	dir := filepath.Dir(filename)
+// readProps returns the properties of an object, with defaults from its PropertyTemplate
+func readProps(n *node, template propMap) propMap {
+	pMap := propMap{}
+	for k, v := range template {
+		pMap[k] = v
+	}
+	if p70Node := n.findFirst("Properties70"); p70Node != nil {
+		for k, v := range newPropMap(p70Node) {
+			pMap[k] = v
+		}
+	}
+	return pMap
+}
 
-	file, err := dusk.Load(filename)
-	if err != nil {
-		return
+func toFloat32(value interface{}) (float32, bool) {
+	switch v := value.(type) {
+	case float64:
+		return float32(v), true
+	case float32:
+		return v, true
+	case int32:
+		return float32(v), true
+	case int64:
+		return float32(v), true
+	case uint8:
+		return float32(v), true
+	}
+	return 0, false
+}
+
+// readMaterialProps copies the known material properties from a 'Properties70' node, including the
+// PBR properties exported by Maya (Standard Surface and Stingray PBS) and 3ds Max (Physical Material)
+func readMaterialProps(m *dusk.MaterialData, pMap propMap) {
+	if value, found := pMap["AmbientColor"].(mgl32.Vec3); found {
+		m.Ambient = mgl32.Vec4{value[0], value[1], value[2], 1.0}
+	}
+	if value, found := pMap["DiffuseColor"].(mgl32.Vec3); found {
+		m.Diffuse = mgl32.Vec4{value[0], value[1], value[2], 1.0}
+	}
+	if value, found := pMap["SpecularColor"].(mgl32.Vec3); found {
+		m.Specular = mgl32.Vec4{value[0], value[1], value[2], 1.0}
+	}
+	if value, found := pMap["Shininess"].(float32); found {
+		m.Shininess = value
+	}
+	if value, found := pMap["ShininessExponent"].(float32); found {
+		m.Shininess = value
+	}
+	if value, found := pMap["EmissiveColor"].(mgl32.Vec3); found {
+		m.Emissive = value
+		if factor, found := pMap["EmissiveFactor"].(float32); found {
+			m.Emissive = m.Emissive.Mul(factor)
+		}
 	}
 
+	for _, name := range []string{"Maya|baseColor", "Maya|base_color", "3dsMax|Parameters|base_color"} {
+		if value, found := pMap[name].(mgl32.Vec3); found {
+			m.BaseColor = mgl32.Vec4{value[0], value[1], value[2], 1.0}
+		}
+	}
+	for _, name := range []string{"Maya|metalness", "Maya|metallic", "3dsMax|Parameters|metalness"} {
+		if value, found := pMap[name].(float32); found {
+			m.Metallic = value
+		}
+	}
+	for _, name := range []string{"Maya|specularRoughness", "Maya|roughness", "3dsMax|Parameters|roughness"} {
+		if value, found := pMap[name].(float32); found {
+			m.Roughness = value
+		}
+	}
+	if inv, found := pMap["3dsMax|Parameters|roughness_inv"].(bool); found && inv {
+		m.Roughness = 1.0 - m.Roughness
+	}
+	for _, name := range []string{"Maya|emissionColor", "Maya|emissive", "3dsMax|Parameters|emit_color"} {
+		if value, found := pMap[name].(mgl32.Vec3); found {
+			m.Emissive = value
+		}
+	}
+}
+
+// textureTarget returns the MaterialData field for a texture connected with the given binding
+func textureTarget(m *dusk.MaterialData, bind string) *string {
+	switch bind {
+	case "AmbientColor":
+		return &m.AmbientMap
+	case "DiffuseColor":
+		return &m.DiffuseMap
+	case "Specular", "SpecularColor":
+		return &m.SpecularMap
+	case "Bump", "NormalMap", "Maya|normalCamera", "Maya|TEX_normal_map", "3dsMax|Parameters|bump_map":
+		return &m.NormalMap
+	case "EmissiveColor", "Maya|emissionColor", "Maya|TEX_emissive_map", "3dsMax|Parameters|emit_color_map":
+		return &m.EmissiveMap
+	case "Maya|baseColor", "Maya|TEX_color_map", "3dsMax|Parameters|base_color_map":
+		return &m.BaseColorMap
+	case "Maya|metalness", "Maya|TEX_metallic_map", "3dsMax|Parameters|metalness_map":
+		return &m.MetallicMap
+	case "Maya|specularRoughness", "Maya|TEX_roughness_map", "3dsMax|Parameters|roughness_map":
+		return &m.RoughnessMap
+	case "Maya|TEX_ao_map", "3dsMax|Parameters|ao_map":
+		return &m.OcclusionMap
+	}
+	return nil
+}
+
+// readBinary parses a binary FBX file into its tree of nodes
+func readBinary(file []byte) (*node, error) {
 	r := &reader{
 		Buffer: bytes.NewReader(file),
 		Header: &header{},
 	}
 
-	err = binary.Read(r.Buffer, binary.LittleEndian, r.Header)
+	err := binary.Read(r.Buffer, binary.LittleEndian, r.Header)
 	if err != nil {
-		return
+		return nil, err
 	}
 
 	if string(r.Header.Magic[:]) != magic {
@@ -525,19 +683,59 @@ func Load(filename string) (data []*dusk.MeshData, err error) {
 		n := &node{}
 		err = n.read(r)
 		if err == io.EOF {
-			err = nil
 			root.Nodes = append(root.Nodes, n)
 			break
 		}
 		if err != nil {
-			return
+			return nil, err
+		}
+		// The top level ends with an empty node, followed by the footer
+		if n.Name == "" {
+			break
+		}
+		if isIgnored(n.Name) {
+			continue
 		}
 		root.Nodes = append(root.Nodes, n)
 	}
 
-	var ambient mgl32.Vec4
-	var diffuse mgl32.Vec4
-	var specular mgl32.Vec4
+	return root, nil
+}
+
+// Load parses the file and returns its MeshData, with the transform of every Model applied to its vertices
+func Load(filename string) ([]*dusk.MeshData, error) {
+	scene, err := LoadScene(filename)
+	if err != nil {
+		return nil, err
+	}
+	return scene.Flatten(), nil
+}
+
+// LoadScene parses the file and returns the hierarchy of its Models, converted to the engine's axes and units
+func LoadScene(filename string) (scene *dusk.Scene, err error) {
+	filename = filepath.Clean(filename)
+	dir := filepath.Dir(filename)
+
+	file, err := dusk.Load(filename)
+	if err != nil {
+		return
+	}
+
+	var root *node
+	if bytes.HasPrefix(file, []byte(magic)) {
+		root, err = readBinary(file)
+	} else {
+		root, err = readASCII(file)
+	}
+	if err != nil {
+		return
+	}
+
+	ambient := mgl32.Vec4{0, 0, 0, 1}
+	diffuse := mgl32.Vec4{0, 0, 0, 1}
+	specular := mgl32.Vec4{0, 0, 0, 1}
+	var matTemplate, modelTemplate propMap
+	attrTemplates := map[string]propMap{}
 
 	defNode := root.findFirst("Definitions")
 	if defNode != nil {
@@ -547,19 +745,37 @@ func Load(filename string) (data []*dusk.MeshData, err error) {
 				continue
 			}
 			t := otNode.Props[0].Value.(string)
+
+			// NodeAttributes have a template for each class, such as 'FbxCamera' and 'FbxLight'
+			if t == "NodeAttribute" {
+				for _, propTempNode := range otNode.findAll("PropertyTemplate") {
+					p70Node := propTempNode.findFirst("Properties70")
+					if p70Node != nil && len(propTempNode.Props) > 0 {
+						class, _ := propTempNode.Props[0].Value.(string)
+						attrTemplates[class] = newPropMap(p70Node)
+					}
+				}
+			}
+
 			if t == "Geometry" || t == "Model" || t == "Material" {
 				propTempNode := otNode.findFirst("PropertyTemplate")
 				if propTempNode != nil {
 					p70Node := propTempNode.findFirst("Properties70")
 					if p70Node != nil {
 						pMap := newPropMap(p70Node)
+						switch t {
+						case "Material":
+							matTemplate = pMap
+						case "Model":
+							modelTemplate = pMap
+						}
 
 						if value, found := pMap["Color"].(mgl32.Vec3); found {
 							diffuse = mgl32.Vec4{value[0], value[1], value[2], 1.0}
 						}
 
 						if value, found := pMap["AmbientColor"].(mgl32.Vec3); found {
-							diffuse = mgl32.Vec4{value[0], value[1], value[2], 1.0}
+							ambient = mgl32.Vec4{value[0], value[1], value[2], 1.0}
 						}
 						if value, found := pMap["DiffuseColor"].(mgl32.Vec3); found {
 							diffuse = mgl32.Vec4{value[0], value[1], value[2], 1.0}
@@ -590,84 +806,54 @@ func Load(filename string) (data []*dusk.MeshData, err error) {
 		return nil, fmt.Errorf("FBX has no 'Objects' node")
 	}
 
-	data = []*dusk.MeshData{}
-	modelNodes := objNode.findAll("Model")
-	for _, modelNode := range modelNodes {
-		name := modelNode.Props[1].Value.(string)
-		name = name[:len(name)-7]
-		dusk.Verbosef("Processing Object [%v]", name)
-
-		var geomNode *node
-		var matNode *node
-
-		rotation := mgl32.Vec3{0, 0, 0}
-		scale := mgl32.Vec3{1, 1, 1}
+	conversion := readAxisConversion(root)
 
-		for _, c := range conns {
-			if c.A == nil {
-				continue
-			}
+	ci := newConnIndex(conns)
+	skeleton, bones, skins := loadSkeleton(objNode, ci, modelTemplate, conversion)
 
-			if c.B == modelNode {
-				switch c.A.Name {
-				case "Geometry":
-					geomNode = c.A
-				case "Material":
-					matNode = c.A
-				}
-			}
-		}
+	var animations []*dusk.AnimationClip
+	if skeleton != nil {
+		dusk.Verbosef("Loaded Skeleton with %d bones", len(skeleton.Bones))
+		animations = loadAnimations(objNode, ci, skeleton, bones, modelTemplate, conversion)
+	}
 
-		if geomNode == nil {
-			continue
+	// Materials are shared by every Model using them, a nil node uses the defaults
+	matCache := map[*node]*dusk.Material{}
+	loadMaterial := func(matNode *node) (*dusk.Material, error) {
+		if mat, found := matCache[matNode]; found {
+			return mat, nil
 		}
 
-		var pMap propMap
-		p70Node := modelNode.findFirst("Properties70")
-		if p70Node != nil {
-			pMap = newPropMap(p70Node)
+		matData := &dusk.MaterialData{
+			Ambient:  ambient,
+			Diffuse:  diffuse,
+			Specular: specular,
 		}
 
-		if value, found := pMap["Lcl Rotation"].(mgl32.Vec3); found {
-			rotation = value
+		if matTemplate != nil {
+			readMaterialProps(matData, matTemplate)
 		}
-
-		if value, found := pMap["Lcl Scaling"].(mgl32.Vec3); found {
-			scale = value.Mul(1.0 / 100.0)
+		if matNode != nil {
+			if matP70Node := matNode.findFirst("Properties70"); matP70Node != nil {
+				readMaterialProps(matData, newPropMap(matP70Node))
+			}
 		}
 
-		var ambientTexNode *node
-		var diffuseTexNode *node
-		var specularTexNode *node
-		var normalTexNode *node
-
+		texTargets := map[*node]*string{}
 		for _, c := range conns {
-			if c.A == nil {
+			if c.A == nil || matNode == nil {
 				continue
 			}
 
 			if c.B == matNode {
 				if c.A.Name == "Texture" {
-					switch c.Bind {
-					case "AmbientColor":
-						ambientTexNode = c.A
-					case "DiffuseColor":
-						diffuseTexNode = c.A
-					case "Specular":
-						specularTexNode = c.A
-					case "Bump":
-						normalTexNode = c.A
+					if target := textureTarget(matData, c.Bind); target != nil {
+						texTargets[c.A] = target
 					}
 				}
 			}
 		}
 
-		matData := &dusk.MaterialData{
-			Ambient:  ambient,
-			Diffuse:  diffuse,
-			Specular: specular,
-		}
-
 		for _, c := range conns {
 			if c.A == nil {
 				continue
@@ -697,30 +883,94 @@ func Load(filename string) (data []*dusk.MeshData, err error) {
 
 				f = filepath.Join(dir, filepath.Clean(f))
 
-				if c.B == ambientTexNode {
-					matData.AmbientMap = f
-				} else if c.B == diffuseTexNode {
-					matData.DiffuseMap = f
-				} else if c.B == specularTexNode {
-					matData.SpecularMap = f
-				} else if c.B == normalTexNode {
-					matData.NormalMap = f
+				if target, found := texTargets[c.B]; found {
+					*target = f
 				}
 			}
 		}
 
-		vertInds := []int32{}
-		verts := []float64{}
+		mat, err := dusk.NewMaterialFromData(matData)
+		if err != nil {
+			return nil, err
+		}
+		matCache[matNode] = mat
+		return mat, nil
+	}
+
+	scene = &dusk.Scene{}
+
+	// Every Model becomes a SceneNode, with its parent Model as its Parent
+	modelNodes := objNode.findAll("Model")
+	sceneNodes := map[*node]*dusk.SceneNode{}
+	transforms := map[*node]*modelTransform{}
+	for _, modelNode := range modelNodes {
+		mt := readModelTransform(modelNode, modelTemplate)
+		sn := dusk.NewSceneNode(nodeName(modelNode))
+		sn.SetMatrix(mt.localMatrix())
+		sceneNodes[modelNode] = sn
+		transforms[modelNode] = mt
+	}
+	for _, modelNode := range modelNodes {
+		sn := sceneNodes[modelNode]
+		if parent, found := sceneNodes[ci.parent(modelNode, "Model")]; found {
+			parent.AddChild(sn)
+		} else {
+			// Only the nodes at the root are converted, the rest inherit it
+			sn.SetMatrix(conversion.Mul4(sn.GetLocalMatrix()))
+			scene.AddNode(sn)
+		}
+	}
+
+	// Cameras and lights are placed on a child of their Model, turned to face down -Z
+	unitScale := conversion.Col(0).Vec3().Len()
+	for _, modelNode := range modelNodes {
+		for _, c := range ci.children(modelNode, "NodeAttribute") {
+			var child *dusk.SceneNode
+			switch nodeClass(c.A) {
+			case "Camera":
+				child = dusk.NewSceneNode(nodeName(c.A))
+				child.Camera = readCamera(c.A, attrTemplates["FbxCamera"], unitScale)
+				child.Rotation = cameraRotation
+			case "Light":
+				child = dusk.NewSceneNode(nodeName(c.A))
+				child.Light = readLight(c.A, attrTemplates["FbxLight"], unitScale)
+				child.Rotation = lightRotation
+			}
+			if child != nil {
+				sceneNodes[modelNode].AddChild(child)
+			}
+		}
+	}
+
+	skinned := []*dusk.SceneNode{}
+	for _, modelNode := range modelNodes {
+		name := nodeName(modelNode)
+		dusk.Verbosef("Processing Object [%v]", name)
+
+		var geomNode *node
+		matNodes := []*node{}
+
+		for _, c := range conns {
+			if c.A == nil {
+				continue
+			}
+
+			if c.B == modelNode {
+				switch c.A.Name {
+				case "Geometry":
+					geomNode = c.A
+				case "Material":
+					matNodes = append(matNodes, c.A)
+				}
+			}
+		}
 
-		normInds := []int32{}
-		norms := []float64{}
-		normMapping := ""
-		normReference := ""
+		if geomNode == nil {
+			continue
+		}
 
-		txcdInds := []int32{}
-		txcds := []float64{}
-		txcdMapping := ""
-		txcdReference := ""
+		sn := sceneNodes[modelNode]
+		mt := transforms[modelNode]
 
 		vertNode := geomNode.findFirst("Vertices")
 		if vertNode == nil || len(vertNode.Props) == 0 {
@@ -728,7 +978,7 @@ func Load(filename string) (data []*dusk.MeshData, err error) {
 			continue
 		}
 
-		verts = vertNode.Props[0].Value.([]float64)
+		verts := toFloat64s(vertNode.Props[0].Value)
 		if len(verts) == 0 {
 			dusk.Warnf("No vertices")
 		}
@@ -739,93 +989,84 @@ func Load(filename string) (data []*dusk.MeshData, err error) {
 			continue
 		}
 
-		vertInds = indexNode.Props[0].Value.([]int32)
-		if len(verts) == 0 {
+		vertInds := toInt32s(indexNode.Props[0].Value)
+		if len(vertInds) == 0 {
 			dusk.Warnf("No vertInds")
 		}
 
-		normLayerNode := geomNode.findFirst("LayerElementNormal")
-		if normLayerNode != nil {
-			normMapNode := normLayerNode.findFirst("MappingInformationType")
-			if normMapNode == nil || len(normMapNode.Props) == 0 {
-				dusk.Warnf("No 'MappingInformationType' in 'LayerElementNormal' node")
-			} else {
-				normMapping = normMapNode.Props[0].Value.(string)
-			}
+		normals := readLayerElement(geomNode, "LayerElementNormal", "Normals", "NormalsIndex", 3)
+		txcds := readLayerElement(geomNode, "LayerElementUV", "UV", "UVIndex", 2)
+		colors := readLayerElement(geomNode, "LayerElementColor", "Colors", "ColorIndex", 4)
+		tangents := readLayerElement(geomNode, "LayerElementTangent", "Tangents", "TangentsIndex", 3)
+		binormals := readLayerElement(geomNode, "LayerElementBinormal", "Binormals", "BinormalsIndex", 3)
+
+		// Polygons choose their Material by its index in the order they are connected to the Model
+		materials := readLayerElement(geomNode, "LayerElementMaterial", "Materials", "", 1)
+
+		// Skinned vertices are moved to where they were bound to the Skeleton, in Scene space
+		// Other vertices stay in the space of their Model, with only its geometric transform applied
+		sk := skins[geomNode]
+		geometric := mt.geometric
+		if sk != nil {
+			geometric = sk.bind
+			skinned = append(skinned, sn)
+		}
+		normMat := geometric.Mat3().Inv().Transpose()
 
-			normRefNode := normLayerNode.findFirst("ReferenceInformationType")
-			if normRefNode == nil || len(normRefNode.Props) == 0 {
-				dusk.Warnf("No 'ReferenceInformationType' in 'LayerElementNormal' node")
-			} else {
-				normReference = normRefNode.Props[0].Value.(string)
-			}
+		transformPoint := func(v mgl32.Vec3) mgl32.Vec3 {
+			return mgl32.TransformCoordinate(v, geometric)
+		}
 
-			if normReference == "IndexToDirect" {
-				normIndNode := normLayerNode.findFirst("NormalsIndex")
-				if normIndNode == nil || len(normIndNode.Props) == 0 {
-					dusk.Warnf("No 'NormalsIndex' in 'LayerElementNormal' node")
-				} else {
-					normInds = normIndNode.Props[0].Value.([]int32)
-				}
-			}
+		transformNormal := func(n mgl32.Vec3) mgl32.Vec3 {
+			return normMat.Mul3x1(n).Normalize()
+		}
 
-			normNode := normLayerNode.findFirst("Normals")
-			if normNode == nil || len(normNode.Props) == 0 {
-				dusk.Warnf("No 'Normals' in 'LayerElementNormal' node")
-			} else {
-				norms = normNode.Props[0].Value.([]float64)
-			}
+		transformDirection := func(n mgl32.Vec3) mgl32.Vec3 {
+			return mgl32.TransformNormal(n, geometric).Normalize()
 		}
 
-		txcdLayerNode := geomNode.findFirst("LayerElementUV")
-		if txcdLayerNode != nil {
-			txcdMapNode := txcdLayerNode.findFirst("MappingInformationType")
-			if txcdMapNode == nil || len(txcdMapNode.Props) == 0 {
-				dusk.Warnf("No 'MappingInformationType' in 'LayerElementUV' node")
-			} else {
-				txcdMapping = txcdMapNode.Props[0].Value.(string)
+		// Each Material used by the Geometry gets its own MeshData, in the order they are first used
+		meshes := map[int]*dusk.MeshData{}
+		meshOrder := []int{}
+		getMesh := func(matIndex int) (*dusk.MeshData, error) {
+			if matIndex < 0 || matIndex >= len(matNodes) {
+				matIndex = 0
 			}
-
-			txcdRefNode := txcdLayerNode.findFirst("ReferenceInformationType")
-			if txcdRefNode == nil || len(txcdRefNode.Props) == 0 {
-				dusk.Warnf("No 'ReferenceInformationType' in 'LayerElementUV' node")
-			} else {
-				txcdReference = txcdRefNode.Props[0].Value.(string)
+			if d, found := meshes[matIndex]; found {
+				return d, nil
 			}
 
-			if txcdReference == "IndexToDirect" {
-				txcdIndNode := txcdLayerNode.findFirst("UVIndex")
-				if txcdIndNode == nil || len(txcdIndNode.Props) == 0 {
-					dusk.Warnf("No 'UVIndex' in 'LayerElementUV' node")
-				} else {
-					txcdInds = txcdIndNode.Props[0].Value.([]int32)
-				}
+			var matNode *node
+			if matIndex < len(matNodes) {
+				matNode = matNodes[matIndex]
+			}
+			mat, err := loadMaterial(matNode)
+			if err != nil {
+				return nil, err
 			}
 
-			txcdNode := txcdLayerNode.findFirst("UV")
-			if txcdNode == nil || len(txcdNode.Props) == 0 {
-				dusk.Warnf("No 'UV' in 'LayerElementUV' node")
-			} else {
-				txcds = txcdNode.Props[0].Value.([]float64)
+			d := &dusk.MeshData{
+				Name:      name,
+				Vertices:  []mgl32.Vec3{},
+				Normals:   []mgl32.Vec3{},
+				TexCoords: []mgl32.Vec2{},
+				Material:  mat,
+			}
+			if sk != nil {
+				d.Skeleton = skeleton
+				d.Animations = animations
+				d.BoneIndices = []mgl32.Vec4{}
+				d.BoneWeights = []mgl32.Vec4{}
 			}
-		}
 
-		mat, err := dusk.NewMaterialFromData(matData)
-		if err != nil {
-			return nil, err
+			meshes[matIndex] = d
+			meshOrder = append(meshOrder, matIndex)
+			return d, nil
 		}
 
-		_ = normInds
-		_ = txcdInds
-
-		d := &dusk.MeshData{
-			Name:      name,
-			Vertices:  []mgl32.Vec3{},
-			Normals:   []mgl32.Vec3{},
-			TexCoords: []mgl32.Vec2{},
-			Material:  mat,
-		}
 		inds := []int{}
+		points := []mgl32.Vec3{}
+		polygon := 0
 		for i := 0; i < len(vertInds); i += len(inds) {
 			inds = []int{}
 			for j := i; j < len(vertInds); j++ {
@@ -837,113 +1078,86 @@ func Load(filename string) (data []*dusk.MeshData, err error) {
 			}
 			inds[len(inds)-1] ^= -1
 
-			order := []int{0, 1, 2}
-			if len(inds) == 4 {
-				order = []int{0, 1, 2, 2, 3, 0}
-			}
-
-			for _, o := range order {
-				ind := inds[o] * 3
-				v := mgl32.Vec3{
+			points = points[:0]
+			for _, cp := range inds {
+				ind := cp * 3
+				points = append(points, mgl32.Vec3{
 					float32(verts[ind+0]),
 					float32(verts[ind+1]),
 					float32(verts[ind+2]),
-				}
-				if rotation[0] != 0 {
-					v = mgl32.TransformCoordinate(v, mgl32.HomogRotate3DX(rotation[0]*(-180.0/m32.Pi)))
-				}
-				if rotation[1] != 0 {
-					v = mgl32.TransformCoordinate(v, mgl32.HomogRotate3DY(rotation[1]*(-180.0/m32.Pi)))
-				}
-				if rotation[2] != 0 {
-					v = mgl32.TransformCoordinate(v, mgl32.HomogRotate3DZ(rotation[2]*(-180.0/m32.Pi)))
-				}
-				if !scale.ApproxEqual(mgl32.Vec3{1, 1, 1}) {
-					v = mgl32.TransformCoordinate(v, mgl32.Scale3D(scale[0], scale[1], scale[2]))
-				}
-				d.Vertices = append(d.Vertices, v)
-			}
-
-			if len(norms) > 0 {
-				if normMapping == "ByPolygonVertex" {
-					if normReference == "Direct" {
-						for _, o := range order {
-							ind := (i + o) * 3
-							n := mgl32.Vec3{
-								float32(norms[ind+0]),
-								float32(norms[ind+1]),
-								float32(norms[ind+2]),
-							}
-							if rotation[0] != 0 {
-								n = mgl32.TransformNormal(n, mgl32.HomogRotate3DX(rotation[0]*(-180.0/m32.Pi)))
-							}
-							if rotation[1] != 0 {
-								n = mgl32.TransformNormal(n, mgl32.HomogRotate3DY(rotation[1]*(-180.0/m32.Pi)))
-							}
-							if rotation[2] != 0 {
-								n = mgl32.TransformNormal(n, mgl32.HomogRotate3DZ(rotation[2]*(-180.0/m32.Pi)))
-							}
-							d.Normals = append(d.Normals, n)
-						}
-					} else if normReference == "IndexToDirect" {
-						for _, o := range order {
-							ind := inds[i+o] * 3
-							n := mgl32.Vec3{
-								float32(norms[ind+0]),
-								float32(norms[ind+1]),
-								float32(norms[ind+2]),
-							}
-							if rotation[0] != 0 {
-								n = mgl32.TransformNormal(n, mgl32.HomogRotate3DX(rotation[0]*(-180.0/m32.Pi)))
-							}
-							if rotation[1] != 0 {
-								n = mgl32.TransformNormal(n, mgl32.HomogRotate3DY(rotation[1]*(-180.0/m32.Pi)))
-							}
-							if rotation[2] != 0 {
-								n = mgl32.TransformNormal(n, mgl32.HomogRotate3DZ(rotation[2]*(-180.0/m32.Pi)))
-							}
-							d.Normals = append(d.Normals, n)
-						}
+				})
+			}
+
+			matIndex := 0
+			if materials != nil {
+				matIndex = materials.getInt(i, inds[0], polygon)
+			}
+			d, err := getMesh(matIndex)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, tri := range dusk.TriangulatePolygon(points) {
+				for _, o := range tri {
+					// Layer elements can be indexed by polygon corner, control point or polygon
+					pv, cp := i+o, inds[o]
+
+					d.Vertices = append(d.Vertices, transformPoint(points[o]))
+
+					if sk != nil {
+						indices, weights := sk.getBones(cp)
+						d.BoneIndices = append(d.BoneIndices, indices)
+						d.BoneWeights = append(d.BoneWeights, weights)
 					}
-				} else if normMapping == "ByVertex" {
-					if normReference == "Direct" {
-						// TODO
-					} else if normReference == "IndexToDirect" {
-						// TODO
+					if normals != nil {
+						d.Normals = append(d.Normals, transformNormal(normals.vec3(pv, cp, polygon)))
+					}
+					if txcds != nil {
+						d.TexCoords = append(d.TexCoords, txcds.vec2(pv, cp, polygon))
+					}
+					if colors != nil {
+						d.Colors = append(d.Colors, colors.vec4(pv, cp, polygon))
+					}
+					if tangents != nil {
+						d.Tangents = append(d.Tangents, transformDirection(tangents.vec3(pv, cp, polygon)))
+					}
+					if binormals != nil {
+						d.Bitangents = append(d.Bitangents, transformDirection(binormals.vec3(pv, cp, polygon)))
 					}
 				}
+				d.Polygons = append(d.Polygons, polygon)
 			}
 
-			if len(txcds) > 0 {
-				if txcdMapping == "ByPolygonVertex" {
-					if txcdReference == "Direct" {
-						for _, o := range order {
-							ind := (i + o) * 2
-							d.TexCoords = append(d.TexCoords, mgl32.Vec2{
-								float32(txcds[ind+0]),
-								float32(txcds[ind+1]),
-							})
-						}
-					} else if txcdReference == "IndexToDirect" {
-						for _, o := range order {
-							ind := txcdInds[i+o] * 2
-							d.TexCoords = append(d.TexCoords, mgl32.Vec2{
-								float32(txcds[ind+0]),
-								float32(txcds[ind+1]),
-							})
-						}
-					}
-				} else if txcdMapping == "ByVertex" {
-					if txcdReference == "Direct" {
-						// TODO
-					} else if txcdReference == "IndexToDirect" {
-						// TODO
-					}
+			polygon++
+		}
+
+		for _, matIndex := range meshOrder {
+			d := meshes[matIndex]
+			if d.Material != nil && d.Material.NormalMap != nil && !d.HasTangents() {
+				meshutil.GenerateTangents(d)
+			}
+			if len(meshOrder) > 1 {
+				matName := strconv.Itoa(matIndex)
+				if matIndex < len(matNodes) && nodeName(matNodes[matIndex]) != "" {
+					matName = nodeName(matNodes[matIndex])
 				}
+				d.Name = name + "/" + matName
 			}
+			sn.Meshes = append(sn.Meshes, d)
 		}
+	}
 
-		data = append(data, d)
+	// Skinned vertices are already in Scene space, so their nodes are moved to the root without a transform
+	for _, sn := range skinned {
+		global := sn.GetGlobalMatrix()
+		for _, c := range sn.Children {
+			c.SetMatrix(global.Mul4(c.GetLocalMatrix()))
+		}
+		if sn.Parent != nil {
+			sn.Parent.RemoveChild(sn)
+			scene.AddNode(sn)
+		}
+		sn.SetMatrix(mgl32.Ident4())
 	}
 
 	return
@@ -0,0 +1,132 @@
+package fbx
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/WhoBrokeTheBuild/GoDusk/dusk"
+)
+
+// meshFiles are the same file in each format, with 32-bit node records before 7500 and 64-bit after
+var meshFiles = []string{"mesh.fbx", "mesh-7400.fbx", "mesh-7500.fbx"}
+
+// compareNodes returns the first difference between two trees of nodes, or an empty string if there is none
+// ASCII files have no types for single values, so numbers are compared by value
+func compareNodes(path string, a, b *node) string {
+	path += "/" + a.Name
+	if a.Name != b.Name {
+		return fmt.Sprintf("%v: Found '%v'", path, b.Name)
+	}
+	if len(a.Props) != len(b.Props) {
+		return fmt.Sprintf("%v: %d props, found %d", path, len(a.Props), len(b.Props))
+	}
+	for i := range a.Props {
+		if !equalProps(a.Props[i], b.Props[i]) {
+			return fmt.Sprintf("%v: Prop %d is %c %v, found %c %v", path, i, a.Props[i].Type, a.Props[i].Value, b.Props[i].Type, b.Props[i].Value)
+		}
+	}
+	if len(a.Nodes) != len(b.Nodes) {
+		return fmt.Sprintf("%v: %d nodes, found %d", path, len(a.Nodes), len(b.Nodes))
+	}
+	for i := range a.Nodes {
+		if diff := compareNodes(path, a.Nodes[i], b.Nodes[i]); diff != "" {
+			return diff
+		}
+	}
+	return ""
+}
+
+func equalProps(a, b *prop) bool {
+	if x, ok := toFloat64(a.Value); ok {
+		y, ok := toFloat64(b.Value)
+		return ok && x == y
+	}
+	if x := toFloat64s(a.Value); x != nil {
+		return a.Type == b.Type && reflect.DeepEqual(x, toFloat64s(b.Value))
+	}
+	return a.Type == b.Type && reflect.DeepEqual(a.Value, b.Value)
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func TestReadFormats(t *testing.T) {
+	want := readTestFile(t, meshFiles[0])
+	if len(want.Nodes) == 0 {
+		t.Fatalf("%v: No nodes", meshFiles[0])
+	}
+
+	for _, filename := range meshFiles[1:] {
+		got := readTestFile(t, filename)
+		if diff := compareNodes("", want, got); diff != "" {
+			t.Errorf("%v: %v", filename, diff)
+		}
+	}
+}
+
+func TestLoadFormats(t *testing.T) {
+	load := func(filename string) []*dusk.MeshData {
+		data, err := Load("testdata/" + filename)
+		if err != nil {
+			t.Fatalf("%v: %v", filename, err)
+		}
+		return data
+	}
+
+	want := load(meshFiles[0])
+	names := []string{"House/Walls", "House/Roof"}
+	if len(want) != len(names) {
+		t.Fatalf("%v: %d MeshData, expected %d", meshFiles[0], len(want), len(names))
+	}
+	for i, d := range want {
+		if d.Name != names[i] {
+			t.Errorf("%v: MeshData %d is named [%v], expected [%v]", meshFiles[0], i, d.Name, names[i])
+		}
+	}
+
+	for _, filename := range meshFiles[1:] {
+		got := load(filename)
+		if len(got) != len(want) {
+			t.Errorf("%v: %d MeshData, expected %d", filename, len(got), len(want))
+			continue
+		}
+
+		for i := range want {
+			a, b := want[i], got[i]
+			if a.Name != b.Name {
+				t.Errorf("%v: MeshData %d is named [%v], expected [%v]", filename, i, b.Name, a.Name)
+			}
+
+			fields := []struct {
+				name string
+				a, b interface{}
+			}{
+				{"Vertices", a.Vertices, b.Vertices},
+				{"Normals", a.Normals, b.Normals},
+				{"TexCoords", a.TexCoords, b.TexCoords},
+				{"Colors", a.Colors, b.Colors},
+				{"Polygons", a.Polygons, b.Polygons},
+				{"Diffuse", a.Material.Diffuse, b.Material.Diffuse},
+				{"Specular", a.Material.Specular, b.Material.Specular},
+				{"Shininess", a.Material.Shininess, b.Material.Shininess},
+			}
+			for _, f := range fields {
+				if !reflect.DeepEqual(f.a, f.b) {
+					t.Errorf("%v: %v of [%v] are %v, expected %v", filename, f.name, a.Name, f.b, f.a)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package fbx
+
+import (
+	"github.com/WhoBrokeTheBuild/GoDusk/dusk"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// layerElement is the data of a 'LayerElement' node of a Geometry, such as its normals or UVs
+//
+// The mapping controls what each value belongs to:
+//   - ByPolygonVertex, one value for each corner of each polygon
+//   - ByVertex (or ByVertice, ByControlPoint), one value for each control point, shared by the polygons using it
+//   - ByPolygon, one value for each polygon
+//   - AllSame, a single value for the whole Geometry
+//
+// The reference controls how the values are found, either Direct, or IndexToDirect through a separate index array.
+type layerElement struct {
+	mapping string
+	direct  bool
+	size    int
+	values  []float64
+	indices []int32
+}
+
+// readLayerElement reads the first layer element node of a Geometry, or returns nil if there is none or it cannot be used
+// If indexName is empty, the values are read as Direct regardless of the reference, as with 'LayerElementMaterial'
+func readLayerElement(geomNode *node, name, valuesName, indexName string, size int) *layerElement {
+	layerNode := geomNode.findFirst(name)
+	if layerNode == nil {
+		return nil
+	}
+
+	le := &layerElement{
+		direct: true,
+		size:   size,
+	}
+
+	mapNode := layerNode.findFirst("MappingInformationType")
+	if mapNode == nil || len(mapNode.Props) == 0 {
+		dusk.Warnf("No 'MappingInformationType' in '%v' node", name)
+		return nil
+	}
+	le.mapping, _ = mapNode.Props[0].Value.(string)
+
+	switch le.mapping {
+	case "ByPolygonVertex", "ByPolygon", "AllSame":
+	case "ByVertex", "ByVertice", "ByControlPoint":
+		le.mapping = "ByVertex"
+	default:
+		dusk.Warnf("Unsupported mapping [%v] in '%v' node", le.mapping, name)
+		return nil
+	}
+
+	if indexName != "" {
+		refNode := layerNode.findFirst("ReferenceInformationType")
+		if refNode == nil || len(refNode.Props) == 0 {
+			dusk.Warnf("No 'ReferenceInformationType' in '%v' node", name)
+			return nil
+		}
+
+		reference, _ := refNode.Props[0].Value.(string)
+		switch reference {
+		case "Direct":
+		case "IndexToDirect", "Index":
+			le.direct = false
+			indexNode := layerNode.findFirst(indexName)
+			if indexNode == nil || len(indexNode.Props) == 0 {
+				dusk.Warnf("No '%v' in '%v' node", indexName, name)
+				return nil
+			}
+			le.indices = toInt32s(indexNode.Props[0].Value)
+		default:
+			dusk.Warnf("Unsupported reference [%v] in '%v' node", reference, name)
+			return nil
+		}
+	}
+
+	valuesNode := layerNode.findFirst(valuesName)
+	if valuesNode == nil || len(valuesNode.Props) == 0 {
+		dusk.Warnf("No '%v' in '%v' node", valuesName, name)
+		return nil
+	}
+	le.values = toFloat64s(valuesNode.Props[0].Value)
+	if len(le.values) == 0 {
+		return nil
+	}
+
+	return le
+}
+
+// index returns the position in values for a corner of a polygon
+// polygonVertex is the index of the corner in 'PolygonVertexIndex', and controlPoint is the vertex it uses
+func (le *layerElement) index(polygonVertex, controlPoint, polygon int) (int, bool) {
+	i := 0
+	switch le.mapping {
+	case "ByPolygonVertex":
+		i = polygonVertex
+	case "ByVertex":
+		i = controlPoint
+	case "ByPolygon":
+		i = polygon
+	}
+
+	if !le.direct {
+		if i < 0 || i >= len(le.indices) {
+			return 0, false
+		}
+		i = int(le.indices[i])
+	}
+
+	if i < 0 || (i+1)*le.size > len(le.values) {
+		return 0, false
+	}
+	return i * le.size, true
+}
+
+// get returns the components of the value for a corner of a polygon, or zeros if it is missing
+func (le *layerElement) get(polygonVertex, controlPoint, polygon int) [4]float32 {
+	v := [4]float32{}
+	i, ok := le.index(polygonVertex, controlPoint, polygon)
+	if !ok {
+		return v
+	}
+	for c := 0; c < le.size && c < len(v); c++ {
+		v[c] = float32(le.values[i+c])
+	}
+	return v
+}
+
+func (le *layerElement) vec2(polygonVertex, controlPoint, polygon int) mgl32.Vec2 {
+	v := le.get(polygonVertex, controlPoint, polygon)
+	return mgl32.Vec2{v[0], v[1]}
+}
+
+func (le *layerElement) vec3(polygonVertex, controlPoint, polygon int) mgl32.Vec3 {
+	v := le.get(polygonVertex, controlPoint, polygon)
+	return mgl32.Vec3{v[0], v[1], v[2]}
+}
+
+func (le *layerElement) vec4(polygonVertex, controlPoint, polygon int) mgl32.Vec4 {
+	return mgl32.Vec4(le.get(polygonVertex, controlPoint, polygon))
+}
+
+// getInt returns a single integer value for a polygon, such as a material index, or -1 if it is missing
+func (le *layerElement) getInt(polygonVertex, controlPoint, polygon int) int {
+	i, ok := le.index(polygonVertex, controlPoint, polygon)
+	if !ok {
+		return -1
+	}
+	return int(le.values[i])
+}
+
+// toFloat64s converts any numeric array property to []float64
+func toFloat64s(value interface{}) []float64 {
+	switch v := value.(type) {
+	case []float64:
+		return v
+	case []float32:
+		out := make([]float64, len(v))
+		for i := range v {
+			out[i] = float64(v[i])
+		}
+		return out
+	case []int32:
+		out := make([]float64, len(v))
+		for i := range v {
+			out[i] = float64(v[i])
+		}
+		return out
+	case []int64:
+		out := make([]float64, len(v))
+		for i := range v {
+			out[i] = float64(v[i])
+		}
+		return out
+	}
+	return nil
+}
+
+// toInt32s converts any integer array property to []int32
+func toInt32s(value interface{}) []int32 {
+	switch v := value.(type) {
+	case []int32:
+		return v
+	case []int64:
+		out := make([]int32, len(v))
+		for i := range v {
+			out[i] = int32(v[i])
+		}
+		return out
+	}
+	return nil
+}
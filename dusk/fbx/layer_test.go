@@ -0,0 +1,170 @@
+package fbx
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+// readTestFile parses a file in testdata, in either format
+func readTestFile(t *testing.T, filename string) *node {
+	t.Helper()
+	file, err := ioutil.ReadFile("testdata/" + filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var root *node
+	if len(file) >= len(magic) && string(file[:len(magic)]) == magic {
+		root, err = readBinary(file)
+	} else {
+		root, err = readASCII(file)
+	}
+	if err != nil {
+		t.Fatalf("%v: %v", filename, err)
+	}
+	return root
+}
+
+// readTestGeometry returns the Geometry nodes of a file in testdata, by name
+func readTestGeometry(t *testing.T, filename string) map[string]*node {
+	t.Helper()
+	objNode := readTestFile(t, filename).findFirst("Objects")
+	if objNode == nil {
+		t.Fatalf("%v: No 'Objects' node", filename)
+	}
+
+	geoms := map[string]*node{}
+	for _, geomNode := range objNode.findAll("Geometry") {
+		geoms[nodeName(geomNode)] = geomNode
+	}
+	return geoms
+}
+
+// layerCorners are the corners of the two triangles in testdata/layers.fbx, as their polygon vertex, control point and polygon
+var layerCorners = [][3]int{
+	{0, 0, 0}, {1, 1, 0}, {2, 2, 0},
+	{3, 2, 1}, {4, 1, 1}, {5, 3, 1},
+}
+
+// layerSlot returns which value a corner uses for a mapping
+func layerSlot(mapping string, corner [3]int) int {
+	switch mapping {
+	case "ByPolygonVertex":
+		return corner[0]
+	case "ByVertice", "ByControlPoint":
+		return corner[1]
+	case "ByPolygon":
+		return corner[2]
+	}
+	return 0
+}
+
+func TestReadLayerElement(t *testing.T) {
+	geoms := readTestGeometry(t, "layers.fbx")
+
+	// The component c of the value in slot k is base + k*10 + c
+	elements := []struct {
+		name, values, index string
+		size                int
+		base                float32
+		get                 func(le *layerElement, pv, cp, polygon int) []float32
+	}{
+		{"LayerElementNormal", "Normals", "NormalsIndex", 3, 100, func(le *layerElement, pv, cp, polygon int) []float32 {
+			v := le.vec3(pv, cp, polygon)
+			return v[:]
+		}},
+		{"LayerElementUV", "UV", "UVIndex", 2, 200, func(le *layerElement, pv, cp, polygon int) []float32 {
+			v := le.vec2(pv, cp, polygon)
+			return v[:]
+		}},
+		{"LayerElementColor", "Colors", "ColorIndex", 4, 300, func(le *layerElement, pv, cp, polygon int) []float32 {
+			v := le.vec4(pv, cp, polygon)
+			return v[:]
+		}},
+		{"LayerElementTangent", "Tangents", "TangentsIndex", 3, 400, func(le *layerElement, pv, cp, polygon int) []float32 {
+			v := le.vec3(pv, cp, polygon)
+			return v[:]
+		}},
+		{"LayerElementBinormal", "Binormals", "BinormalsIndex", 3, 500, func(le *layerElement, pv, cp, polygon int) []float32 {
+			v := le.vec3(pv, cp, polygon)
+			return v[:]
+		}},
+	}
+
+	for _, mapping := range []string{"ByPolygonVertex", "ByVertice", "ByControlPoint", "ByPolygon", "AllSame"} {
+		for _, reference := range []string{"Direct", "IndexToDirect"} {
+			name := mapping + "_" + reference
+			geomNode := geoms[name]
+			if geomNode == nil {
+				t.Fatalf("No Geometry [%v]", name)
+			}
+
+			for _, e := range elements {
+				le := readLayerElement(geomNode, e.name, e.values, e.index, e.size)
+				if le == nil {
+					t.Errorf("%v: Failed to read '%v'", name, e.name)
+					continue
+				}
+				if le.direct != (reference == "Direct") {
+					t.Errorf("%v: '%v' has direct %v", name, e.name, le.direct)
+				}
+
+				for i, corner := range layerCorners {
+					got := e.get(le, corner[0], corner[1], corner[2])
+					for c := 0; c < e.size; c++ {
+						want := e.base + float32(layerSlot(mapping, corner)*10+c)
+						if got[c] != want {
+							t.Errorf("%v: '%v' of corner %d is %v, expected component %d to be %v", name, e.name, i, got, c, want)
+							break
+						}
+					}
+				}
+			}
+
+			// Material indices ignore the reference, and are always Direct
+			materials := readLayerElement(geomNode, "LayerElementMaterial", "Materials", "", 1)
+			if materials == nil {
+				t.Errorf("%v: Failed to read 'LayerElementMaterial'", name)
+				continue
+			}
+			for i, corner := range layerCorners {
+				got := materials.getInt(corner[0], corner[1], corner[2])
+				if want := layerSlot(mapping, corner); got != want {
+					t.Errorf("%v: Material of corner %d is %d, expected %d", name, i, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestReadLayerElementInvalid(t *testing.T) {
+	geoms := readTestGeometry(t, "layers.fbx")
+	geomNode := geoms["Invalid"]
+	if geomNode == nil {
+		t.Fatalf("No Geometry [Invalid]")
+	}
+
+	tests := []struct {
+		desc, name, values, index string
+		size                      int
+	}{
+		{"unsupported mapping", "LayerElementNormal", "Normals", "NormalsIndex", 3},
+		{"missing index", "LayerElementUV", "UV", "UVIndex", 2},
+		{"missing values", "LayerElementColor", "Colors", "ColorIndex", 4},
+		{"missing element", "LayerElementTangent", "Tangents", "TangentsIndex", 3},
+	}
+	for _, test := range tests {
+		if le := readLayerElement(geomNode, test.name, test.values, test.index, test.size); le != nil {
+			t.Errorf("Read '%v' with %v", test.name, test.desc)
+		}
+	}
+
+	// Values outside of the arrays are zero
+	le := readLayerElement(geoms["ByPolygon_IndexToDirect"], "LayerElementNormal", "Normals", "NormalsIndex", 3)
+	if le == nil {
+		t.Fatalf("Failed to read 'LayerElementNormal'")
+	}
+	if v := le.vec3(0, 0, 2); v.Len() != 0 {
+		t.Errorf("Value of a missing polygon is %v, expected zero", v)
+	}
+}
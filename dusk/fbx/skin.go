@@ -0,0 +1,496 @@
+package fbx
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/WhoBrokeTheBuild/GoDusk/dusk"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// ticksPerSecond is the number of FBX time units (KTime) in one second
+const ticksPerSecond = 46186158000
+
+// connIndex finds the connections of a node without searching every connection
+type connIndex struct {
+	// to holds the connections to each node, from its children
+	to map[*node][]*conn
+	// from holds the connections from each node, to its parents
+	from map[*node][]*conn
+}
+
+func newConnIndex(conns []*conn) *connIndex {
+	ci := &connIndex{
+		to:   map[*node][]*conn{},
+		from: map[*node][]*conn{},
+	}
+	for _, c := range conns {
+		if c.A == nil || c.B == nil {
+			continue
+		}
+		ci.to[c.B] = append(ci.to[c.B], c)
+		ci.from[c.A] = append(ci.from[c.A], c)
+	}
+	return ci
+}
+
+// children returns the connections to n from nodes with the given name
+func (ci *connIndex) children(n *node, name string) []*conn {
+	found := []*conn{}
+	for _, c := range ci.to[n] {
+		if c.A.Name == name {
+			found = append(found, c)
+		}
+	}
+	return found
+}
+
+// parent returns the first node with the given name that n is connected to, or nil
+func (ci *connIndex) parent(n *node, name string) *node {
+	for _, c := range ci.from[n] {
+		if c.B.Name == name {
+			return c.B
+		}
+	}
+	return nil
+}
+
+// nodeName returns the name of an object, without its "\x00\x01Class" suffix
+func nodeName(n *node) string {
+	if len(n.Props) < 2 {
+		return ""
+	}
+	name, _ := n.Props[1].Value.(string)
+	if i := strings.Index(name, "\x00\x01"); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// nodeClass returns the sub-type of an object, such as "Skin", "Cluster" or "LimbNode"
+func nodeClass(n *node) string {
+	if len(n.Props) < 3 {
+		return ""
+	}
+	class, _ := n.Props[2].Value.(string)
+	return class
+}
+
+// nodeMatrix returns the matrix stored in the named child of n, and whether it was found
+func nodeMatrix(n *node, name string) (mgl32.Mat4, bool) {
+	m := mgl32.Ident4()
+	child := n.findFirst(name)
+	if child == nil || len(child.Props) == 0 {
+		return m, false
+	}
+	values, ok := child.Props[0].Value.([]float64)
+	if !ok || len(values) != 16 {
+		return m, false
+	}
+	// FBX matrices are column-major, like mgl32
+	for i := range values {
+		m[i] = float32(values[i])
+	}
+	return m, true
+}
+
+type boneWeight struct {
+	bone   int
+	weight float32
+}
+
+// skin is the binding of a Geometry to the Skeleton
+type skin struct {
+	// bind is the matrix of the Geometry when it was bound to the Skeleton
+	bind mgl32.Mat4
+	// weights are the bones affecting each control point
+	weights map[int][]boneWeight
+}
+
+// getBones returns the four bones with the most weight for a control point, with their weights adding up to one
+// Bones past dusk.MaxBones can't be passed to the shader, so their weight is given to the others
+func (s *skin) getBones(point int) (mgl32.Vec4, mgl32.Vec4) {
+	weights := []boneWeight{}
+	for _, w := range s.weights[point] {
+		if w.bone < dusk.MaxBones {
+			weights = append(weights, w)
+		}
+	}
+	sort.SliceStable(weights, func(i, j int) bool {
+		return weights[i].weight > weights[j].weight
+	})
+
+	indices := mgl32.Vec4{}
+	values := mgl32.Vec4{}
+	total := float32(0)
+	for i := 0; i < 4 && i < len(weights); i++ {
+		indices[i] = float32(weights[i].bone)
+		values[i] = weights[i].weight
+		total += weights[i].weight
+	}
+	if total > 0 {
+		values = values.Mul(1 / total)
+	}
+	return indices, values
+}
+
+// loadSkeleton builds a Skeleton from every bone used by a Skin deformer, and the skins of each Geometry
+// Bones are returned as a map of Model nodes to their index in the Skeleton
+// The conversion to the engine's axes and units is applied to the root bones, and to the bind matrix of each skin
+func loadSkeleton(objNode *node, ci *connIndex, template propMap, conversion mgl32.Mat4) (*dusk.Skeleton, map[*node]int, map[*node]*skin) {
+	skeleton := &dusk.Skeleton{}
+	bones := map[*node]int{}
+	skins := map[*node]*skin{}
+
+	// The bind matrix of each bone, in the same space as the skinned vertices
+	binds := map[*node]mgl32.Mat4{}
+
+	type cluster struct {
+		skin    *skin
+		bone    *node
+		indexes []int32
+		weights []float64
+	}
+	clusters := []cluster{}
+
+	for _, skinNode := range objNode.findAll("Deformer") {
+		if nodeClass(skinNode) != "Skin" {
+			continue
+		}
+		geomNode := ci.parent(skinNode, "Geometry")
+		if geomNode == nil {
+			continue
+		}
+
+		sk := &skin{
+			bind:    conversion,
+			weights: map[int][]boneWeight{},
+		}
+		skins[geomNode] = sk
+
+		for _, c := range ci.children(skinNode, "Deformer") {
+			clusterNode := c.A
+			if nodeClass(clusterNode) != "Cluster" {
+				continue
+			}
+
+			boneConns := ci.children(clusterNode, "Model")
+			if len(boneConns) == 0 {
+				dusk.Warnf("No bone connected to cluster [%v]", nodeName(clusterNode))
+				continue
+			}
+			bone := boneConns[0].A
+
+			if m, found := nodeMatrix(clusterNode, "Transform"); found {
+				sk.bind = conversion.Mul4(m)
+			}
+			if m, found := nodeMatrix(clusterNode, "TransformLink"); found {
+				if _, exists := binds[bone]; !exists {
+					binds[bone] = conversion.Mul4(m)
+				}
+			}
+
+			cl := cluster{skin: sk, bone: bone}
+			if n := clusterNode.findFirst("Indexes"); n != nil && len(n.Props) > 0 {
+				cl.indexes, _ = n.Props[0].Value.([]int32)
+			}
+			if n := clusterNode.findFirst("Weights"); n != nil && len(n.Props) > 0 {
+				cl.weights, _ = n.Props[0].Value.([]float64)
+			}
+			clusters = append(clusters, cl)
+		}
+	}
+
+	if len(clusters) == 0 {
+		return nil, nil, nil
+	}
+
+	globals := []mgl32.Mat4{}
+
+	// addBone adds a bone after all of its parents, and returns its index
+	var addBone func(n *node) int
+	addBone = func(n *node) int {
+		if i, found := bones[n]; found {
+			return i
+		}
+
+		parent := -1
+		parentGlobal := conversion
+		if p := ci.parent(n, "Model"); p != nil {
+			parent = addBone(p)
+			parentGlobal = globals[parent]
+		}
+
+		global, found := binds[n]
+		if !found {
+			global = parentGlobal.Mul4(readModelTransform(n, template).localMatrix())
+		}
+
+		// Root bones keep the conversion in their local transform, since they have no parent to inherit it from
+		local := global
+		if parent >= 0 {
+			local = parentGlobal.Inv().Mul4(global)
+		}
+		t, r, s := dusk.DecomposeMatrix(local)
+
+		i := len(skeleton.Bones)
+		skeleton.Bones = append(skeleton.Bones, dusk.Bone{
+			Name:        nodeName(n),
+			Parent:      parent,
+			Offset:      global.Inv(),
+			Translation: t,
+			Rotation:    r,
+			Scale:       s,
+		})
+		globals = append(globals, global)
+		bones[n] = i
+		return i
+	}
+
+	for _, cl := range clusters {
+		bone := addBone(cl.bone)
+		for i := 0; i < len(cl.indexes) && i < len(cl.weights); i++ {
+			point := int(cl.indexes[i])
+			cl.skin.weights[point] = append(cl.skin.weights[point], boneWeight{
+				bone:   bone,
+				weight: float32(cl.weights[i]),
+			})
+		}
+	}
+
+	if len(skeleton.Bones) > dusk.MaxBones {
+		dusk.Warnf("Skeleton has %d bones, only the first %d are used for skinning", len(skeleton.Bones), dusk.MaxBones)
+	}
+
+	return skeleton, bones, skins
+}
+
+// curve is a single animated value
+type curve struct {
+	times  []int64
+	values []float32
+}
+
+func newCurve(n *node) *curve {
+	c := &curve{}
+	if timeNode := n.findFirst("KeyTime"); timeNode != nil && len(timeNode.Props) > 0 {
+		c.times, _ = timeNode.Props[0].Value.([]int64)
+	}
+	if valueNode := n.findFirst("KeyValueFloat"); valueNode != nil && len(valueNode.Props) > 0 {
+		switch values := valueNode.Props[0].Value.(type) {
+		case []float32:
+			c.values = values
+		case []float64:
+			c.values = make([]float32, len(values))
+			for i := range values {
+				c.values[i] = float32(values[i])
+			}
+		}
+	}
+	if len(c.values) < len(c.times) {
+		c.times = c.times[:len(c.values)]
+	}
+	return c
+}
+
+// eval returns the value of the curve at a time, interpolating linearly between keys
+func (c *curve) eval(time int64) float32 {
+	i := sort.Search(len(c.times), func(i int) bool {
+		return c.times[i] > time
+	})
+	if i == 0 {
+		return c.values[0]
+	}
+	if i == len(c.times) {
+		return c.values[i-1]
+	}
+	f := float32(time-c.times[i-1]) / float32(c.times[i]-c.times[i-1])
+	return c.values[i-1] + (c.values[i]-c.values[i-1])*f
+}
+
+// curveNode is the three curves of an animated Vec3 property
+type curveNode struct {
+	bone     *node
+	property string
+	defaults mgl32.Vec3
+	curves   [3]*curve
+}
+
+// times returns the sorted times of every key of the three curves
+func (cn *curveNode) times() []int64 {
+	unique := map[int64]bool{}
+	for _, c := range cn.curves {
+		if c == nil {
+			continue
+		}
+		for _, t := range c.times {
+			unique[t] = true
+		}
+	}
+	return sortedTimes(unique)
+}
+
+// sortedTimes returns the keys of a set of times in order
+func sortedTimes(unique map[int64]bool) []int64 {
+	times := make([]int64, 0, len(unique))
+	for t := range unique {
+		times = append(times, t)
+	}
+	sort.Slice(times, func(i, j int) bool {
+		return times[i] < times[j]
+	})
+	return times
+}
+
+// eval returns the value of the property at a time
+func (cn *curveNode) eval(time int64) mgl32.Vec3 {
+	v := cn.defaults
+	for i, c := range cn.curves {
+		if c != nil && len(c.values) > 0 {
+			v[i] = c.eval(time)
+		}
+	}
+	return v
+}
+
+// loadAnimations returns an AnimationClip for each AnimationStack, with channels for the bones of the Skeleton
+// Every key combines the animated properties with the rest of the bone's transform, so pivots and pre-rotations are kept
+func loadAnimations(objNode *node, ci *connIndex, skeleton *dusk.Skeleton, bones map[*node]int, template propMap, conversion mgl32.Mat4) []*dusk.AnimationClip {
+	clips := []*dusk.AnimationClip{}
+
+	for _, stackNode := range objNode.findAll("AnimationStack") {
+		layerConns := ci.children(stackNode, "AnimationLayer")
+		if len(layerConns) == 0 {
+			continue
+		}
+		if len(layerConns) > 1 {
+			dusk.Warnf("Animation [%v] has %d layers, only the first is used", nodeName(stackNode), len(layerConns))
+		}
+
+		nodes := []*curveNode{}
+		start := int64(math.MaxInt64)
+		for _, c := range ci.children(layerConns[0].A, "AnimationCurveNode") {
+			cn := &curveNode{}
+			for _, target := range ci.from[c.A] {
+				if target.Type == "OP" && target.B.Name == "Model" {
+					cn.bone = target.B
+					cn.property = target.Bind
+				}
+			}
+			if _, found := bones[cn.bone]; !found {
+				continue
+			}
+
+			if p70Node := c.A.findFirst("Properties70"); p70Node != nil {
+				pMap := newPropMap(p70Node)
+				for i, axis := range []string{"d|X", "d|Y", "d|Z"} {
+					if value, found := pMap[axis].(float32); found {
+						cn.defaults[i] = value
+					}
+				}
+			}
+
+			for _, cc := range ci.children(c.A, "AnimationCurve") {
+				axis := -1
+				switch cc.Bind {
+				case "d|X":
+					axis = 0
+				case "d|Y":
+					axis = 1
+				case "d|Z":
+					axis = 2
+				}
+				if axis < 0 {
+					continue
+				}
+				cn.curves[axis] = newCurve(cc.A)
+				if len(cn.curves[axis].times) > 0 && cn.curves[axis].times[0] < start {
+					start = cn.curves[axis].times[0]
+				}
+			}
+			nodes = append(nodes, cn)
+		}
+
+		if len(nodes) == 0 {
+			continue
+		}
+
+		clip := &dusk.AnimationClip{
+			Name: nodeName(stackNode),
+		}
+		seconds := func(t int64) float32 {
+			return float32(float64(t-start) / ticksPerSecond)
+		}
+
+		// The animated properties of each bone
+		properties := map[*node]map[string]*curveNode{}
+		for _, cn := range nodes {
+			if properties[cn.bone] == nil {
+				properties[cn.bone] = map[string]*curveNode{}
+			}
+			properties[cn.bone][cn.property] = cn
+		}
+
+		channels := map[*node]*dusk.AnimationChannel{}
+		for bone, props := range properties {
+			mt := readModelTransform(bone, template)
+			root := skeleton.Bones[bones[bone]].Parent < 0
+
+			unique := map[int64]bool{}
+			for _, cn := range props {
+				for _, t := range cn.times() {
+					unique[t] = true
+				}
+			}
+			times := sortedTimes(unique)
+
+			eval := func(property string, time int64, def mgl32.Vec3) mgl32.Vec3 {
+				if cn, found := props[property]; found {
+					return cn.eval(time)
+				}
+				return def
+			}
+
+			ch := &dusk.AnimationChannel{Bone: nodeName(bone)}
+			for _, time := range times {
+				local := mt.localMatrixAt(
+					eval("Lcl Translation", time, mt.translation),
+					eval("Lcl Rotation", time, mt.rotation),
+					eval("Lcl Scaling", time, mt.scaling),
+				)
+				if root {
+					local = conversion.Mul4(local)
+				}
+
+				t, r, s := dusk.DecomposeMatrix(local)
+				ch.Translations = append(ch.Translations, dusk.VectorKey{Time: seconds(time), Value: t})
+				ch.Rotations = append(ch.Rotations, dusk.QuatKey{Time: seconds(time), Value: r})
+				ch.Scales = append(ch.Scales, dusk.VectorKey{Time: seconds(time), Value: s})
+			}
+			channels[bone] = ch
+		}
+
+		// Keep the channels in the order of the Skeleton
+		for _, bone := range sortedBones(channels, bones) {
+			clip.Channels = append(clip.Channels, *channels[bone])
+		}
+		clip.ComputeDuration()
+
+		dusk.Verbosef("Loaded Animation [%v] (%.2fs, %d channels)", clip.Name, clip.Duration, len(clip.Channels))
+		clips = append(clips, clip)
+	}
+
+	return clips
+}
+
+func sortedBones(channels map[*node]*dusk.AnimationChannel, bones map[*node]int) []*node {
+	sorted := make([]*node, 0, len(channels))
+	for bone := range channels {
+		sorted = append(sorted, bone)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return bones[sorted[i]] < bones[sorted[j]]
+	})
+	return sorted
+}
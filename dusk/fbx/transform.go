@@ -0,0 +1,171 @@
+package fbx
+
+import (
+	"github.com/WhoBrokeTheBuild/GoDusk/dusk"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// eulerAxes are the axes of each FBX RotationOrder, in the order they are applied
+var eulerAxes = [][3]int{
+	{0, 1, 2}, // XYZ
+	{0, 2, 1}, // XZY
+	{1, 2, 0}, // YZX
+	{1, 0, 2}, // YXZ
+	{2, 0, 1}, // ZXY
+	{2, 1, 0}, // ZYX
+}
+
+// eulerToQuat returns the rotation of euler angles in degrees, applied in the given FBX RotationOrder
+// SphericXYZ and unknown orders are treated as the default XYZ
+func eulerToQuat(v mgl32.Vec3, order int) mgl32.Quat {
+	if order < 0 || order >= len(eulerAxes) {
+		order = 0
+	}
+	q := mgl32.QuatIdent()
+	for _, axis := range eulerAxes[order] {
+		unit := mgl32.Vec3{}
+		unit[axis] = 1
+		q = mgl32.QuatRotate(mgl32.DegToRad(v[axis]), unit).Mul(q)
+	}
+	return q
+}
+
+// modelTransform is the local transform of a Model node, made of the same properties as in the FBX SDK
+// Children are combined with their parents by matrix multiplication, which matches every InheritType unless a parent has non-uniform scale
+type modelTransform struct {
+	translation mgl32.Vec3
+	rotation    mgl32.Vec3
+	scaling     mgl32.Vec3
+
+	rotationOrder int
+	preRotation   mgl32.Quat
+	postRotation  mgl32.Quat
+
+	rotationOffset mgl32.Vec3
+	rotationPivot  mgl32.Vec3
+	scalingOffset  mgl32.Vec3
+	scalingPivot   mgl32.Vec3
+
+	// geometric is only applied to the Geometry of the Model, and is not inherited by its children
+	geometric mgl32.Mat4
+}
+
+// readModelTransform reads the transform properties of a Model node, with defaults from the Model's PropertyTemplate
+func readModelTransform(n *node, template propMap) *modelTransform {
+	pMap := readProps(n, template)
+
+	vec := func(name string, def mgl32.Vec3) mgl32.Vec3 {
+		if value, found := pMap[name].(mgl32.Vec3); found {
+			return value
+		}
+		return def
+	}
+	one := mgl32.Vec3{1, 1, 1}
+
+	mt := &modelTransform{
+		translation:    vec("Lcl Translation", mgl32.Vec3{}),
+		rotation:       vec("Lcl Rotation", mgl32.Vec3{}),
+		scaling:        vec("Lcl Scaling", one),
+		preRotation:    eulerToQuat(vec("PreRotation", mgl32.Vec3{}), 0),
+		postRotation:   eulerToQuat(vec("PostRotation", mgl32.Vec3{}), 0),
+		rotationOffset: vec("RotationOffset", mgl32.Vec3{}),
+		rotationPivot:  vec("RotationPivot", mgl32.Vec3{}),
+		scalingOffset:  vec("ScalingOffset", mgl32.Vec3{}),
+		scalingPivot:   vec("ScalingPivot", mgl32.Vec3{}),
+	}
+	if value, found := pMap["RotationOrder"].(int); found {
+		mt.rotationOrder = value
+	}
+
+	gt := vec("GeometricTranslation", mgl32.Vec3{})
+	gr := vec("GeometricRotation", mgl32.Vec3{})
+	gs := vec("GeometricScaling", one)
+	mt.geometric = mgl32.Translate3D(gt[0], gt[1], gt[2]).
+		Mul4(eulerToQuat(gr, 0).Mat4()).
+		Mul4(mgl32.Scale3D(gs[0], gs[1], gs[2]))
+
+	return mt
+}
+
+// localMatrix returns the transform of the Model relative to its parent
+func (mt *modelTransform) localMatrix() mgl32.Mat4 {
+	return mt.localMatrixAt(mt.translation, mt.rotation, mt.scaling)
+}
+
+// localMatrixAt returns the transform of the Model relative to its parent, with animated translation, rotation and scaling
+// The order is T * Roff * Rp * Rpre * R * Rpost^-1 * Rp^-1 * Soff * Sp * S * Sp^-1
+func (mt *modelTransform) localMatrixAt(t, r, s mgl32.Vec3) mgl32.Mat4 {
+	translate := func(v mgl32.Vec3) mgl32.Mat4 {
+		return mgl32.Translate3D(v[0], v[1], v[2])
+	}
+	rotate := mt.preRotation.Mul(eulerToQuat(r, mt.rotationOrder)).Mul(mt.postRotation.Inverse())
+
+	return translate(t).
+		Mul4(translate(mt.rotationOffset)).
+		Mul4(translate(mt.rotationPivot)).
+		Mul4(rotate.Mat4()).
+		Mul4(translate(mt.rotationPivot.Mul(-1))).
+		Mul4(translate(mt.scalingOffset)).
+		Mul4(translate(mt.scalingPivot)).
+		Mul4(mgl32.Scale3D(s[0], s[1], s[2])).
+		Mul4(translate(mt.scalingPivot.Mul(-1)))
+}
+
+// readAxisConversion returns the matrix that converts from the axes and units in the 'GlobalSettings' node
+// to those of the engine, with Y up, Z towards the viewer and distances in meters
+func readAxisConversion(root *node) mgl32.Mat4 {
+	up, upSign := 1, 1
+	front, frontSign := 2, 1
+	coord, coordSign := 0, 1
+	// FBX units are centimeters, scaled by UnitScaleFactor
+	unit := float32(1)
+
+	if settingsNode := root.findFirst("GlobalSettings"); settingsNode != nil {
+		if p70Node := settingsNode.findFirst("Properties70"); p70Node != nil {
+			pMap := newPropMap(p70Node)
+			for name, value := range map[string]*int{
+				"UpAxis":        &up,
+				"UpAxisSign":    &upSign,
+				"FrontAxis":     &front,
+				"FrontAxisSign": &frontSign,
+				"CoordAxis":     &coord,
+				"CoordAxisSign": &coordSign,
+			} {
+				if v, found := pMap[name].(int); found {
+					*value = v
+				}
+			}
+			if value, found := pMap["UnitScaleFactor"].(float32); found && value > 0 {
+				unit = value
+			}
+		}
+	}
+
+	scale := unit / 100
+	valid := func(axis int) bool {
+		return axis >= 0 && axis <= 2
+	}
+	if !valid(up) || !valid(front) || !valid(coord) || up == front || up == coord || front == coord {
+		dusk.Warnf("Invalid axes in 'GlobalSettings' node, assuming Y up")
+		return mgl32.Scale3D(scale, scale, scale)
+	}
+
+	sign := func(s int) float32 {
+		if s < 0 {
+			return -scale
+		}
+		return scale
+	}
+
+	// Each row picks the file's axis that becomes the engine's X, Y and Z
+	m := mgl32.Mat4{}
+	m.Set(0, coord, sign(coordSign))
+	m.Set(1, up, sign(upSign))
+	m.Set(2, front, sign(frontSign))
+	m.Set(3, 3, 1)
+
+	if m.Mat3().Det() < 0 {
+		dusk.Warnf("FBX uses a left-handed coordinate system, the Scene will be mirrored")
+	}
+	return m
+}
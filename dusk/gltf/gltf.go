@@ -0,0 +1,853 @@
+package gltf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/WhoBrokeTheBuild/GoDusk/dusk"
+	"github.com/WhoBrokeTheBuild/GoDusk/dusk/meshutil"
+)
+
+func init() {
+	dusk.RegisterModelFormat("gltf", []string{".gltf", ".glb"}, Load)
+	dusk.RegisterSceneFormat("gltf", []string{".gltf", ".glb"}, LoadScene)
+	dusk.RegisterFunc(loadEmbedded)
+}
+
+const (
+	glbMagic     uint32 = 0x46546C67 // "glTF"
+	glbChunkJSON uint32 = 0x4E4F534A // "JSON"
+	glbChunkBIN  uint32 = 0x004E4942 // "BIN\0"
+
+	componentByte          = 5120
+	componentUnsignedByte  = 5121
+	componentShort         = 5122
+	componentUnsignedShort = 5123
+	componentUnsignedInt   = 5125
+	componentFloat         = 5126
+
+	modeTriangles = 4
+)
+
+var typeSizes = map[string]int{
+	"SCALAR": 1,
+	"VEC2":   2,
+	"VEC3":   3,
+	"VEC4":   4,
+	"MAT2":   4,
+	"MAT3":   9,
+	"MAT4":   16,
+}
+
+// _embedded holds images stored inside a .glb or data: URI while their Material is loading
+var _embedded = map[string][]byte{}
+
+// loadEmbedded is a dusk.LoadFunc that serves embedded images to dusk.NewTextureFromFile
+func loadEmbedded(filename string) ([]byte, error) {
+	if b, found := _embedded[filename]; found {
+		return b, nil
+	}
+	return nil, fmt.Errorf("No embedded asset [%v]", filename)
+}
+
+type document struct {
+	Scene       *int         `json:"scene"`
+	Scenes      []scene      `json:"scenes"`
+	Nodes       []gltfNode   `json:"nodes"`
+	Meshes      []mesh       `json:"meshes"`
+	Accessors   []accessor   `json:"accessors"`
+	BufferViews []bufferView `json:"bufferViews"`
+	Buffers     []buffer     `json:"buffers"`
+	Materials   []material   `json:"materials"`
+	Textures    []texture    `json:"textures"`
+	Images      []image      `json:"images"`
+	Cameras     []camera     `json:"cameras"`
+
+	Extensions struct {
+		LightsPunctual *struct {
+			Lights []light `json:"lights"`
+		} `json:"KHR_lights_punctual"`
+	} `json:"extensions"`
+}
+
+type scene struct {
+	Nodes []int `json:"nodes"`
+}
+
+type gltfNode struct {
+	Name        string       `json:"name"`
+	Children    []int        `json:"children"`
+	Mesh        *int         `json:"mesh"`
+	Matrix      *[16]float32 `json:"matrix"`
+	Translation *[3]float32  `json:"translation"`
+	Rotation    *[4]float32  `json:"rotation"`
+	Scale       *[3]float32  `json:"scale"`
+	Camera      *int         `json:"camera"`
+
+	Extensions struct {
+		LightsPunctual *struct {
+			Light int `json:"light"`
+		} `json:"KHR_lights_punctual"`
+	} `json:"extensions"`
+}
+
+type camera struct {
+	Type        string `json:"type"`
+	Perspective *struct {
+		AspectRatio float32 `json:"aspectRatio"`
+		YFov        float32 `json:"yfov"`
+		ZNear       float32 `json:"znear"`
+		ZFar        float32 `json:"zfar"`
+	} `json:"perspective"`
+	Orthographic *struct {
+		XMag  float32 `json:"xmag"`
+		YMag  float32 `json:"ymag"`
+		ZNear float32 `json:"znear"`
+		ZFar  float32 `json:"zfar"`
+	} `json:"orthographic"`
+}
+
+// light is a light from the KHR_lights_punctual extension
+type light struct {
+	Name      string      `json:"name"`
+	Type      string      `json:"type"`
+	Color     *[3]float32 `json:"color"`
+	Intensity *float32    `json:"intensity"`
+	Range     float32     `json:"range"`
+	Spot      *struct {
+		InnerConeAngle float32  `json:"innerConeAngle"`
+		OuterConeAngle *float32 `json:"outerConeAngle"`
+	} `json:"spot"`
+}
+
+type mesh struct {
+	Name       string      `json:"name"`
+	Primitives []primitive `json:"primitives"`
+}
+
+type primitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    *int           `json:"indices"`
+	Material   *int           `json:"material"`
+	Mode       *int           `json:"mode"`
+}
+
+type accessor struct {
+	BufferView    *int   `json:"bufferView"`
+	ByteOffset    int    `json:"byteOffset"`
+	ComponentType int    `json:"componentType"`
+	Normalized    bool   `json:"normalized"`
+	Count         int    `json:"count"`
+	Type          string `json:"type"`
+}
+
+type bufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	ByteStride int `json:"byteStride"`
+}
+
+type buffer struct {
+	URI        string `json:"uri"`
+	ByteLength int    `json:"byteLength"`
+}
+
+type textureInfo struct {
+	Index    int      `json:"index"`
+	Scale    *float32 `json:"scale"`
+	Strength *float32 `json:"strength"`
+}
+
+type material struct {
+	Name                 string `json:"name"`
+	PbrMetallicRoughness *struct {
+		BaseColorFactor          *[4]float32  `json:"baseColorFactor"`
+		BaseColorTexture         *textureInfo `json:"baseColorTexture"`
+		MetallicFactor           *float32     `json:"metallicFactor"`
+		RoughnessFactor          *float32     `json:"roughnessFactor"`
+		MetallicRoughnessTexture *textureInfo `json:"metallicRoughnessTexture"`
+	} `json:"pbrMetallicRoughness"`
+	NormalTexture    *textureInfo `json:"normalTexture"`
+	OcclusionTexture *textureInfo `json:"occlusionTexture"`
+	EmissiveTexture  *textureInfo `json:"emissiveTexture"`
+	EmissiveFactor   *[3]float32  `json:"emissiveFactor"`
+	AlphaMode        string       `json:"alphaMode"`
+}
+
+type texture struct {
+	Source *int `json:"source"`
+}
+
+type image struct {
+	URI        string `json:"uri"`
+	MimeType   string `json:"mimeType"`
+	BufferView *int   `json:"bufferView"`
+}
+
+type loader struct {
+	filename string
+	dir      string
+	doc      *document
+	buffers  [][]byte
+	images   []string
+
+	scene *dusk.Scene
+}
+
+// Load parses the file and returns its MeshData, with the transform of every node applied to its vertices
+func Load(filename string) ([]*dusk.MeshData, error) {
+	scene, err := LoadScene(filename)
+	if err != nil {
+		return nil, err
+	}
+	return scene.Flatten(), nil
+}
+
+// LoadScene parses the file and returns the hierarchy of nodes in its default scene
+func LoadScene(filename string) (*dusk.Scene, error) {
+	filename = filepath.Clean(filename)
+
+	file, err := dusk.Load(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &loader{
+		filename: filename,
+		dir:      filepath.Dir(filename),
+		doc:      &document{},
+		scene:    &dusk.Scene{},
+	}
+
+	var bin []byte
+	if len(file) >= 12 && binary.LittleEndian.Uint32(file) == glbMagic {
+		var js []byte
+		js, bin, err = readGLB(file)
+		if err != nil {
+			return nil, err
+		}
+		file = js
+	}
+
+	err = json.Unmarshal(file, l.doc)
+	if err != nil {
+		return nil, err
+	}
+
+	err = l.loadBuffers(bin)
+	if err != nil {
+		return nil, err
+	}
+
+	err = l.loadImages()
+	defer func() {
+		for _, name := range l.images {
+			delete(_embedded, name)
+		}
+	}()
+	if err != nil {
+		return nil, err
+	}
+
+	roots := []int{}
+	if l.doc.Scene != nil && *l.doc.Scene < len(l.doc.Scenes) {
+		roots = l.doc.Scenes[*l.doc.Scene].Nodes
+	} else if len(l.doc.Scenes) > 0 {
+		roots = l.doc.Scenes[0].Nodes
+	} else {
+		// No scenes, use every node that is not the child of another
+		isChild := make([]bool, len(l.doc.Nodes))
+		for _, n := range l.doc.Nodes {
+			for _, c := range n.Children {
+				if c < len(isChild) {
+					isChild[c] = true
+				}
+			}
+		}
+		for i := range l.doc.Nodes {
+			if !isChild[i] {
+				roots = append(roots, i)
+			}
+		}
+	}
+
+	for _, i := range roots {
+		err = l.loadNode(i, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return l.scene, nil
+}
+
+// readGLB splits a binary glTF into its JSON and BIN chunks
+func readGLB(file []byte) (js []byte, bin []byte, err error) {
+	length := int(binary.LittleEndian.Uint32(file[8:]))
+	if length > len(file) {
+		return nil, nil, fmt.Errorf("Truncated GLB file")
+	}
+
+	offset := 12
+	for offset+8 <= length {
+		chunkLen := int(binary.LittleEndian.Uint32(file[offset:]))
+		chunkType := binary.LittleEndian.Uint32(file[offset+4:])
+		offset += 8
+
+		if offset+chunkLen > length {
+			return nil, nil, fmt.Errorf("Truncated GLB chunk")
+		}
+
+		switch chunkType {
+		case glbChunkJSON:
+			js = file[offset : offset+chunkLen]
+		case glbChunkBIN:
+			bin = file[offset : offset+chunkLen]
+		}
+		offset += chunkLen
+	}
+
+	if js == nil {
+		return nil, nil, fmt.Errorf("GLB file has no JSON chunk")
+	}
+	return
+}
+
+// readURI returns the contents of a data: URI, or a file relative to the glTF
+func (l *loader) readURI(uri string) ([]byte, error) {
+	if strings.HasPrefix(uri, "data:") {
+		comma := strings.IndexByte(uri, ',')
+		if comma < 0 || !strings.HasSuffix(uri[:comma], ";base64") {
+			return nil, fmt.Errorf("Unsupported data URI in [%v]", l.filename)
+		}
+		return base64.StdEncoding.DecodeString(uri[comma+1:])
+	}
+	return dusk.Load(filepath.Join(l.dir, filepath.FromSlash(uri)))
+}
+
+func (l *loader) loadBuffers(bin []byte) error {
+	l.buffers = make([][]byte, len(l.doc.Buffers))
+	for i, b := range l.doc.Buffers {
+		if b.URI == "" {
+			if bin == nil {
+				return fmt.Errorf("Buffer %d has no data", i)
+			}
+			l.buffers[i] = bin
+			continue
+		}
+
+		data, err := l.readURI(b.URI)
+		if err != nil {
+			return err
+		}
+		l.buffers[i] = data
+	}
+	return nil
+}
+
+// loadImages resolves every image to a filename that dusk.Load can read, registering embedded images
+func (l *loader) loadImages() error {
+	for i, img := range l.doc.Images {
+		if img.BufferView != nil {
+			data, err := l.bufferViewData(*img.BufferView)
+			if err != nil {
+				return err
+			}
+			l.addEmbedded(i, data)
+		} else if strings.HasPrefix(img.URI, "data:") {
+			data, err := l.readURI(img.URI)
+			if err != nil {
+				return err
+			}
+			l.addEmbedded(i, data)
+		} else {
+			l.images = append(l.images, "")
+		}
+	}
+	return nil
+}
+
+func (l *loader) addEmbedded(index int, data []byte) {
+	name := fmt.Sprintf("%s#image%d", l.filename, index)
+	_embedded[name] = data
+	l.images = append(l.images, name)
+}
+
+// texturePath returns the file to load for a texture, or "" if it has none
+func (l *loader) texturePath(info *textureInfo) string {
+	if info == nil || info.Index < 0 || info.Index >= len(l.doc.Textures) {
+		return ""
+	}
+	src := l.doc.Textures[info.Index].Source
+	if src == nil || *src >= len(l.doc.Images) {
+		return ""
+	}
+	if l.images[*src] != "" {
+		return l.images[*src]
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(l.doc.Images[*src].URI))
+}
+
+func (l *loader) bufferViewData(index int) ([]byte, error) {
+	if index < 0 || index >= len(l.doc.BufferViews) {
+		return nil, fmt.Errorf("Invalid bufferView %d", index)
+	}
+	bv := l.doc.BufferViews[index]
+	if bv.Buffer >= len(l.buffers) || bv.ByteOffset+bv.ByteLength > len(l.buffers[bv.Buffer]) {
+		return nil, fmt.Errorf("Invalid bufferView %d", index)
+	}
+	return l.buffers[bv.Buffer][bv.ByteOffset : bv.ByteOffset+bv.ByteLength], nil
+}
+
+// readAccessor returns the accessor's values as float32, normalizing integer types if needed
+func (l *loader) readAccessor(index int) ([]float32, int, error) {
+	if index < 0 || index >= len(l.doc.Accessors) {
+		return nil, 0, fmt.Errorf("Invalid accessor %d", index)
+	}
+	acc := l.doc.Accessors[index]
+
+	comps, found := typeSizes[acc.Type]
+	if !found {
+		return nil, 0, fmt.Errorf("Unsupported accessor type [%v]", acc.Type)
+	}
+
+	values := make([]float32, acc.Count*comps)
+	if acc.BufferView == nil {
+		// Accessors without a bufferView are all zeros
+		return values, comps, nil
+	}
+
+	data, err := l.bufferViewData(*acc.BufferView)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var size int
+	switch acc.ComponentType {
+	case componentByte, componentUnsignedByte:
+		size = 1
+	case componentShort, componentUnsignedShort:
+		size = 2
+	case componentUnsignedInt, componentFloat:
+		size = 4
+	default:
+		return nil, 0, fmt.Errorf("Unsupported component type %d", acc.ComponentType)
+	}
+
+	stride := l.doc.BufferViews[*acc.BufferView].ByteStride
+	if stride == 0 {
+		stride = size * comps
+	}
+
+	if acc.Count > 0 && acc.ByteOffset+(acc.Count-1)*stride+size*comps > len(data) {
+		return nil, 0, fmt.Errorf("Accessor %d is out of range", index)
+	}
+
+	for i := 0; i < acc.Count; i++ {
+		for j := 0; j < comps; j++ {
+			b := data[acc.ByteOffset+i*stride+j*size:]
+
+			var v float32
+			switch acc.ComponentType {
+			case componentByte:
+				v = float32(int8(b[0]))
+				if acc.Normalized {
+					v = float32(math.Max(float64(v)/127.0, -1.0))
+				}
+			case componentUnsignedByte:
+				v = float32(b[0])
+				if acc.Normalized {
+					v /= 255.0
+				}
+			case componentShort:
+				v = float32(int16(binary.LittleEndian.Uint16(b)))
+				if acc.Normalized {
+					v = float32(math.Max(float64(v)/32767.0, -1.0))
+				}
+			case componentUnsignedShort:
+				v = float32(binary.LittleEndian.Uint16(b))
+				if acc.Normalized {
+					v /= 65535.0
+				}
+			case componentUnsignedInt:
+				v = float32(binary.LittleEndian.Uint32(b))
+			case componentFloat:
+				v = math.Float32frombits(binary.LittleEndian.Uint32(b))
+			}
+			values[i*comps+j] = v
+		}
+	}
+
+	return values, comps, nil
+}
+
+// readIndices returns the accessor's values as integer indices
+func (l *loader) readIndices(index int) ([]int, error) {
+	if index < 0 || index >= len(l.doc.Accessors) {
+		return nil, fmt.Errorf("Invalid accessor %d", index)
+	}
+	acc := l.doc.Accessors[index]
+	if acc.BufferView == nil {
+		return make([]int, acc.Count), nil
+	}
+
+	data, err := l.bufferViewData(*acc.BufferView)
+	if err != nil {
+		return nil, err
+	}
+
+	var size int
+	switch acc.ComponentType {
+	case componentUnsignedByte:
+		size = 1
+	case componentUnsignedShort:
+		size = 2
+	case componentUnsignedInt:
+		size = 4
+	default:
+		return nil, fmt.Errorf("Unsupported index type %d", acc.ComponentType)
+	}
+
+	if acc.ByteOffset+acc.Count*size > len(data) {
+		return nil, fmt.Errorf("Accessor %d is out of range", index)
+	}
+
+	r := bytes.NewReader(data[acc.ByteOffset:])
+	inds := make([]int, acc.Count)
+	for i := range inds {
+		switch size {
+		case 1:
+			var v uint8
+			binary.Read(r, binary.LittleEndian, &v)
+			inds[i] = int(v)
+		case 2:
+			var v uint16
+			binary.Read(r, binary.LittleEndian, &v)
+			inds[i] = int(v)
+		case 4:
+			var v uint32
+			binary.Read(r, binary.LittleEndian, &v)
+			inds[i] = int(v)
+		}
+	}
+	return inds, nil
+}
+
+// nodeMatrix returns the local transform of a node
+func nodeMatrix(n *gltfNode) mgl32.Mat4 {
+	if n.Matrix != nil {
+		return mgl32.Mat4(*n.Matrix)
+	}
+
+	m := mgl32.Ident4()
+	if n.Translation != nil {
+		t := n.Translation
+		m = m.Mul4(mgl32.Translate3D(t[0], t[1], t[2]))
+	}
+	if n.Rotation != nil {
+		r := n.Rotation
+		q := mgl32.Quat{W: r[3], V: mgl32.Vec3{r[0], r[1], r[2]}}
+		m = m.Mul4(q.Normalize().Mat4())
+	}
+	if n.Scale != nil {
+		s := n.Scale
+		m = m.Mul4(mgl32.Scale3D(s[0], s[1], s[2]))
+	}
+	return m
+}
+
+// loadNode adds a node and its children to the Scene, under parent if it is not nil
+func (l *loader) loadNode(index int, parent *dusk.SceneNode) error {
+	if index < 0 || index >= len(l.doc.Nodes) {
+		return fmt.Errorf("Invalid node %d", index)
+	}
+	n := &l.doc.Nodes[index]
+
+	name := n.Name
+	if name == "" && n.Mesh != nil && *n.Mesh < len(l.doc.Meshes) {
+		name = l.doc.Meshes[*n.Mesh].Name
+	}
+	if name == "" {
+		name = fmt.Sprintf("node%d", index)
+	}
+
+	sn := dusk.NewSceneNode(name)
+	sn.SetMatrix(nodeMatrix(n))
+	if parent != nil {
+		parent.AddChild(sn)
+	} else {
+		l.scene.AddNode(sn)
+	}
+
+	if n.Mesh != nil {
+		if *n.Mesh >= len(l.doc.Meshes) {
+			return fmt.Errorf("Invalid mesh %d", *n.Mesh)
+		}
+		m := &l.doc.Meshes[*n.Mesh]
+		dusk.Verbosef("Processing Object [%v]", name)
+
+		for i := range m.Primitives {
+			primName := name
+			if len(m.Primitives) > 1 {
+				primName = fmt.Sprintf("%s.%d", name, i)
+			}
+			d, err := l.loadPrimitive(primName, &m.Primitives[i])
+			if err != nil {
+				return err
+			}
+			if d != nil {
+				sn.Meshes = append(sn.Meshes, d)
+			}
+		}
+	}
+
+	if n.Camera != nil {
+		if *n.Camera < 0 || *n.Camera >= len(l.doc.Cameras) {
+			return fmt.Errorf("Invalid camera %d", *n.Camera)
+		}
+		sn.Camera = loadCamera(&l.doc.Cameras[*n.Camera])
+	}
+
+	if ext := n.Extensions.LightsPunctual; ext != nil {
+		lights := l.doc.Extensions.LightsPunctual
+		if lights == nil || ext.Light < 0 || ext.Light >= len(lights.Lights) {
+			return fmt.Errorf("Invalid light %d", ext.Light)
+		}
+		sn.Light = loadLight(&lights.Lights[ext.Light])
+	}
+
+	for _, c := range n.Children {
+		err := l.loadNode(c, sn)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadCamera returns the settings of a camera, which looks down -Z like a dusk.Camera
+func loadCamera(c *camera) *dusk.SceneCamera {
+	sc := &dusk.SceneCamera{}
+	switch {
+	case c.Type == "orthographic" && c.Orthographic != nil:
+		o := c.Orthographic
+		sc.Orthographic = true
+		sc.OrthoSize = o.YMag
+		if o.YMag > 0 {
+			sc.Aspect = o.XMag / o.YMag
+		}
+		sc.Near, sc.Far = o.ZNear, o.ZFar
+	case c.Perspective != nil:
+		p := c.Perspective
+		sc.FOV = p.YFov
+		sc.Aspect = p.AspectRatio
+		// A missing zfar is an infinite projection, which is left to the Camera's default
+		sc.Near, sc.Far = p.ZNear, p.ZFar
+	default:
+		dusk.Warnf("Unsupported camera type [%v]", c.Type)
+	}
+	return sc
+}
+
+// loadLight returns the settings of a light, which shines down -Z like a dusk.Light
+// Intensities are in candela for point and spot lights, and lux for directional lights, and are not converted
+func loadLight(lt *light) *dusk.SceneLight {
+	sl := &dusk.SceneLight{
+		Type:       dusk.PointLight,
+		Color:      mgl32.Vec3{1, 1, 1},
+		Intensity:  1,
+		Range:      lt.Range,
+		OuterAngle: math.Pi / 4,
+	}
+
+	switch lt.Type {
+	case "directional":
+		sl.Type = dusk.DirectionalLight
+	case "point":
+	case "spot":
+		sl.Type = dusk.SpotLight
+	default:
+		dusk.Warnf("Unsupported light type [%v] in [%v], using a point light", lt.Type, lt.Name)
+	}
+
+	if lt.Color != nil {
+		sl.Color = mgl32.Vec3(*lt.Color)
+	}
+	if lt.Intensity != nil {
+		sl.Intensity = *lt.Intensity
+	}
+	if lt.Spot != nil {
+		sl.InnerAngle = lt.Spot.InnerConeAngle
+		if lt.Spot.OuterConeAngle != nil {
+			sl.OuterAngle = *lt.Spot.OuterConeAngle
+		}
+	}
+	return sl
+}
+
+// loadPrimitive returns the MeshData of a primitive in the local space of its node, or nil if it cannot be drawn
+func (l *loader) loadPrimitive(name string, p *primitive) (*dusk.MeshData, error) {
+	if p.Mode != nil && *p.Mode != modeTriangles {
+		dusk.Warnf("Skipping non-triangle primitive in [%v]", name)
+		return nil, nil
+	}
+
+	posIndex, found := p.Attributes["POSITION"]
+	if !found {
+		dusk.Warnf("No 'POSITION' in [%v]", name)
+		return nil, nil
+	}
+
+	verts, vertComps, err := l.readAccessor(posIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	var norms []float32
+	normComps := 0
+	if i, found := p.Attributes["NORMAL"]; found {
+		norms, normComps, err = l.readAccessor(i)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var txcds []float32
+	txcdComps := 0
+	if i, found := p.Attributes["TEXCOORD_0"]; found {
+		txcds, txcdComps, err = l.readAccessor(i)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var tangs []float32
+	tangComps := 0
+	if i, found := p.Attributes["TANGENT"]; found {
+		tangs, tangComps, err = l.readAccessor(i)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	count := len(verts) / vertComps
+	var inds []int
+	if p.Indices != nil {
+		inds, err = l.readIndices(*p.Indices)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		inds = make([]int, count)
+		for i := range inds {
+			inds[i] = i
+		}
+	}
+
+	d := &dusk.MeshData{
+		Name:      name,
+		Vertices:  []mgl32.Vec3{},
+		Normals:   []mgl32.Vec3{},
+		TexCoords: []mgl32.Vec2{},
+	}
+
+	for _, i := range inds {
+		if i >= count {
+			return nil, fmt.Errorf("Index out of range in [%v]", name)
+		}
+
+		v := mgl32.Vec3{verts[i*vertComps], verts[i*vertComps+1], verts[i*vertComps+2]}
+		d.Vertices = append(d.Vertices, v)
+
+		if normComps >= 3 && i*normComps+2 < len(norms) {
+			n := mgl32.Vec3{norms[i*normComps], norms[i*normComps+1], norms[i*normComps+2]}
+			d.Normals = append(d.Normals, n.Normalize())
+		}
+
+		if txcdComps >= 2 && i*txcdComps+1 < len(txcds) {
+			// glTF's origin is the top-left, flip to match the bottom-left used by OBJ and FBX
+			d.TexCoords = append(d.TexCoords, mgl32.Vec2{txcds[i*txcdComps], 1.0 - txcds[i*txcdComps+1]})
+		}
+
+		if tangComps >= 4 && i*tangComps+3 < len(tangs) && len(d.Normals) == len(d.Vertices) {
+			t := mgl32.Vec3{tangs[i*tangComps], tangs[i*tangComps+1], tangs[i*tangComps+2]}
+			n := d.Normals[len(d.Normals)-1]
+			d.Tangents = append(d.Tangents, t)
+			// The bitangent is the cross product of the normal and tangent, multiplied by the tangent's w
+			d.Bitangents = append(d.Bitangents, n.Cross(t).Mul(tangs[i*tangComps+3]))
+		}
+	}
+
+	if p.Material != nil {
+		d.Material, err = l.loadMaterial(*p.Material)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Tangents are generated for normal maps when the file leaves them out, as glTF recommends
+	if d.Material != nil && d.Material.NormalMap != nil && !d.HasTangents() {
+		meshutil.GenerateTangents(d)
+	}
+
+	return d, nil
+}
+
+func (l *loader) loadMaterial(index int) (*dusk.Material, error) {
+	if index < 0 || index >= len(l.doc.Materials) {
+		return nil, fmt.Errorf("Invalid material %d", index)
+	}
+	m := &l.doc.Materials[index]
+
+	data := &dusk.MaterialData{
+		Ambient:   mgl32.Vec4{0, 0, 0, 1},
+		Diffuse:   mgl32.Vec4{1, 1, 1, 1},
+		Specular:  mgl32.Vec4{0, 0, 0, 1},
+		BaseColor: mgl32.Vec4{1, 1, 1, 1},
+		Metallic:  1.0,
+		Roughness: 1.0,
+		Occlusion: 1.0,
+	}
+
+	if pbr := m.PbrMetallicRoughness; pbr != nil {
+		if pbr.BaseColorFactor != nil {
+			data.BaseColor = mgl32.Vec4(*pbr.BaseColorFactor)
+		}
+		if pbr.MetallicFactor != nil {
+			data.Metallic = *pbr.MetallicFactor
+		}
+		if pbr.RoughnessFactor != nil {
+			data.Roughness = *pbr.RoughnessFactor
+		}
+		data.BaseColorMap = l.texturePath(pbr.BaseColorTexture)
+		data.MetallicMap = l.texturePath(pbr.MetallicRoughnessTexture)
+		data.RoughnessMap = data.MetallicMap
+	}
+
+	data.NormalMap = l.texturePath(m.NormalTexture)
+	data.OcclusionMap = l.texturePath(m.OcclusionTexture)
+	if m.OcclusionTexture != nil && m.OcclusionTexture.Strength != nil {
+		data.Occlusion = *m.OcclusionTexture.Strength
+	}
+	data.EmissiveMap = l.texturePath(m.EmissiveTexture)
+	if m.EmissiveFactor != nil {
+		data.Emissive = mgl32.Vec3(*m.EmissiveFactor)
+	}
+
+	data.Transparent = (m.AlphaMode == "BLEND")
+
+	// Fallback values for shaders that don't support PBR
+	data.Diffuse = data.BaseColor
+	data.DiffuseMap = data.BaseColorMap
+
+	return dusk.NewMaterialFromData(data)
+}
@@ -0,0 +1,77 @@
+package meshutil
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/WhoBrokeTheBuild/GoDusk/dusk"
+)
+
+// Bounds returns the Bounds around the vertices of all MeshData, and sets the Bounds of each
+// The sphere is fit to the vertices themselves, so it is tighter than the union of each MeshData's Bounds
+func Bounds(data []*dusk.MeshData) dusk.Bounds {
+	points := []mgl32.Vec3{}
+	for _, d := range data {
+		d.ComputeBounds()
+		points = append(points, d.Vertices...)
+	}
+	return dusk.NewBoundsFromPoints(points)
+}
+
+// Recenter moves the vertices of all MeshData so that their Bounds are centered on the origin
+// Returns the offset that was added to every vertex, which is zero if any MeshData is skinned, since its Skeleton would no longer match
+func Recenter(data []*dusk.MeshData) mgl32.Vec3 {
+	if hasBones(data) {
+		dusk.Warnf("Unable to recenter skinned meshes")
+		return mgl32.Vec3{}
+	}
+
+	offset := Bounds(data).Center.Mul(-1)
+	transform(data, mgl32.Translate3D(offset[0], offset[1], offset[2]))
+	return offset
+}
+
+// NormalizeScale scales the vertices of all MeshData about the origin, so that the largest side of their Bounds is size long
+// Returns the scale that was applied, which is 1 if any MeshData is skinned, since its Skeleton would no longer match
+func NormalizeScale(data []*dusk.MeshData, size float32) float32 {
+	if hasBones(data) {
+		dusk.Warnf("Unable to scale skinned meshes")
+		return 1
+	}
+
+	dims := Bounds(data).Size()
+	largest := dims[0]
+	if dims[1] > largest {
+		largest = dims[1]
+	}
+	if dims[2] > largest {
+		largest = dims[2]
+	}
+	if largest == 0 {
+		return 1
+	}
+
+	scale := size / largest
+	transform(data, mgl32.Scale3D(scale, scale, scale))
+	return scale
+}
+
+func hasBones(data []*dusk.MeshData) bool {
+	for _, d := range data {
+		if d.HasBones() {
+			return true
+		}
+	}
+	return false
+}
+
+// transform applies a translation or uniform scale to the vertices of all MeshData, which leaves the normals unchanged
+func transform(data []*dusk.MeshData, m mgl32.Mat4) {
+	for _, d := range data {
+		verts := make([]mgl32.Vec3, len(d.Vertices))
+		for i, v := range d.Vertices {
+			verts[i] = mgl32.TransformCoordinate(v, m)
+		}
+		d.Vertices = verts
+		d.ComputeBounds()
+	}
+}
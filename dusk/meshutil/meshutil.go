@@ -0,0 +1,99 @@
+// Package meshutil processes the triangles of MeshData, to fill in data that files leave out
+//
+// MeshData stores three vertices per triangle, so every function works on triangles in that order.
+// A Processor can be added with dusk.AddMeshProcessor to run on every file loaded by Model.LoadFromFile:
+//
+//	dusk.AddMeshProcessor(meshutil.Processor(meshutil.Options{
+//		Normals:     true,
+//		NormalAngle: mgl32.DegToRad(60),
+//		Tangents:    true,
+//	}))
+package meshutil
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/WhoBrokeTheBuild/GoDusk/dusk"
+	"github.com/WhoBrokeTheBuild/GoDusk/m32"
+)
+
+// Options selects the steps run by a Processor, which are run in the order of the fields
+type Options struct {
+	// WeldEpsilon is the distance within which vertices are welded, or 0 to leave them
+	WeldEpsilon float32
+
+	// FixWinding turns triangles to face the same way as their normals, or outwards if there are none
+	FixWinding bool
+
+	// Normals generates normals for MeshData without them, smoothed across edges sharper than NormalAngle
+	Normals     bool
+	NormalAngle float32
+	// RecomputeNormals replaces normals even when the file has them
+	RecomputeNormals bool
+
+	// Tangents generates tangents for MeshData with normals and texcoords but no tangents
+	Tangents bool
+
+	// Recenter moves the vertices of all MeshData so that their Bounds are centered on the origin
+	Recenter bool
+	// Size scales the vertices of all MeshData so that the largest side of their Bounds is this long, or 0 to leave them
+	Size float32
+}
+
+// Processor returns a dusk.MeshProcessor that runs the steps selected by opts
+func Processor(opts Options) dusk.MeshProcessor {
+	return func(data []*dusk.MeshData) error {
+		for _, d := range data {
+			if opts.WeldEpsilon > 0 {
+				Weld(d, opts.WeldEpsilon)
+			}
+			if opts.FixWinding {
+				FixWinding(d)
+			}
+			if opts.RecomputeNormals || (opts.Normals && len(d.Normals) != len(d.Vertices)) {
+				GenerateNormals(d, opts.NormalAngle)
+			}
+			if opts.Tangents && !d.HasTangents() {
+				GenerateTangents(d)
+			}
+		}
+
+		if opts.Recenter {
+			Recenter(data)
+		}
+		if opts.Size > 0 {
+			NormalizeScale(data, opts.Size)
+		}
+		return nil
+	}
+}
+
+// faceNormal returns the normal of the triangle starting at i, with a length of twice its area
+func faceNormal(d *dusk.MeshData, i int) mgl32.Vec3 {
+	a, b, c := d.Vertices[i], d.Vertices[i+1], d.Vertices[i+2]
+	return b.Sub(a).Cross(c.Sub(a))
+}
+
+// cornerAngle returns the angle in radians at the corner j of the triangle starting at i
+func cornerAngle(d *dusk.MeshData, i, j int) float32 {
+	p := d.Vertices[i+j]
+	e1 := d.Vertices[i+(j+1)%3].Sub(p)
+	e2 := d.Vertices[i+(j+2)%3].Sub(p)
+	if e1.Len() == 0 || e2.Len() == 0 {
+		return 0
+	}
+	return m32.Acos(mgl32.Clamp(e1.Normalize().Dot(e2.Normalize()), -1, 1))
+}
+
+// copyVertexData replaces every per-vertex slice with a copy, before changing them in place
+// MeshData loaded from one file may share its slices, so they can't be changed without copying them first
+func copyVertexData(d *dusk.MeshData) {
+	d.Vertices = append([]mgl32.Vec3(nil), d.Vertices...)
+	d.Normals = append([]mgl32.Vec3(nil), d.Normals...)
+	d.TexCoords = append([]mgl32.Vec2(nil), d.TexCoords...)
+	d.Colors = append([]mgl32.Vec4(nil), d.Colors...)
+	d.Tangents = append([]mgl32.Vec3(nil), d.Tangents...)
+	d.Bitangents = append([]mgl32.Vec3(nil), d.Bitangents...)
+	d.BoneIndices = append([]mgl32.Vec4(nil), d.BoneIndices...)
+	d.BoneWeights = append([]mgl32.Vec4(nil), d.BoneWeights...)
+}
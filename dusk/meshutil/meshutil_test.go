@@ -0,0 +1,61 @@
+package meshutil
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/WhoBrokeTheBuild/GoDusk/dusk"
+)
+
+func near(a, b mgl32.Vec3) bool {
+	return a.ApproxEqualThreshold(b, 1e-4)
+}
+
+// testQuad returns a rectangle from the origin to (w, h) facing +Z, as two triangles with texcoords from 0 to 1
+func testQuad(w, h float32) *dusk.MeshData {
+	corners := []mgl32.Vec2{{0, 0}, {1, 0}, {1, 1}, {0, 0}, {1, 1}, {0, 1}}
+	d := &dusk.MeshData{}
+	for _, c := range corners {
+		d.Vertices = append(d.Vertices, mgl32.Vec3{c[0] * w, c[1] * h, 0})
+		d.TexCoords = append(d.TexCoords, c)
+	}
+	return d
+}
+
+func TestProcessor(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		size mgl32.Vec3
+	}{
+		{"nothing", Options{}, mgl32.Vec3{4, 2, 0}},
+		{"normals and tangents", Options{Normals: true, Tangents: true}, mgl32.Vec3{4, 2, 0}},
+		{"recenter and size", Options{Normals: true, Tangents: true, Recenter: true, Size: 2}, mgl32.Vec3{2, 1, 0}},
+	}
+
+	for _, test := range tests {
+		d := testQuad(4, 2)
+		if err := Processor(test.opts)([]*dusk.MeshData{d}); err != nil {
+			t.Errorf("%v: %v", test.name, err)
+			continue
+		}
+
+		if d.HasTangents() != test.opts.Tangents {
+			t.Errorf("%v: HasTangents is %v", test.name, d.HasTangents())
+		}
+		for i, n := range d.Normals {
+			if !near(n, mgl32.Vec3{0, 0, 1}) {
+				t.Errorf("%v: Normal %d is %v", test.name, i, n)
+			}
+		}
+
+		b := Bounds([]*dusk.MeshData{d})
+		if !near(b.Size(), test.size) {
+			t.Errorf("%v: Size is %v, expected %v", test.name, b.Size(), test.size)
+		}
+		if test.opts.Recenter && !near(b.Center, mgl32.Vec3{}) {
+			t.Errorf("%v: Center is %v", test.name, b.Center)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package meshutil
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/WhoBrokeTheBuild/GoDusk/dusk"
+	"github.com/WhoBrokeTheBuild/GoDusk/m32"
+)
+
+// GenerateNormals sets the Normals from the triangles, replacing any already set
+// Triangles that share a position are smoothed together if the angle between them is at most angle, in radians,
+// so an angle of 0 gives flat normals. Each triangle is weighted by its angle at the shared corner
+func GenerateNormals(d *dusk.MeshData, angle float32) {
+	count := len(d.Vertices) / 3 * 3
+	faces := make([]mgl32.Vec3, count/3)
+	for t := range faces {
+		if n := faceNormal(d, t*3); n.Len() > 0 {
+			faces[t] = n.Normalize()
+		}
+	}
+
+	normals := make([]mgl32.Vec3, len(d.Vertices))
+	if angle <= 0 {
+		for i := 0; i < count; i++ {
+			normals[i] = faces[i/3]
+		}
+		d.Normals = normals
+		return
+	}
+
+	// The corners at each position, as indices into Vertices
+	shared := map[mgl32.Vec3][]int{}
+	for i := 0; i < count; i++ {
+		shared[d.Vertices[i]] = append(shared[d.Vertices[i]], i)
+	}
+
+	threshold := m32.Cos(angle)
+	for i := 0; i < count; i++ {
+		face := faces[i/3]
+		sum := mgl32.Vec3{}
+		for _, j := range shared[d.Vertices[i]] {
+			other := faces[j/3]
+			if j/3 != i/3 && face.Dot(other) < threshold {
+				continue
+			}
+			sum = sum.Add(other.Mul(cornerAngle(d, j/3*3, j%3)))
+		}
+		if sum.Len() == 0 {
+			sum = face
+		}
+		if sum.Len() > 0 {
+			sum = sum.Normalize()
+		}
+		normals[i] = sum
+	}
+	d.Normals = normals
+}
@@ -0,0 +1,53 @@
+package meshutil
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/WhoBrokeTheBuild/GoDusk/dusk"
+	"github.com/WhoBrokeTheBuild/GoDusk/m32"
+)
+
+func TestGenerateNormals(t *testing.T) {
+	tests := []struct {
+		name        string
+		fold, angle float32
+		smooth      bool
+	}{
+		{"flat", 30, 0, false},
+		{"fold below angle", 30, 45, true},
+		{"fold above angle", 60, 45, false},
+		{"fold at angle", 45, 45, true},
+	}
+
+	for _, test := range tests {
+		// Two triangles sharing the edge from the origin to +X, with normals test.fold degrees apart
+		fold := mgl32.DegToRad(test.fold)
+		d := &dusk.MeshData{
+			Vertices: []mgl32.Vec3{
+				{0, 0, 0}, {1, 0, 0}, {0.5, 1, 0},
+				{1, 0, 0}, {0, 0, 0}, {0.5, -m32.Cos(fold), m32.Sin(fold)},
+			},
+		}
+		GenerateNormals(d, mgl32.DegToRad(test.angle)+1e-4)
+
+		if len(d.Normals) != len(d.Vertices) {
+			t.Fatalf("%v: %d normals, expected %d", test.name, len(d.Normals), len(d.Vertices))
+		}
+
+		faceA := mgl32.Vec3{0, 0, 1}
+		faceB := mgl32.Vec3{0, m32.Sin(fold), m32.Cos(fold)}
+		half := mgl32.Vec3{0, m32.Sin(fold / 2), m32.Cos(fold / 2)}
+
+		want := []mgl32.Vec3{faceA, faceA, faceA, faceB, faceB, faceB}
+		if test.smooth {
+			want = []mgl32.Vec3{half, half, faceA, half, half, faceB}
+		}
+		for i := range want {
+			if !near(d.Normals[i], want[i]) {
+				t.Errorf("%v: Normal %d is %v, expected %v", test.name, i, d.Normals[i], want[i])
+			}
+		}
+	}
+}
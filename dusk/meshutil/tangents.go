@@ -0,0 +1,96 @@
+package meshutil
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/WhoBrokeTheBuild/GoDusk/dusk"
+)
+
+// GenerateTangents sets the Tangents and Bitangents from the Normals and TexCoords, in the same way as MikkTSpace
+//
+// Each triangle's tangent frame is found from its texcoords, then averaged over the corners that share a position,
+// normal and texcoord, weighted by the angle at each corner. Tangents are made perpendicular to the normal, and
+// Bitangents are the cross product of the normal and tangent, flipped where the texcoords are mirrored.
+// Nothing is done if the MeshData has no Normals or TexCoords
+func GenerateTangents(d *dusk.MeshData) {
+	if len(d.Normals) != len(d.Vertices) || len(d.TexCoords) != len(d.Vertices) {
+		return
+	}
+
+	type key struct {
+		p, n mgl32.Vec3
+		t    mgl32.Vec2
+	}
+	type frame struct {
+		t, b mgl32.Vec3
+	}
+
+	count := len(d.Vertices) / 3 * 3
+	corners := make([]frame, len(d.Vertices))
+	shared := map[key]frame{}
+
+	for i := 0; i < count; i += 3 {
+		e1 := d.Vertices[i+1].Sub(d.Vertices[i])
+		e2 := d.Vertices[i+2].Sub(d.Vertices[i])
+		uv1 := d.TexCoords[i+1].Sub(d.TexCoords[i])
+		uv2 := d.TexCoords[i+2].Sub(d.TexCoords[i])
+
+		det := uv1[0]*uv2[1] - uv2[0]*uv1[1]
+		if det == 0 {
+			continue
+		}
+		r := 1 / det
+		t := e1.Mul(uv2[1]).Sub(e2.Mul(uv1[1])).Mul(r)
+		b := e2.Mul(uv1[0]).Sub(e1.Mul(uv2[0])).Mul(r)
+		if t.Len() > 0 {
+			t = t.Normalize()
+		}
+		if b.Len() > 0 {
+			b = b.Normalize()
+		}
+
+		for j := 0; j < 3; j++ {
+			w := cornerAngle(d, i, j)
+			k := key{d.Vertices[i+j], d.Normals[i+j], d.TexCoords[i+j]}
+			f := shared[k]
+			f.t = f.t.Add(t.Mul(w))
+			f.b = f.b.Add(b.Mul(w))
+			shared[k] = f
+		}
+	}
+
+	for i := 0; i < count; i++ {
+		corners[i] = shared[key{d.Vertices[i], d.Normals[i], d.TexCoords[i]}]
+	}
+
+	d.Tangents = make([]mgl32.Vec3, len(d.Vertices))
+	d.Bitangents = make([]mgl32.Vec3, len(d.Vertices))
+	for i := 0; i < count; i++ {
+		n := d.Normals[i]
+		t := corners[i].t.Sub(n.Mul(n.Dot(corners[i].t)))
+		if t.Len() == 0 {
+			t = anyPerpendicular(n)
+		}
+		t = t.Normalize()
+
+		b := n.Cross(t)
+		if b.Dot(corners[i].b) < 0 {
+			b = b.Mul(-1)
+		}
+		d.Tangents[i] = t
+		d.Bitangents[i] = b
+	}
+}
+
+// anyPerpendicular returns a direction perpendicular to n, for corners without a tangent
+func anyPerpendicular(n mgl32.Vec3) mgl32.Vec3 {
+	axis := mgl32.Vec3{1, 0, 0}
+	if n[0]*n[0] > 0.8 {
+		axis = mgl32.Vec3{0, 1, 0}
+	}
+	p := axis.Sub(n.Mul(n.Dot(axis)))
+	if p.Len() == 0 {
+		return axis
+	}
+	return p
+}
@@ -0,0 +1,51 @@
+package meshutil
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestGenerateTangents(t *testing.T) {
+	tests := []struct {
+		name       string
+		texcoord   func(uv mgl32.Vec2) mgl32.Vec2
+		tangent    mgl32.Vec3
+		handedness float32
+	}{
+		{"standard", func(uv mgl32.Vec2) mgl32.Vec2 { return uv }, mgl32.Vec3{1, 0, 0}, 1},
+		{"mirrored", func(uv mgl32.Vec2) mgl32.Vec2 { return mgl32.Vec2{1 - uv[0], uv[1]} }, mgl32.Vec3{-1, 0, 0}, -1},
+		{"rotated", func(uv mgl32.Vec2) mgl32.Vec2 { return mgl32.Vec2{uv[1], 1 - uv[0]} }, mgl32.Vec3{0, 1, 0}, 1},
+		{"swapped", func(uv mgl32.Vec2) mgl32.Vec2 { return mgl32.Vec2{uv[1], uv[0]} }, mgl32.Vec3{0, 1, 0}, -1},
+	}
+
+	for _, test := range tests {
+		d := testQuad(2, 2)
+		for i := range d.TexCoords {
+			d.TexCoords[i] = test.texcoord(d.TexCoords[i])
+			d.Normals = append(d.Normals, mgl32.Vec3{0, 0, 1})
+		}
+		GenerateTangents(d)
+
+		if !d.HasTangents() || len(d.Bitangents) != len(d.Vertices) {
+			t.Fatalf("%v: %d tangents and %d bitangents, expected %d", test.name, len(d.Tangents), len(d.Bitangents), len(d.Vertices))
+		}
+		for i := range d.Vertices {
+			n, tan, b := d.Normals[i], d.Tangents[i], d.Bitangents[i]
+			if !near(tan, test.tangent) {
+				t.Errorf("%v: Tangent %d is %v, expected %v", test.name, i, tan, test.tangent)
+			}
+			if handedness := n.Cross(tan).Dot(b); !near(b, n.Cross(tan).Mul(test.handedness)) {
+				t.Errorf("%v: Bitangent %d is %v, with handedness %v, expected %v", test.name, i, b, handedness, test.handedness)
+			}
+		}
+	}
+
+	// Without texcoords there is nothing to find the tangents from
+	d := testQuad(2, 2)
+	d.TexCoords = nil
+	GenerateTangents(d)
+	if d.Tangents != nil {
+		t.Errorf("Generated tangents without texcoords")
+	}
+}
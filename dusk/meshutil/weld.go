@@ -0,0 +1,133 @@
+package meshutil
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/WhoBrokeTheBuild/GoDusk/dusk"
+	"github.com/WhoBrokeTheBuild/GoDusk/m32"
+)
+
+// Weld moves vertices within epsilon of each other to the same position, closing cracks between triangles
+// so that they are smoothed together by GenerateNormals. Triangles that collapse to a line or point are removed
+// Returns the number of unique positions
+func Weld(d *dusk.MeshData, epsilon float32) int {
+	type cell [3]int32
+
+	// Positions are bucketed by a grid of epsilon, so only the neighboring cells need to be searched
+	grid := map[cell][]mgl32.Vec3{}
+	unique := 0
+
+	cellOf := func(p mgl32.Vec3) cell {
+		return cell{
+			int32(m32.Floor(p[0] / epsilon)),
+			int32(m32.Floor(p[1] / epsilon)),
+			int32(m32.Floor(p[2] / epsilon)),
+		}
+	}
+
+	copyVertexData(d)
+	for i, p := range d.Vertices {
+		c := cellOf(p)
+		found := false
+
+	search:
+		for x := c[0] - 1; x <= c[0]+1; x++ {
+			for y := c[1] - 1; y <= c[1]+1; y++ {
+				for z := c[2] - 1; z <= c[2]+1; z++ {
+					for _, q := range grid[cell{x, y, z}] {
+						if p.Sub(q).Len() <= epsilon {
+							d.Vertices[i] = q
+							found = true
+							break search
+						}
+					}
+				}
+			}
+		}
+
+		if !found {
+			grid[c] = append(grid[c], p)
+			unique++
+		}
+	}
+
+	count := len(d.Vertices) / 3
+	keep := make([]bool, count)
+	for t := range keep {
+		a, b, c := d.Vertices[t*3], d.Vertices[t*3+1], d.Vertices[t*3+2]
+		keep[t] = a != b && b != c && c != a
+	}
+	removeTriangles(d, keep)
+	d.Bounds = dusk.Bounds{}
+
+	return unique
+}
+
+// removeTriangles removes the triangles that are not kept from every per-vertex and per-triangle slice of the MeshData
+func removeTriangles(d *dusk.MeshData, keep []bool) {
+	all := len(d.Vertices) == len(keep)*3
+	for _, k := range keep {
+		all = all && k
+	}
+	if all {
+		return
+	}
+
+	vec2 := func(s []mgl32.Vec2) []mgl32.Vec2 {
+		if len(s) != len(d.Vertices) {
+			return s
+		}
+		out := make([]mgl32.Vec2, 0, len(s))
+		for t, k := range keep {
+			if k {
+				out = append(out, s[t*3:t*3+3]...)
+			}
+		}
+		return out
+	}
+	vec3 := func(s []mgl32.Vec3) []mgl32.Vec3 {
+		if len(s) != len(d.Vertices) {
+			return s
+		}
+		out := make([]mgl32.Vec3, 0, len(s))
+		for t, k := range keep {
+			if k {
+				out = append(out, s[t*3:t*3+3]...)
+			}
+		}
+		return out
+	}
+	vec4 := func(s []mgl32.Vec4) []mgl32.Vec4 {
+		if len(s) != len(d.Vertices) {
+			return s
+		}
+		out := make([]mgl32.Vec4, 0, len(s))
+		for t, k := range keep {
+			if k {
+				out = append(out, s[t*3:t*3+3]...)
+			}
+		}
+		return out
+	}
+
+	d.Normals = vec3(d.Normals)
+	d.TexCoords = vec2(d.TexCoords)
+	d.Colors = vec4(d.Colors)
+	d.Tangents = vec3(d.Tangents)
+	d.Bitangents = vec3(d.Bitangents)
+	d.BoneIndices = vec4(d.BoneIndices)
+	d.BoneWeights = vec4(d.BoneWeights)
+
+	if len(d.Polygons) == len(keep) {
+		polygons := make([]int, 0, len(d.Polygons))
+		for t, k := range keep {
+			if k {
+				polygons = append(polygons, d.Polygons[t])
+			}
+		}
+		d.Polygons = polygons
+	}
+
+	// Vertices are filtered last, since the other slices are compared to its length
+	d.Vertices = vec3(d.Vertices)
+}
@@ -0,0 +1,68 @@
+package meshutil
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/WhoBrokeTheBuild/GoDusk/dusk"
+)
+
+func TestWeld(t *testing.T) {
+	tests := []struct {
+		name      string
+		vertices  []mgl32.Vec3
+		epsilon   float32
+		unique    int
+		triangles int
+	}{
+		{
+			"crack within epsilon",
+			[]mgl32.Vec3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {1.0005, 0, 0}, {1, 1, 0}, {0, 1.0005, 0}},
+			0.001, 4, 2,
+		},
+		{
+			"crack outside epsilon",
+			[]mgl32.Vec3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {1.0005, 0, 0}, {1, 1, 0}, {0, 1.0005, 0}},
+			0.0001, 6, 2,
+		},
+		{
+			"collapsed triangle",
+			[]mgl32.Vec3{{0, 0, 0}, {0.0005, 0, 0}, {0, 1, 0}, {0, 0, 0}, {1, 0, 0}, {0, 1, 0}},
+			0.001, 3, 1,
+		},
+	}
+
+	for _, test := range tests {
+		original := append([]mgl32.Vec3(nil), test.vertices...)
+		d := &dusk.MeshData{
+			Vertices: test.vertices,
+			Normals:  make([]mgl32.Vec3, len(test.vertices)),
+			Polygons: make([]int, len(test.vertices)/3),
+		}
+
+		if unique := Weld(d, test.epsilon); unique != test.unique {
+			t.Errorf("%v: %d unique positions, expected %d", test.name, unique, test.unique)
+		}
+		if len(d.Vertices) != test.triangles*3 || len(d.Normals) != test.triangles*3 || len(d.Polygons) != test.triangles {
+			t.Errorf("%v: %d vertices, %d normals and %d polygons, expected %d triangles",
+				test.name, len(d.Vertices), len(d.Normals), len(d.Polygons), test.triangles)
+		}
+
+		// Every position is moved to one that was already seen within epsilon
+		positions := map[mgl32.Vec3]bool{}
+		for _, v := range d.Vertices {
+			positions[v] = true
+		}
+		if len(positions) > test.unique {
+			t.Errorf("%v: %d positions are used, expected at most %d", test.name, len(positions), test.unique)
+		}
+
+		for i := range original {
+			if test.vertices[i] != original[i] {
+				t.Errorf("%v: The original Vertices were changed", test.name)
+				break
+			}
+		}
+	}
+}
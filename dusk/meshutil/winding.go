@@ -0,0 +1,152 @@
+package meshutil
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/WhoBrokeTheBuild/GoDusk/dusk"
+)
+
+// FixWinding turns triangles so that they wind counter-clockwise when seen from the front, returning how many were turned
+//
+// With Normals, the front of each triangle is the side its normals point to. Without them, triangles are turned to
+// match their neighbors across shared edges, and each connected piece is then turned to face outwards from its center
+func FixWinding(d *dusk.MeshData) int {
+	count := len(d.Vertices) / 3
+	flip := make([]bool, count)
+
+	if len(d.Normals) == len(d.Vertices) {
+		for t := range flip {
+			n := d.Normals[t*3].Add(d.Normals[t*3+1]).Add(d.Normals[t*3+2])
+			flip[t] = faceNormal(d, t*3).Dot(n) < 0
+		}
+	} else {
+		orientByNeighbors(d, flip)
+	}
+
+	flipped := 0
+	for t, f := range flip {
+		if !f {
+			continue
+		}
+		if flipped == 0 {
+			copyVertexData(d)
+		}
+		flipTriangle(d, t*3)
+		flipped++
+	}
+	return flipped
+}
+
+// orientByNeighbors marks the triangles to flip so that every edge is used in opposite directions by its two triangles,
+// and so that each connected piece encloses a positive volume
+func orientByNeighbors(d *dusk.MeshData, flip []bool) {
+	type edge struct {
+		a, b mgl32.Vec3
+	}
+	undirected := func(a, b mgl32.Vec3) edge {
+		if a[0] < b[0] || (a[0] == b[0] && (a[1] < b[1] || (a[1] == b[1] && a[2] < b[2]))) {
+			return edge{a, b}
+		}
+		return edge{b, a}
+	}
+
+	edges := map[edge][]int{}
+	for t := range flip {
+		for j := 0; j < 3; j++ {
+			e := undirected(d.Vertices[t*3+j], d.Vertices[t*3+(j+1)%3])
+			edges[e] = append(edges[e], t)
+		}
+	}
+
+	// hasEdge returns whether triangle t, after flipping, goes from a to b
+	hasEdge := func(t int, a, b mgl32.Vec3) bool {
+		for j := 0; j < 3; j++ {
+			p, q := d.Vertices[t*3+j], d.Vertices[t*3+(j+1)%3]
+			if flip[t] {
+				p, q = q, p
+			}
+			if p == a && q == b {
+				return true
+			}
+		}
+		return false
+	}
+
+	visited := make([]bool, len(flip))
+	for start := range flip {
+		if visited[start] {
+			continue
+		}
+
+		piece := []int{start}
+		visited[start] = true
+		for i := 0; i < len(piece); i++ {
+			t := piece[i]
+			for j := 0; j < 3; j++ {
+				a, b := d.Vertices[t*3+j], d.Vertices[t*3+(j+1)%3]
+				if flip[t] {
+					a, b = b, a
+				}
+				for _, o := range edges[undirected(a, b)] {
+					if visited[o] {
+						continue
+					}
+					visited[o] = true
+					// A neighbor with the same winding goes along the shared edge in the other direction
+					flip[o] = hasEdge(o, a, b)
+					piece = append(piece, o)
+				}
+			}
+		}
+
+		center := mgl32.Vec3{}
+		for _, t := range piece {
+			center = center.Add(d.Vertices[t*3]).Add(d.Vertices[t*3+1]).Add(d.Vertices[t*3+2])
+		}
+		center = center.Mul(1 / float32(len(piece)*3))
+
+		volume := float32(0)
+		for _, t := range piece {
+			a := d.Vertices[t*3].Sub(center)
+			b := d.Vertices[t*3+1].Sub(center)
+			c := d.Vertices[t*3+2].Sub(center)
+			v := a.Dot(b.Cross(c))
+			if flip[t] {
+				v = -v
+			}
+			volume += v
+		}
+
+		if volume < 0 {
+			for _, t := range piece {
+				flip[t] = !flip[t]
+			}
+		}
+	}
+}
+
+// flipTriangle swaps the last two corners of the triangle starting at i in every per-vertex slice
+func flipTriangle(d *dusk.MeshData, i int) {
+	d.Vertices[i+1], d.Vertices[i+2] = d.Vertices[i+2], d.Vertices[i+1]
+	if len(d.Normals) == len(d.Vertices) {
+		d.Normals[i+1], d.Normals[i+2] = d.Normals[i+2], d.Normals[i+1]
+	}
+	if len(d.TexCoords) == len(d.Vertices) {
+		d.TexCoords[i+1], d.TexCoords[i+2] = d.TexCoords[i+2], d.TexCoords[i+1]
+	}
+	if len(d.Colors) == len(d.Vertices) {
+		d.Colors[i+1], d.Colors[i+2] = d.Colors[i+2], d.Colors[i+1]
+	}
+	if len(d.Tangents) == len(d.Vertices) {
+		d.Tangents[i+1], d.Tangents[i+2] = d.Tangents[i+2], d.Tangents[i+1]
+	}
+	if len(d.Bitangents) == len(d.Vertices) {
+		d.Bitangents[i+1], d.Bitangents[i+2] = d.Bitangents[i+2], d.Bitangents[i+1]
+	}
+	if len(d.BoneIndices) == len(d.Vertices) {
+		d.BoneIndices[i+1], d.BoneIndices[i+2] = d.BoneIndices[i+2], d.BoneIndices[i+1]
+	}
+	if len(d.BoneWeights) == len(d.Vertices) {
+		d.BoneWeights[i+1], d.BoneWeights[i+2] = d.BoneWeights[i+2], d.BoneWeights[i+1]
+	}
+}
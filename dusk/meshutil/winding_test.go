@@ -0,0 +1,90 @@
+package meshutil
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/WhoBrokeTheBuild/GoDusk/dusk"
+)
+
+// testTetrahedron returns the faces of a tetrahedron, wound counter-clockwise from outside except for the faces to flip
+func testTetrahedron(flip ...int) []mgl32.Vec3 {
+	a, b, c, d := mgl32.Vec3{0, 0, 0}, mgl32.Vec3{1, 0, 0}, mgl32.Vec3{0, 1, 0}, mgl32.Vec3{0, 0, 1}
+	faces := [][3]mgl32.Vec3{{a, c, b}, {a, b, d}, {a, d, c}, {b, c, d}}
+	for _, f := range flip {
+		faces[f][1], faces[f][2] = faces[f][2], faces[f][1]
+	}
+
+	verts := []mgl32.Vec3{}
+	for _, f := range faces {
+		verts = append(verts, f[:]...)
+	}
+	return verts
+}
+
+func TestFixWinding(t *testing.T) {
+	up := []mgl32.Vec3{{0, 0, 1}, {0, 0, 1}, {0, 0, 1}}
+	down := []mgl32.Vec3{{0, 0, -1}, {0, 0, -1}, {0, 0, -1}}
+	triangle := []mgl32.Vec3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}
+
+	tests := []struct {
+		name     string
+		vertices []mgl32.Vec3
+		normals  []mgl32.Vec3
+		flipped  int
+	}{
+		{"normals agree", triangle, up, 0},
+		{"normals disagree", triangle, down, 1},
+		{"closed", testTetrahedron(), nil, 0},
+		{"closed with one face flipped", testTetrahedron(2), nil, 1},
+		{"closed with three faces flipped", testTetrahedron(0, 1, 3), nil, 3},
+		{"closed inside out", testTetrahedron(0, 1, 2, 3), nil, 4},
+	}
+
+	for _, test := range tests {
+		original := append([]mgl32.Vec3(nil), test.vertices...)
+
+		// Each color is the position of its vertex, to check that the other slices are flipped with it
+		d := &dusk.MeshData{
+			Vertices: test.vertices,
+			Normals:  test.normals,
+		}
+		for _, v := range d.Vertices {
+			d.Colors = append(d.Colors, v.Vec4(1))
+		}
+
+		if flipped := FixWinding(d); flipped != test.flipped {
+			t.Errorf("%v: Flipped %d triangles, expected %d", test.name, flipped, test.flipped)
+		}
+
+		center := mgl32.Vec3{}
+		for _, v := range d.Vertices {
+			center = center.Add(v)
+		}
+		center = center.Mul(1 / float32(len(d.Vertices)))
+
+		for i := 0; i < len(d.Vertices); i += 3 {
+			front := d.Vertices[i].Add(d.Vertices[i+1]).Add(d.Vertices[i+2]).Mul(1.0 / 3).Sub(center)
+			if d.Normals != nil {
+				front = d.Normals[i]
+			}
+			if faceNormal(d, i).Dot(front) <= 0 {
+				t.Errorf("%v: Triangle %d faces the wrong way", test.name, i/3)
+			}
+		}
+		for i, v := range d.Vertices {
+			if d.Colors[i] != v.Vec4(1) {
+				t.Errorf("%v: Color %d is %v, expected %v", test.name, i, d.Colors[i], v.Vec4(1))
+				break
+			}
+		}
+
+		for i := range original {
+			if test.vertices[i] != original[i] {
+				t.Errorf("%v: The original Vertices were changed", test.name)
+				break
+			}
+		}
+	}
+}
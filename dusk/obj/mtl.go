@@ -0,0 +1,266 @@
+package obj
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/WhoBrokeTheBuild/GoDusk/dusk"
+)
+
+// mapOptionArgs is the number of values taken by each option of a texture map statement
+// Options with a range of values take as many numbers as follow them, up to the maximum
+var mapOptionArgs = map[string][2]int{
+	"-blendu":  {1, 1},
+	"-blendv":  {1, 1},
+	"-bm":      {1, 1},
+	"-boost":   {1, 1},
+	"-cc":      {1, 1},
+	"-clamp":   {1, 1},
+	"-imfchan": {1, 1},
+	"-mm":      {2, 2},
+	"-o":       {1, 3},
+	"-s":       {1, 3},
+	"-t":       {1, 3},
+	"-texres":  {1, 1},
+	"-type":    {1, 1},
+}
+
+// mtlParser holds the state of an MTL file while it is read
+type mtlParser struct {
+	dir  string
+	line int
+
+	materials map[string]*dusk.MaterialData
+	current   *dusk.MaterialData
+
+	// illum is the illumination model of each material, applied once all of its colors are read
+	illum map[*dusk.MaterialData]int
+}
+
+// readMaterial parses an MTL file and returns its materials by name
+func readMaterial(filename string) (map[string]*dusk.MaterialData, error) {
+	filename = filepath.Clean(filename)
+
+	file, err := dusk.Load(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &mtlParser{
+		dir:       filepath.Dir(filename),
+		materials: map[string]*dusk.MaterialData{},
+		illum:     map[*dusk.MaterialData]int{},
+	}
+
+	lines := strings.Split(string(file), "\n")
+	for i := 0; i < len(lines); i++ {
+		p.line = i + 1
+		line := strings.TrimRight(lines[i], "\r")
+		for strings.HasSuffix(line, "\\") && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + " " + strings.TrimRight(lines[i], "\r")
+		}
+
+		if c := strings.IndexByte(line, '#'); c >= 0 {
+			line = line[:c]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		err = p.parseStatement(fields[0], fields[1:])
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", filepath.Base(filename), err)
+		}
+	}
+
+	for m, illum := range p.illum {
+		switch illum {
+		case 0, 1:
+			// Illumination models without highlights
+			m.Specular = mgl32.Vec4{0, 0, 0, m.Specular[3]}
+		case 4, 6, 7, 9:
+			// Illumination models for glass
+			m.Transparent = true
+		}
+	}
+
+	return p.materials, nil
+}
+
+func (p *mtlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("Line %d: %v", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *mtlParser) parseStatement(keyword string, args []string) error {
+	if keyword == "newmtl" {
+		name := strings.Join(args, " ")
+		p.current = &dusk.MaterialData{
+			Ambient:  mgl32.Vec4{0, 0, 0, 1},
+			Diffuse:  mgl32.Vec4{0, 0, 0, 1},
+			Specular: mgl32.Vec4{0, 0, 0, 1},
+		}
+		p.materials[name] = p.current
+		return nil
+	}
+
+	m := p.current
+	if m == nil {
+		return p.errorf("'%v' before 'newmtl'", keyword)
+	}
+
+	switch keyword {
+	case "Ka":
+		return p.parseColor(args, &m.Ambient)
+	case "Kd":
+		return p.parseColor(args, &m.Diffuse)
+	case "Ks":
+		return p.parseColor(args, &m.Specular)
+	case "Ke":
+		tmp := mgl32.Vec4{}
+		err := p.parseColor(args, &tmp)
+		m.Emissive = tmp.Vec3()
+		return err
+
+	case "Ns":
+		return p.parseFloat(args, &m.Shininess)
+	case "Pr":
+		return p.parseFloat(args, &m.Roughness)
+	case "Pm":
+		return p.parseFloat(args, &m.Metallic)
+
+	case "d", "Tr":
+		// d is the opacity, and Tr is the transparency, both apply to the alpha of every color
+		var value float32
+		if len(args) > 0 && args[0] == "-halo" {
+			args = args[1:]
+		}
+		err := p.parseFloat(args, &value)
+		if err != nil {
+			return err
+		}
+		if keyword == "Tr" {
+			value = 1 - value
+		}
+		m.Ambient[3] = value
+		m.Diffuse[3] = value
+		m.Specular[3] = value
+
+	case "illum":
+		var value float32
+		err := p.parseFloat(args, &value)
+		if err != nil {
+			return err
+		}
+		p.illum[m] = int(value)
+
+	case "map_Ka":
+		return p.parseMap(args, &m.AmbientMap)
+	case "map_Kd":
+		return p.parseMap(args, &m.DiffuseMap)
+	case "map_Ks":
+		return p.parseMap(args, &m.SpecularMap)
+	case "map_Ke":
+		return p.parseMap(args, &m.EmissiveMap)
+	case "map_Ns":
+		return p.parseMap(args, &m.ShininessMap)
+	case "map_Pr":
+		return p.parseMap(args, &m.RoughnessMap)
+	case "map_Pm":
+		return p.parseMap(args, &m.MetallicMap)
+	case "map_d":
+		return p.parseMap(args, &m.AlphaMap)
+	case "norm":
+		return p.parseMap(args, &m.NormalMap)
+	case "bump", "map_bump", "map_Bump":
+		// Exporters write normal maps as bump maps, so they are only used if there is no 'norm'
+		if m.NormalMap != "" {
+			return nil
+		}
+		return p.parseMap(args, &m.NormalMap)
+	case "disp":
+		return p.parseMap(args, &m.DisplacementMap)
+	case "refl":
+		return p.parseMap(args, &m.ReflectionMap)
+	}
+
+	return nil
+}
+
+func (p *mtlParser) parseFloat(args []string, value *float32) error {
+	if len(args) < 1 {
+		return p.errorf("Expected a value")
+	}
+	tmp, err := strconv.ParseFloat(args[0], 32)
+	if err != nil {
+		return p.errorf("Invalid number '%v'", args[0])
+	}
+	*value = float32(tmp)
+	return nil
+}
+
+// parseColor reads an RGB color, or a single value used for all three, into the color's RGB
+func (p *mtlParser) parseColor(args []string, color *mgl32.Vec4) error {
+	if len(args) > 0 && (args[0] == "spectral" || args[0] == "xyz") {
+		dusk.Warnf("Line %d: Unsupported color type '%v'", p.line, args[0])
+		return nil
+	}
+	if len(args) < 1 {
+		return p.errorf("Expected a color")
+	}
+
+	var rgb [3]float32
+	for i := range rgb {
+		if i >= len(args) {
+			rgb[i] = rgb[0]
+			continue
+		}
+		err := p.parseFloat(args[i:], &rgb[i])
+		if err != nil {
+			return err
+		}
+	}
+	color[0], color[1], color[2] = rgb[0], rgb[1], rgb[2]
+	return nil
+}
+
+// parseMap reads the filename of a texture map, skipping any options before it
+func (p *mtlParser) parseMap(args []string, file *string) error {
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		option := args[0]
+		count, found := mapOptionArgs[option]
+		if !found {
+			return p.errorf("Unknown texture option '%v'", option)
+		}
+		args = args[1:]
+		if len(args) < count[0] {
+			return p.errorf("Expected %d values for '%v'", count[0], option)
+		}
+
+		if option == "-type" && args[0] != "sphere" {
+			dusk.Warnf("Line %d: Unsupported reflection type '%v'", p.line, args[0])
+			return nil
+		}
+
+		n := count[0]
+		for n < count[1] && n < len(args)-1 {
+			if _, err := strconv.ParseFloat(args[n], 32); err != nil {
+				break
+			}
+			n++
+		}
+		args = args[n:]
+	}
+
+	if len(args) == 0 {
+		return p.errorf("Expected a filename")
+	}
+	*file = filepath.Join(p.dir, strings.Join(args, " "))
+	return nil
+}
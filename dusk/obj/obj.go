@@ -1,15 +1,15 @@
 package obj
 
 import (
-	"bytes"
 	"fmt"
-	"io"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/go-gl/mathgl/mgl32"
 
 	"github.com/WhoBrokeTheBuild/GoDusk/dusk"
+	"github.com/WhoBrokeTheBuild/GoDusk/dusk/meshutil"
 )
 
 func init() {
@@ -17,265 +17,504 @@ func init() {
 }
 
 // Load parses and returns the data from the file
+// Each object is split into one MeshData per material, and MeshData with the same material share one Material
 func Load(filename string) ([]*dusk.MeshData, error) {
 	filename = filepath.Clean(filename)
-	dir := filepath.Dir(filename)
-
-	data := []*dusk.MeshData{}
-	materials := map[string]*dusk.MaterialData{}
 
 	file, err := dusk.Load(filename)
 	if err != nil {
 		return nil, err
 	}
 
-	if file[len(file)-1] != '\n' {
-		file = append(file, '\n')
+	p := &parser{
+		dir:       filepath.Dir(filename),
+		materials: map[string]*dusk.MaterialData{},
+		loaded:    map[string]*dusk.Material{},
 	}
 
-	buf := bytes.NewBuffer(file)
+	err = p.parse(file)
+	if err != nil {
+		for _, m := range p.created() {
+			m.Delete()
+		}
+		return nil, err
+	}
 
-	var o *dusk.MeshData
-	var x, y, z, u, v float32
-	var f [4][3]int
+	// Objects split by material are named with a suffix, the same as glTF primitives
+	parts := map[*group]int{}
+	for _, g := range p.groups {
+		if len(g.corners) > 0 {
+			parts[g.object]++
+		}
+	}
 
-	var hasNorm bool
-	var hasTxcd bool
+	data := []*dusk.MeshData{}
+	used := map[*dusk.Material]bool{}
+	index := map[*group]int{}
+	for _, g := range p.groups {
+		if len(g.corners) == 0 {
+			continue
+		}
+		d := p.buildMeshData(g)
+		if parts[g.object] > 1 {
+			d.Name = fmt.Sprintf("%s.%d", g.object.name, index[g.object])
+			index[g.object]++
+		}
+		used[g.material] = true
+		data = append(data, d)
+	}
 
-	verts := []mgl32.Vec3{}
-	norms := []mgl32.Vec3{}
-	txcds := []mgl32.Vec2{}
+	for _, m := range p.created() {
+		if !used[m] {
+			m.Delete()
+		}
+	}
+	return data, nil
+}
 
-	for {
-		bytes, err := buf.ReadBytes('\n')
-		if err != nil {
-			if err == io.EOF {
-				break
-			} else {
-				return nil, err
-			}
+// corner is one corner of a face, as indices into the positions, texcoords and normals, or -1 if missing
+type corner struct {
+	v, t, n int
+}
+
+// group is the faces of one 'o' or 'g' statement with one material, which become one MeshData
+type group struct {
+	name     string
+	material *dusk.Material
+
+	// object is the first group of the 'o' or 'g' statement, which is split into more groups by 'usemtl'
+	object *group
+
+	// corners are three per triangle, and smooth and polygons are one per triangle
+	corners  []corner
+	smooth   []int
+	polygons []int
+}
+
+// parser holds the state of an OBJ file while it is read
+type parser struct {
+	dir  string
+	line int
+
+	verts  []mgl32.Vec3
+	colors []mgl32.Vec4
+	txcds  []mgl32.Vec2
+	norms  []mgl32.Vec3
+
+	// hasColors is set once any vertex has a color, vertices without one are white
+	hasColors bool
+
+	materials map[string]*dusk.MaterialData
+	// loaded are the Materials created for 'usemtl', by name
+	loaded map[string]*dusk.Material
+	// replaced are the Materials created before a later 'mtllib' redefined their name
+	replaced []*dusk.Material
+
+	groups   []*group
+	current  *group
+	smooth   int
+	polygons int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("Line %d: %v", p.line, fmt.Sprintf(format, args...))
+}
+
+// parse reads every statement in the file
+// Lines ending in '\' continue on the next line, and '#' starts a comment
+func (p *parser) parse(file []byte) error {
+	lines := strings.Split(string(file), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		p.line = i + 1
+		line := strings.TrimRight(lines[i], "\r")
+		for strings.HasSuffix(line, "\\") && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + " " + strings.TrimRight(lines[i], "\r")
 		}
 
-		line := string(bytes)
+		if c := strings.IndexByte(line, '#'); c >= 0 {
+			line = line[:c]
+		}
 
-		if len(line) < 2 || line[0] == '#' || line[0] == '\n' {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
 			continue
 		}
 
-		if line[0] == 'v' {
-			if line[1] == 'n' {
-				fmt.Sscanf(line[3:], "%f %f %f", &x, &y, &z)
-				norms = append(norms, mgl32.Vec3{x, y, z})
-			} else if line[1] == 't' {
-				fmt.Sscanf(line[3:], "%f %f", &u, &v)
-				txcds = append(txcds, mgl32.Vec2{u, v})
-			} else {
-				fmt.Sscanf(line[2:], "%f %f %f", &x, &y, &z)
-				verts = append(verts, mgl32.Vec3{x, y, z})
-			}
-		} else if line[0] == 'f' {
-			if o == nil {
-				o = &dusk.MeshData{}
-				data = append(data, o)
-			}
+		err := p.parseStatement(fields[0], fields[1:])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-			polySize := 3
-			hasNorm = false
-			hasTxcd = false
-			if strings.Contains(line, "//") {
-				hasNorm = true
-				polySize, _ = fmt.Sscanf(line[2:],
-					"%d//%d %d//%d %d//%d %d//%d",
-					&f[0][0], &f[0][2],
-					&f[1][0], &f[1][2],
-					&f[2][0], &f[2][2],
-					&f[3][0], &f[3][2])
-				polySize /= 2
-			} else {
-				sc := strings.Count(line, "/")
-				if sc == 3 || sc == 4 {
-					hasTxcd = true
-					polySize, _ = fmt.Sscanf(line[2:],
-						"%d/%d %d/%d %d/%d %d/%d",
-						&f[0][0], &f[0][1],
-						&f[1][0], &f[1][1],
-						&f[2][0], &f[2][1],
-						&f[2][0], &f[2][1])
-					polySize /= 2
-				} else {
-					hasNorm = true
-					hasTxcd = true
-					polySize, _ = fmt.Sscanf(line[2:],
-						"%d/%d/%d %d/%d/%d %d/%d/%d %d/%d/%d",
-						&f[0][0], &f[0][1], &f[0][2],
-						&f[1][0], &f[1][1], &f[1][2],
-						&f[2][0], &f[2][1], &f[2][2],
-						&f[3][0], &f[3][1], &f[3][2])
-					polySize /= 3
-				}
-			}
-			// TODO: Handle `f %d %d %d`
+func (p *parser) parseStatement(keyword string, args []string) error {
+	switch keyword {
+	case "v":
+		// Positions may be followed by a weight, or by an RGB color
+		if len(args) < 3 {
+			return p.errorf("Expected 3 values for 'v', found %d", len(args))
+		}
+		values, err := p.parseFloats(args)
+		if err != nil {
+			return err
+		}
+		p.verts = append(p.verts, mgl32.Vec3{values[0], values[1], values[2]})
 
-			inds := []int{0, 1, 2}
-			if polySize == 4 {
-				inds = []int{0, 1, 2, 2, 3, 0}
-			}
-			for _, i := range inds {
-				if f[i][0] < 0 {
-					f[i][0] += len(verts) + 1
-				}
-				if f[i][1] < 0 {
-					f[i][1] += len(txcds) + 1
-				}
-				if f[i][2] < 0 {
-					f[i][2] += len(norms) + 1
-				}
+		color := mgl32.Vec4{1, 1, 1, 1}
+		if len(values) >= 6 {
+			color = mgl32.Vec4{values[3], values[4], values[5], 1}
+			p.hasColors = true
+		}
+		p.colors = append(p.colors, color)
 
-				o.Vertices = append(o.Vertices, verts[f[i][0]-1])
+	case "vt":
+		if len(args) < 1 {
+			return p.errorf("Expected a value for 'vt'")
+		}
+		values, err := p.parseFloats(args)
+		if err != nil {
+			return err
+		}
+		t := mgl32.Vec2{values[0], 0}
+		if len(values) > 1 {
+			t[1] = values[1]
+		}
+		p.txcds = append(p.txcds, t)
 
-				if hasTxcd {
-					o.TexCoords = append(o.TexCoords, txcds[f[i][1]-1])
-				}
+	case "vn":
+		if len(args) < 3 {
+			return p.errorf("Expected 3 values for 'vn', found %d", len(args))
+		}
+		values, err := p.parseFloats(args)
+		if err != nil {
+			return err
+		}
+		p.norms = append(p.norms, mgl32.Vec3{values[0], values[1], values[2]})
 
-				if hasNorm {
-					o.Normals = append(o.Normals, norms[f[i][2]-1])
-				}
-			}
-		} else if line[0] == 'o' {
-			name := strings.TrimSpace(line[2:])
-			dusk.Verbosef("Processing Object [%v]", name)
-			if o != nil && o.Name == "" {
-				o.Name = name
-			} else {
-				o = &dusk.MeshData{
-					Name:      name,
-					Vertices:  []mgl32.Vec3{},
-					Normals:   []mgl32.Vec3{},
-					TexCoords: []mgl32.Vec2{},
-				}
-				data = append(data, o)
+	case "f":
+		return p.parseFace(args)
+
+	case "o", "g":
+		name := strings.Join(args, " ")
+		dusk.Verbosef("Processing Object [%v]", name)
+		var material *dusk.Material
+		if p.current != nil {
+			material = p.current.material
+		}
+		// Faces of an object that is named again are added to it, since each MeshData needs a unique name
+		if object := p.findObject(name); object != nil {
+			p.selectGroup(object, material)
+		} else if p.current != nil && len(p.current.corners) == 0 {
+			p.current.name = name
+			p.current.object = p.current
+		} else {
+			p.startGroup(name, material)
+		}
+
+	case "s":
+		if len(args) < 1 {
+			return p.errorf("Expected a value for 's'")
+		}
+		if args[0] == "off" {
+			p.smooth = 0
+		} else {
+			value, err := strconv.Atoi(args[0])
+			if err != nil {
+				return p.errorf("Invalid smoothing group '%v'", args[0])
 			}
-		} else if strings.HasPrefix(line, "mtllib") {
-			tmp, err := readMaterial(filepath.Join(dir, strings.TrimSpace(line[7:])))
+			p.smooth = value
+		}
+
+	case "mtllib":
+		for _, name := range args {
+			tmp, err := readMaterial(filepath.Join(p.dir, name))
 			if err != nil {
-				return nil, err
+				return err
 			}
 			for k, v := range tmp {
-				materials[k] = v
-			}
-		} else if strings.HasPrefix(line, "usemtl") {
-			if o == nil {
-				o = &dusk.MeshData{}
-				data = append(data, o)
-			}
-
-			name := strings.TrimSpace(line[7:])
-			if m, ok := materials[name]; ok {
-				o.Material, err = dusk.NewMaterialFromData(m)
-				if err != nil {
-					return nil, err
+				p.materials[k] = v
+				// A Material already created with this name stays with the faces that used it
+				if m, found := p.loaded[k]; found {
+					p.replaced = append(p.replaced, m)
+					delete(p.loaded, k)
 				}
 			}
 		}
+
+	case "usemtl":
+		if p.current == nil {
+			p.startGroup("", nil)
+		}
+		name := strings.Join(args, " ")
+		material, err := p.loadMaterial(name)
+		if err != nil {
+			return err
+		}
+		if material == p.current.material {
+			break
+		}
+		if len(p.current.corners) == 0 {
+			p.current.material = material
+			break
+		}
+
+		// Faces with the same material in one object are kept together
+		p.selectGroup(p.current.object, material)
 	}
 
-	return data, nil
+	return nil
 }
 
-func readMaterial(filename string) (map[string]*dusk.MaterialData, error) {
-	filename = filepath.Clean(filename)
-	dir := filepath.Dir(filename)
+// loadMaterial returns the Material with the given name, creating it the first time it is used
+func (p *parser) loadMaterial(name string) (*dusk.Material, error) {
+	if m, found := p.loaded[name]; found {
+		return m, nil
+	}
 
-	materials := map[string]*dusk.MaterialData{}
+	data, found := p.materials[name]
+	if !found {
+		dusk.Warnf("Line %d: Unknown material [%v]", p.line, name)
+		return nil, nil
+	}
 
-	file, err := dusk.Load(filename)
+	m, err := dusk.NewMaterialFromData(data)
 	if err != nil {
 		return nil, err
 	}
+	p.loaded[name] = m
+	return m, nil
+}
 
-	if file[len(file)-1] != '\n' {
-		file = append(file, '\n')
+// created returns every Material created while parsing, including those that were replaced
+func (p *parser) created() []*dusk.Material {
+	materials := append([]*dusk.Material{}, p.replaced...)
+	for _, m := range p.loaded {
+		materials = append(materials, m)
 	}
+	return materials
+}
 
-	buf := bytes.NewBuffer(file)
+// startGroup starts a new object, with the material of the faces before it
+func (p *parser) startGroup(name string, material *dusk.Material) {
+	p.current = &group{
+		name:     name,
+		material: material,
+	}
+	p.current.object = p.current
+	p.groups = append(p.groups, p.current)
+}
 
-	var m *dusk.MaterialData
+// findObject returns the first group of the object with the given name, or nil if there is none
+func (p *parser) findObject(name string) *group {
+	for _, g := range p.groups {
+		if g.object == g && g.name == name {
+			return g
+		}
+	}
+	return nil
+}
+
+// selectGroup continues the group of object with the given material, starting one if there is none
+func (p *parser) selectGroup(object *group, material *dusk.Material) {
+	for _, g := range p.groups {
+		if g.object == object && g.material == material {
+			p.current = g
+			return
+		}
+	}
+	p.startGroup(object.name, material)
+	p.current.object = object
+}
 
-	for {
-		bytes, err := buf.ReadBytes('\n')
+func (p *parser) parseFloats(args []string) ([]float32, error) {
+	values := make([]float32, len(args))
+	for i, arg := range args {
+		value, err := strconv.ParseFloat(arg, 32)
 		if err != nil {
-			if err == io.EOF {
-				break
-			} else {
-				return nil, err
-			}
+			return nil, p.errorf("Invalid number '%v'", arg)
 		}
+		values[i] = float32(value)
+	}
+	return values, nil
+}
+
+// parseIndex returns the 0-based index of a 1-based or negative relative index, or -1 if it is empty
+func (p *parser) parseIndex(text string, count int, kind string) (int, error) {
+	if text == "" {
+		return -1, nil
+	}
+	index, err := strconv.Atoi(text)
+	if err != nil {
+		return -1, p.errorf("Invalid %v index '%v'", kind, text)
+	}
+	if index < 0 {
+		index += count
+	} else {
+		index--
+	}
+	if index < 0 || index >= count {
+		return -1, p.errorf("Out of range %v index %v", kind, text)
+	}
+	return index, nil
+}
 
-		line := string(bytes)
+// parseFace reads a polygon of corners in any of the forms 'v', 'v/t', 'v//n' or 'v/t/n', and triangulates it
+func (p *parser) parseFace(args []string) error {
+	if len(args) < 3 {
+		return p.errorf("Expected at least 3 corners for 'f', found %d", len(args))
+	}
 
-		if len(line) < 2 || line[0] == '#' || line[0] == '\n' {
-			continue
+	corners := make([]corner, len(args))
+	points := make([]mgl32.Vec3, len(args))
+	for i, arg := range args {
+		parts := strings.Split(arg, "/")
+		if len(parts) > 3 || parts[0] == "" {
+			return p.errorf("Invalid face corner '%v'", arg)
 		}
 
-		if strings.HasPrefix(line, "newmtl") { // newmtl
-			name := strings.TrimSpace(line[7:])
-			m = &dusk.MaterialData{
-				Ambient:  mgl32.Vec4{0, 0, 0, 1},
-				Diffuse:  mgl32.Vec4{0, 0, 0, 1},
-				Specular: mgl32.Vec4{0, 0, 0, 1},
+		var err error
+		c := corner{t: -1, n: -1}
+		c.v, err = p.parseIndex(parts[0], len(p.verts), "vertex")
+		if err != nil {
+			return err
+		}
+		if len(parts) > 1 {
+			c.t, err = p.parseIndex(parts[1], len(p.txcds), "texcoord")
+			if err != nil {
+				return err
 			}
-			materials[name] = m
-		} else if line[0] == 'K' {
-			if line[1] == 'a' {
-				if m != nil {
-					// Ka
-					fmt.Sscanf(line[3:], "%f %f %f", &m.Ambient[0], &m.Ambient[1], &m.Ambient[2])
-				}
-			} else if line[1] == 'd' {
-				if m != nil {
-					// Kd
-					fmt.Sscanf(line[3:], "%f %f %f", &m.Diffuse[0], &m.Diffuse[1], &m.Diffuse[2])
-				}
-			} else if line[1] == 's' {
-				if m != nil {
-					// Ks
-					fmt.Sscanf(line[3:], "%f %f %f", &m.Specular[0], &m.Specular[1], &m.Specular[2])
-				}
+		}
+		if len(parts) > 2 {
+			c.n, err = p.parseIndex(parts[2], len(p.norms), "normal")
+			if err != nil {
+				return err
 			}
-		} else if line[0] == 'N' && line[1] == 's' {
-			// Ns
-			//fmt.Sscanf(line[3:], "%f", &m.Shininess)
-		} else if strings.HasPrefix(line, "map_K") {
-			if line[5] == 'a' {
-				// map_Ka
-				m.AmbientMap = filepath.Join(dir, strings.TrimSpace(line[7:]))
-			} else if line[5] == 'd' {
-				// map_Kd
-				m.DiffuseMap = filepath.Join(dir, strings.TrimSpace(line[7:]))
-			} else if line[5] == 's' {
-				// map_Ks
-				m.SpecularMap = filepath.Join(dir, strings.TrimSpace(line[7:]))
+		}
+
+		corners[i] = c
+		points[i] = p.verts[c.v]
+	}
+
+	if p.current == nil {
+		p.startGroup("", nil)
+	}
+	g := p.current
+
+	for _, tri := range dusk.TriangulatePolygon(points) {
+		g.corners = append(g.corners, corners[tri[0]], corners[tri[1]], corners[tri[2]])
+		g.smooth = append(g.smooth, p.smooth)
+		g.polygons = append(g.polygons, p.polygons)
+	}
+	p.polygons++
+
+	return nil
+}
+
+// buildMeshData returns the vertices of a group
+// Texcoords are only kept if every corner has one, while missing normals are generated from the
+// smoothing groups if some corners have normals or the group uses smoothing
+func (p *parser) buildMeshData(g *group) *dusk.MeshData {
+	d := &dusk.MeshData{
+		Name:      g.name,
+		Material:  g.material,
+		Vertices:  make([]mgl32.Vec3, len(g.corners)),
+		TexCoords: []mgl32.Vec2{},
+		Normals:   []mgl32.Vec3{},
+	}
+
+	hasTxcds, missingTxcds := false, false
+	hasNorms, missingNorms, smoothed := false, false, false
+	for _, c := range g.corners {
+		hasTxcds = hasTxcds || c.t >= 0
+		missingTxcds = missingTxcds || c.t < 0
+		hasNorms = hasNorms || c.n >= 0
+		missingNorms = missingNorms || c.n < 0
+	}
+	for _, s := range g.smooth {
+		smoothed = smoothed || s != 0
+	}
+	if hasTxcds && missingTxcds {
+		dusk.Warnf("Some faces of [%v] have no texcoords, ignoring all texcoords", g.name)
+		hasTxcds = false
+	}
+	if (hasNorms && missingNorms) || (!hasNorms && smoothed) {
+		hasNorms = true
+	} else {
+		missingNorms = false
+	}
+
+	for i, c := range g.corners {
+		d.Vertices[i] = p.verts[c.v]
+	}
+	if hasTxcds {
+		d.TexCoords = make([]mgl32.Vec2, len(g.corners))
+		for i, c := range g.corners {
+			d.TexCoords[i] = p.txcds[c.t]
+		}
+	}
+	if p.hasColors {
+		d.Colors = make([]mgl32.Vec4, len(g.corners))
+		for i, c := range g.corners {
+			d.Colors[i] = p.colors[c.v]
+		}
+	}
+	if hasNorms {
+		d.Normals = make([]mgl32.Vec3, len(g.corners))
+		for i, c := range g.corners {
+			if c.n >= 0 {
+				d.Normals[i] = p.norms[c.n]
 			}
-		} else if strings.HasPrefix(line, "map_Ns") {
-			// map_Ns
-			//m.SpecularHighlightMap = filepath.Join(dir, strings.TrimSpace(line[7:]))
-		} else if strings.HasPrefix(line, "bump") {
-			// bump
-			//m.BumpMap = filepath.Join(dir, strings.TrimSpace(line[5:]))
-		} else if strings.HasPrefix(line, "map_bump") {
-			// map_bump
-			//m.BumpMap = filepath.Join(dir, strings.TrimSpace(line[7:]))
-		} else if strings.HasPrefix(line, "disp") {
-			// disp
-			//m.DisplacementMap = filepath.Join(dir, strings.TrimSpace(line[5:]))
-		} else if strings.HasPrefix(line, "refl") {
-			// refl
-			//m.ReflectionMap = filepath.Join(dir, strings.TrimSpace(line[5:]))
-		} else if strings.HasPrefix(line, "map_d") {
-			// map_d
-			//m.AlphaMap = filepath.Join(dir, strings.TrimSpace(line[6:]))
-		}
-	}
-
-	return materials, nil
+		}
+	}
+	if missingNorms {
+		smoothNormals(d, g)
+	}
+
+	// OBJ has no tangents, so they are generated for normal maps
+	if d.Material != nil && d.Material.NormalMap != nil {
+		meshutil.GenerateTangents(d)
+	}
+
+	d.Polygons = g.polygons
+	return d
+}
+
+// smoothNormals fills in the normals of corners that have none
+// Corners that share a position within a smoothing group average the normals of their triangles, and other corners use their triangle's normal
+func smoothNormals(d *dusk.MeshData, g *group) {
+	type key struct {
+		smooth, v int
+	}
+	sums := map[key]mgl32.Vec3{}
+
+	faces := make([]mgl32.Vec3, len(g.smooth))
+	for t := range faces {
+		a, b, c := d.Vertices[t*3], d.Vertices[t*3+1], d.Vertices[t*3+2]
+		// The unnormalized cross product weights each triangle by its area
+		faces[t] = b.Sub(a).Cross(c.Sub(a))
+		if g.smooth[t] != 0 {
+			for i := t * 3; i < t*3+3; i++ {
+				k := key{g.smooth[t], g.corners[i].v}
+				sums[k] = sums[k].Add(faces[t])
+			}
+		}
+	}
+
+	for i, c := range g.corners {
+		if c.n >= 0 {
+			continue
+		}
+		t := i / 3
+		n := faces[t]
+		if g.smooth[t] != 0 {
+			n = sums[key{g.smooth[t], c.v}]
+		}
+		if n.Len() > 0 {
+			n = n.Normalize()
+		}
+		d.Normals[i] = n
+	}
 }
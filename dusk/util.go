@@ -66,3 +66,10 @@ func DistanceSquared(p1, p2 mgl32.Vec3) float32 {
 func Distance(p1, p2 mgl32.Vec3) float32 {
 	return m32.Sqrt(DistanceSquared(p1, p2))
 }
+
+func boolToInt32(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -53,7 +53,7 @@ func Atan(x float32) float32 {
 
 // Atan2 = math.Atan2
 func Atan2(y, x float32) float32 {
-	return float32(math.Atan2(float64(x), float64(y)))
+	return float32(math.Atan2(float64(y), float64(x)))
 }
 
 // Atanh = math.Atanh
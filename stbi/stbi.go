@@ -51,7 +51,22 @@ func LoadFromMemory(buffer []byte, desiredChannels C.int) (*C.uchar, int, int, C
 		int(width), int(height), channels
 }
 
+// LoadfFromMemory = stbi_loadf_from_memory
+func LoadfFromMemory(buffer []byte, desiredChannels C.int) (*C.float, int, int, C.int) {
+	var width, height, channels C.int
+	cbuf := C.CBytes(buffer)
+	defer C.free(cbuf)
+
+	return C.stbi_loadf_from_memory((*C.uchar)(cbuf), C.int(len(buffer)), &width, &height, &channels, desiredChannels),
+		int(width), int(height), channels
+}
+
 // ImageFree = stbi_image_free
 func ImageFree(image *C.uchar) {
 	C.stbi_image_free(unsafe.Pointer(image))
 }
+
+// ImageFreef = stbi_image_free, for images from Loadf
+func ImageFreef(image *C.float) {
+	C.stbi_image_free(unsafe.Pointer(image))
+}